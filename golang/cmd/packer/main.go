@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"maps"
 	"math/rand"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"tree-packing-challenge/pkg/solvers/greedy"
@@ -26,21 +32,95 @@ type Result struct {
 	Score    float64
 	Trees    []tree.ChristmasTree
 	TreeData [][]string
+	Elapsed  time.Duration // Wall-clock time the solver took for this n
 }
 
+// budgetWeights holds optional per-n importance factors (-weights) that scale the
+// outer SA steps each n receives; nil means a uniform budget
+var budgetWeights map[int]float64
+
+// greedyDirections is the number of evenly spaced directions (-directions) swept by greedy
+// placement in addition to its random ones
+var greedyDirections int
+
+// deterministic (-deterministic) draws each n's greedy layout from its own source seeded
+// with runSeed+n, so results do not depend on worker scheduling
+var deterministic bool
+var runSeed int64
+
+// scoreObjective (-score) overrides the objective minimized by the collision-free SA
+var scoreObjective string
+
+// snapIncrement (-snap) enables a final pass in runParallel that snaps each tree's angle to
+// a multiple of it when that neither overlaps nor grows the side (0 = off)
+var snapIncrement float64
+
+// quiet (-quiet) silences the SA solvers and the per-n result lines of runParallel,
+// leaving only the progress line
+var quiet bool
+
+// saStarts (-starts) runs each collision-free SA solve of sa and grid-sa as that many
+// independently seeded starts, keeping the best (1 = a single run)
+var saStarts int
+
+// curveRecorder collects best-score curves from the SA solvers when -curve is set
+var curveRecorder *sa.CurveRecorder
+
+// finalGroups holds the in-memory layout of every group formatted by groupRows, so -format
+// json writes the solvers' raw floats rather than the rounded CSV values
+var finalGroups = struct {
+	sync.Mutex
+	trees map[int][]tree.ChristmasTree
+}{trees: make(map[int][]tree.ChristmasTree)}
+
+// runCtx bounds the whole invocation; it is cancelled when -deadline is reached so that
+// no new n is started and in-flight solves return their best so far
+var runCtx = context.Background()
+
 // SolverFunc defines the signature for a single-instance solver
 type SolverFunc func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree)
 
 func main() {
 	// CLI flags
-	algorithm := flag.String("algorithm", "greedy", "Algorithm: greedy, sa, sa-penalty, sa-advanced, grid, grid-sa, grid-sa-penalty")
-	configPath := flag.String("config", "", "Path to SA config YAML file (optional, uses defaults if not provided)")
+	algorithm := flag.String("algorithm", "greedy", "Algorithm: greedy, greedy-ls, sa, sa-penalty, sa-advanced, grid, hex, spiral, grid-sa, grid-sa-penalty, fit, repair, forever, best-merge (alias merge), refine, validate, compare, bench")
+	configPath := flag.String("config", "", "Path to SA config YAML file, also read by grid-ga from its ga section (optional, uses defaults if not provided)")
 	numTrees := flag.Int("n", 200, "Number of trees to pack")
 	output := flag.String("output", "../results/submissions/submission.csv", "Output CSV file path")
 	seed := flag.Int64("seed", 0, "Random seed (0 = use current time)")
 	startFrom := flag.String("start-from", "", "Path to submission CSV to use as starting point")
+	side := flag.Float64("side", 0, "Target square side for the fit algorithm")
+	input := flag.String("input", "", "Path to submission CSV to process (repair, validate, compare), or comma-separated paths (best-merge)")
+	inputs := flag.String("inputs", "", "Comma-separated submission CSVs to merge (merge, best-merge); overrides -input")
+	only := flag.String("only", "", "Comma-separated n to re-optimize with SA (refine); all other groups of -input are kept as written")
+	input2 := flag.String("input2", "", "Second submission CSV, compared against -input (compare)")
+	baseAngle := flag.Float64("base-angle", 0, "Reference angle offset (degrees) added to every tree's angle; output CSV stores the true rotation")
+	weightsPath := flag.String("weights", "", "Path to CSV of n,weight importance factors scaling the per-n SA budget")
+	dumpConfig := flag.String("dump-config", "", "Write the resolved SA config to this YAML path before solving")
+	directions := flag.Int("directions", 0, "Extra evenly spaced directions swept per tree by greedy placement (0 = random only)")
+	baselinePath := flag.String("baseline", "", "Baseline submission CSV to report per-n improvement of the output against")
+	curvePath := flag.String("curve", "", "Write the best-score curve (n,step,best) of SA runs to this CSV path")
+	resumePath := flag.String("resume", "", "Submission CSV to resume greedy packing from: complete groups are kept and larger n grow from the largest one")
+	svgPath := flag.String("svg", "", "Write an SVG drawing of the final layout for n = -n to this path")
+	deterministicFlag := flag.Bool("deterministic", false, "Seed greedy initial layouts per n from -seed so the run is reproducible regardless of worker scheduling")
+	score := flag.String("score", "", "Objective minimized by sa/grid-sa: square or circle (reported scores stay square sides); empty = config value")
+	lsIters := flag.Int("ls-iters", 20, "Local-search passes per n for greedy-ls")
+	settle := flag.String("settle", "compaction", "Final pass of greedy-ls: compaction (towards the bounding-box center) or centroid (towards the mean tree position)")
+	snap := flag.Float64("snap", 0, "Final pass: snap angles to multiples of this many degrees where it keeps the layout valid and the side no larger (0 = off)")
+	format := flag.String("format", "csv", "Output format: csv, json to also write the result next to the CSV as <output>.json with raw float values, or geojson to also write the layout for n = -n as <output>.geojson")
+	starts := flag.Int("starts", 1, "Independent SA starts per n for sa and grid-sa, run in parallel; the best valid result is kept")
+	quietFlag := flag.Bool("quiet", false, "Suppress per-step SA logs and per-n result lines; show a single progress line with ETA instead")
+	shapePath := flag.String("shape", "", "Tree outline file (.json list of [x, y] vertices, or CSV of x,y rows) replacing the built-in challenge shape")
+	deadline := flag.Duration("deadline", 0, "Maximum run time (e.g. 30m); when reached, in-flight solves stop and completed n are written (0 = no limit)")
 
 	flag.Parse()
+	tree.BaseAngleOffset = *baseAngle
+	greedyDirections = *directions
+	deterministic = *deterministicFlag
+	runSeed = *seed
+	quiet = *quietFlag
+	saStarts = *starts
+	scoreObjective = *score
+	snapIncrement = *snap
 
 	// Set random seed
 	if *seed == 0 {
@@ -51,28 +131,92 @@ func main() {
 
 	fmt.Printf("Tree Packing - Algorithm: %s, Trees: %d\n", *algorithm, *numTrees)
 
+	if *shapePath != "" {
+		s, err := tree.LoadShape(*shapePath)
+		if err == nil {
+			err = tree.SetShape(*s)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading tree shape: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Tree shape loaded from %s (area %.6f)\n", *shapePath, s.Area())
+	}
+
+	if *score != "" && *score != string(sa.ObjectiveSquare) && *score != string(sa.ObjectiveCircle) {
+		fmt.Fprintf(os.Stderr, "Unknown -score %q (want square or circle)\n", *score)
+		os.Exit(1)
+	}
+
+	if *settle != "compaction" && *settle != "centroid" {
+		fmt.Fprintf(os.Stderr, "Unknown -settle %q (want compaction or centroid)\n", *settle)
+		os.Exit(1)
+	}
+	if *format != "csv" && *format != "json" && *format != "geojson" {
+		fmt.Fprintf(os.Stderr, "Unknown -format %q (want csv, json or geojson)\n", *format)
+		os.Exit(1)
+	}
+
+	if *deadline > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(context.Background(), *deadline)
+		defer cancel()
+		fmt.Printf("Deadline: %s\n", *deadline)
+	}
+
+	if *dumpConfig != "" {
+		if err := sa.SaveConfig(*dumpConfig, loadConfig(*configPath)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing config: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Resolved config written to %s\n", *dumpConfig)
+	}
+
+	if *weightsPath != "" {
+		var err error
+		budgetWeights, err = sa.LoadWeights(*weightsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading weights: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded budget weights for %d values of n\n", len(budgetWeights))
+	}
+
+	if *curvePath != "" {
+		curveRecorder = sa.NewCurveRecorder()
+	}
+
 	var startingPoints map[int][]tree.ChristmasTree
 	if *startFrom != "" {
 		var err error
-		startingPoints, err = loadStartingPoints(*startFrom)
+		startingPoints, err = tree.LoadSubmission(*startFrom)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error loading starting points: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Printf("Loaded starting points from %s for %d layouts\n", *startFrom, len(startingPoints))
+		if ext := tree.TrivialExtensions(startingPoints, 1e-6); len(ext) > 0 {
+			fmt.Printf("Note: %d layouts just extend n-1 by one tree and likely need more optimization: %v\n", len(ext), ext)
+		}
 	}
 
 	var treeData [][]string
 
 	switch *algorithm {
 	case "greedy":
-		treeData = runGreedy(*numTrees, *output, startingPoints)
+		treeData = runGreedy(*numTrees, *output, startingPoints, *resumePath)
+	case "greedy-ls":
+		treeData = runGreedyLS(*numTrees, *output, startingPoints, *lsIters, *settle)
 	case "sa":
 		treeData = runSimulatedAnnealing(*numTrees, *configPath, *output, false, startingPoints)
 	case "sa-penalty":
 		treeData = runSimulatedAnnealing(*numTrees, *configPath, *output, true, startingPoints)
 	case "grid":
 		treeData = runGrid(*numTrees, *output, startingPoints)
+	case "hex":
+		treeData = runHex(*numTrees, *output, startingPoints)
+	case "spiral":
+		treeData = runSpiral(*numTrees, *output, startingPoints)
 	case "grid-sa":
 		treeData = runGridSA(*numTrees, *configPath, *output, false, startingPoints)
 	case "grid-sa-penalty":
@@ -82,7 +226,35 @@ func main() {
 	case "sa-advanced-penalty":
 		treeData = runAdvancedSAPenalty(*numTrees, *configPath, *output, startingPoints)
 	case "grid-ga":
-		treeData = runGridGA(*numTrees, *output, startingPoints)
+		treeData = runGridGA(*numTrees, *configPath, *output, startingPoints)
+	case "fit":
+		treeData = runFit(*numTrees, *side, *configPath)
+	case "repair":
+		treeData = runRepair(*input)
+	case "refine":
+		treeData = runRefine(*input, *only, *configPath)
+	case "forever":
+		treeData = runForever(*numTrees, *configPath, *output, startingPoints)
+	case "best-merge", "merge":
+		paths := *input
+		if *inputs != "" {
+			paths = *inputs
+		}
+		treeData = runBestMerge(paths)
+	case "compare":
+		// Comparison only reports on -input and -input2; nothing is written
+		runCompare(*input, *input2)
+		return
+	case "bench":
+		// Benchmarking only reports timings for -n; nothing is written
+		runBench(*numTrees, *configPath, *seed)
+		return
+	case "validate":
+		// Validation only reports on -input; nothing is written
+		if !runValidate(*input) {
+			os.Exit(1)
+		}
+		return
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown algorithm: %s\n", *algorithm)
 		os.Exit(1)
@@ -94,15 +266,161 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *format == "json" {
+		jsonPath := strings.TrimSuffix(*output, filepath.Ext(*output)) + ".json"
+		results, err := jsonResults(*output)
+		if err == nil {
+			err = writeJSON(jsonPath, results)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("JSON output written to: %s\n", jsonPath)
+	}
+
+	if *format == "geojson" {
+		geoPath := strings.TrimSuffix(*output, filepath.Ext(*output)) + ".geojson"
+		if err := writeGeoJSON(*output, geoPath, *numTrees); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing GeoJSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("GeoJSON of n=%d written to: %s\n", *numTrees, geoPath)
+	}
+
+	if curveRecorder != nil {
+		if err := curveRecorder.WriteCSV(*curvePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing curve: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Best-score curve written to: %s\n", *curvePath)
+	}
+
+	if *svgPath != "" {
+		if err := writeSVG(*output, *svgPath, *numTrees); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing SVG: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("SVG of n=%d written to: %s\n", *numTrees, *svgPath)
+	}
+
+	if *baselinePath != "" {
+		if err := reportBaseline(*output, *baselinePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing to baseline: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if groups, err := tree.LoadSubmission(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to score output: %v\n", err)
+	} else {
+		fmt.Printf("Aggregate score: %.6f over %d groups\n", tree.AggregateScore(groups), len(groups))
+	}
+
 	fmt.Printf("Done! Output written to: %s\n", *output)
 }
 
-// runParallel executes the given solver in parallel for all n from 1 to numTrees
-func runParallel(numTrees int, configPath string, outputPath string, algoName string, startingPoints map[int][]tree.ChristmasTree, solver SolverFunc) [][]string {
+// writeSVG draws group n of the written output submission to svgPath
+func writeSVG(outputPath, svgPath string, n int) error {
+	groups, err := tree.LoadSubmission(outputPath)
+	if err != nil {
+		return err
+	}
+	trees, ok := groups[n]
+	if !ok {
+		return fmt.Errorf("no layout for n=%d in %s", n, outputPath)
+	}
+
+	file, err := os.Create(svgPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return tree.ExportSVG(trees, file)
+}
+
+// writeGeoJSON writes group n of the written output submission to geoPath as GeoJSON
+func writeGeoJSON(outputPath, geoPath string, n int) error {
+	groups, err := tree.LoadSubmission(outputPath)
+	if err != nil {
+		return err
+	}
+	trees, ok := groups[n]
+	if !ok {
+		return fmt.Errorf("no layout for n=%d in %s", n, outputPath)
+	}
+
+	file, err := os.Create(geoPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return tree.ExportGeoJSON(trees, file)
+}
+
+// reportBaseline prints the per-n and overall improvement of the written output over a
+// baseline submission, flagging every n that regressed
+func reportBaseline(outputPath, baselinePath string) error {
+	current, err := tree.LoadSubmission(outputPath)
+	if err != nil {
+		return err
+	}
+	baseline, err := tree.LoadSubmission(baselinePath)
+	if err != nil {
+		return err
+	}
+
+	report, overall := tree.CompareToBaseline(current, baseline)
+	var regressed []int
+	for _, imp := range report {
+		mark := ""
+		if imp.Regressed {
+			mark = "  REGRESSED"
+			regressed = append(regressed, imp.N)
+		}
+		fmt.Printf("Baseline: n=%3d %.5f -> %.5f (%+.3f%%)%s\n", imp.N, imp.Baseline, imp.Current, imp.Percent, mark)
+	}
+	fmt.Printf("Baseline: overall improvement %+.3f%% over %d groups, %d regressed %v\n",
+		overall, len(report), len(regressed), regressed)
+	return nil
+}
+
+// curveHook returns the OnImprove hook recording n's curve, or nil when -curve is unset
+func curveHook(n int) func(step int, best float64) {
+	if curveRecorder == nil {
+		return nil
+	}
+	return curveRecorder.Hook(n)
+}
+
+// solveSA runs the collision-free SA for n from trees, as -starts independent starts when
+// it is above 1 (the best-score curve is then not recorded)
+func solveSA(ctx context.Context, n int, trees []tree.ChristmasTree, config *sa.Config) (float64, []tree.ChristmasTree) {
+	if saStarts > 1 {
+		return sa.SolveMultiStartContext(ctx, trees, config, saStarts)
+	}
+	solver := sa.NewSimulatedAnnealing(trees, config)
+	solver.OnImprove = curveHook(n)
+	return solver.SolveContext(ctx)
+}
+
+// runParallel executes the given solver in parallel for all n from 1 to numTrees. Once ctx
+// is done no further n is started; the results of all n solved so far are returned.
+func runParallel(ctx context.Context, numTrees int, configPath string, outputPath string, algoName string, startingPoints map[int][]tree.ChristmasTree, solver SolverFunc) [][]string {
 	config := loadConfig(configPath)
 	numWorkers := runtime.NumCPU()
 	fmt.Printf("Running %s in parallel with %d workers\n", algoName, numWorkers)
 
+	// Redistribute the total outer-step budget according to the weights
+	ns := make([]int, 0, numTrees)
+	for n := 1; n <= numTrees; n++ {
+		ns = append(ns, n)
+	}
+	var stepsPerN map[int]int
+	if budgetWeights != nil {
+		stepsPerN = sa.AllocateSteps(config.NSteps*numTrees, budgetWeights, ns)
+	}
+
 	jobs := make(chan int, numTrees)
 	results := make(chan Result, numTrees)
 
@@ -110,22 +428,39 @@ func runParallel(numTrees int, configPath string, outputPath string, algoName st
 	for i := 0; i < numWorkers; i++ {
 		wg.Go(func() {
 			for n := range jobs {
+				if ctx.Err() != nil {
+					fmt.Printf("%s: stopped, skipping n=%d\n", algoName, n)
+					continue
+				}
 				var startNodes []tree.ChristmasTree
 				if startingPoints != nil {
 					startNodes = startingPoints[n]
 				}
-				score, trees := solver(n, config, startNodes)
-
-				var data [][]string
-				for tIdx, t := range trees {
-					data = append(data, formatTree(n, tIdx, t))
+				// Each n gets its own seed so it can be replayed independently of the others,
+				// on top of any per-n overrides from the config file
+				nConfig := *sa.ConfigForN(config, n)
+				nConfig.RandomSeed += int64(n)
+				if stepsPerN != nil {
+					nConfig.NSteps = stepsPerN[n]
+				}
+				if quiet {
+					nConfig.LogLevel = sa.LogSilent
+				}
+				start := time.Now()
+				score, trees := solver(n, &nConfig, startNodes)
+				elapsed := time.Since(start)
+				if snapIncrement > 0 && len(trees) > 0 {
+					trees, score = snapPass(n, trees, score)
 				}
 
+				data := groupRows(n, trees)
+
 				results <- Result{
 					N:        n,
 					Score:    score,
 					Trees:    trees,
 					TreeData: data,
+					Elapsed:  elapsed,
 				}
 			}
 		})
@@ -141,46 +476,64 @@ func runParallel(numTrees int, configPath string, outputPath string, algoName st
 		close(results)
 	}()
 
-	// Construct intermediate file path
+	// Stream each result to the intermediate file as it arrives, so a crash keeps all
+	// finished n. Out-of-order results wait in pending until the groups before them are in.
 	dir := filepath.Dir(outputPath)
 	base := filepath.Base(outputPath)
 	intermediatePath := filepath.Join(dir, "intermediate_"+base)
+	sink, err := tree.NewCSVSink(intermediatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to open intermediate results file: %v\n", err)
+	} else {
+		fmt.Printf("Streaming intermediate results to %s\n", intermediatePath)
+	}
+	writeGroup := func(r Result) {
+		if sink == nil {
+			return
+		}
+		if err := sink.WriteGroup(r.N, r.Trees); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write intermediate results for n=%d: %v\n", r.N, err)
+		}
+	}
+
+	progress := tree.NewProgressReporter(os.Stdout, ns, quiet)
 
 	var allResults []Result
-	count := 0
+	pending := make(map[int]Result)
+	next := 1
 	for result := range results {
-		fmt.Printf("%s: n=%d, score=%.5f\n", algoName, result.N, result.Score)
+		if !quiet {
+			fmt.Printf("%s: n=%d, score=%.5f\n", algoName, result.N, result.Score)
+		}
+		progress.Done(result.N, result.Elapsed)
 		allResults = append(allResults, result)
-		count++
-
-		// Intermediate saving every 10 iterations
-		if count%10 == 0 {
-			// Sort results by N
-			sortedResults := make([]Result, len(allResults))
-			copy(sortedResults, allResults)
-			sort.Slice(sortedResults, func(i, j int) bool {
-				return sortedResults[i].N < sortedResults[j].N
-			})
-
-			// Prepare data for CSV
-			var intermediateData [][]string
-			for _, r := range sortedResults {
-				intermediateData = append(intermediateData, r.TreeData...)
-			}
 
-			// Write to intermediate CSV
-			if err := writeCSV(intermediatePath, intermediateData); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to write intermediate results: %v\n", err)
-			} else {
-				fmt.Printf("Saved intermediate results (%d/%d) to %s\n", count, numTrees, intermediatePath)
-			}
+		pending[result.N] = result
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			writeGroup(r)
+			delete(pending, next)
+			next++
 		}
 	}
 
+	progress.Finish()
+
 	sort.Slice(allResults, func(i, j int) bool {
 		return allResults[i].N < allResults[j].N
 	})
 
+	// Groups after an n that was skipped (deadline) are still pending; write them in order
+	for _, r := range allResults {
+		if _, ok := pending[r.N]; ok {
+			writeGroup(r)
+		}
+	}
+	if sink != nil {
+		if err := sink.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to close intermediate results file: %v\n", err)
+		}
+	}
+
 	var treeData [][]string
 	for _, result := range allResults {
 		treeData = append(treeData, result.TreeData...)
@@ -189,25 +542,161 @@ func runParallel(numTrees int, configPath string, outputPath string, algoName st
 	return treeData
 }
 
-// runGreedy runs the greedy placement algorithm in parallel
-func runGreedy(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
-	return runParallel(numTrees, "", outputPath, "Greedy", startingPoints, func(n int, _ *sa.Config, _ []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
-		trees, sideLength := greedy.InitializeTrees(n, nil)
+// snapPass applies tree.SnapAngles with snapIncrement, reports how many trees snapped and
+// returns the snapped trees with their side (or the input unchanged if none snapped)
+func snapPass(n int, trees []tree.ChristmasTree, score float64) ([]tree.ChristmasTree, float64) {
+	snapped := tree.SnapAngles(trees, snapIncrement)
+	count := 0
+	for i := range trees {
+		if snapped[i].Angle != trees[i].Angle {
+			count++
+		}
+	}
+	if count == 0 {
+		return trees, score
+	}
+	side := tree.CalculateSideLength(snapped)
+	fmt.Printf("Snap: n=%d snapped %d/%d trees to multiples of %g°, side %.6f -> %.6f\n",
+		n, count, len(trees), snapIncrement, tree.CalculateSideLength(trees), side)
+	return snapped, side
+}
+
+// runGreedy runs the greedy placement algorithm in parallel. With resumePath set, complete groups of that submission are kept as they are
+// and larger n continue placing trees from its largest complete group instead of starting over.
+func runGreedy(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree, resumePath string) [][]string {
+	var resumed map[int][]tree.ChristmasTree
+	var seed []tree.ChristmasTree
+	if resumePath != "" {
+		var err error
+		resumed, seed, err = loadResume(resumePath, numTrees)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading resume CSV: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	return runParallel(runCtx, numTrees, "", outputPath, "Greedy", startingPoints, func(n int, _ *sa.Config, _ []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+		if trees := resumed[n]; len(trees) == n {
+			return tree.CalculateSideLength(trees), trees
+		}
+		var trees []tree.ChristmasTree
+		var sideLength float64
+		if len(seed) < n {
+			trees, sideLength = greedyInitFrom(n, seed)
+		} else {
+			trees, sideLength = greedyInit(n)
+		}
 		return sideLength, trees
 	})
 }
 
-// loadConfig loads SA config from path or returns defaults
+// greedyLSCompactionIters bounds the Compaction or SettleToCentroid passes of greedy-ls (both
+// stop early once no tree can move closer to the center)
+const greedyLSCompactionIters = 100
+
+// runGreedyLS runs greedy placement followed by LocalSearch and a final settle pass (Compaction
+// or SettleToCentroid) in parallel, reporting the side before and after the local improvement
+func runGreedyLS(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree, lsIters int, settle string) [][]string {
+	return runParallel(runCtx, numTrees, "", outputPath, "Greedy+LS", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+		trees := startNodes
+		if len(trees) == 0 {
+			trees, _ = greedyInit(n)
+		}
+		before := tree.CalculateSideLength(trees)
+
+		trees = sa.LocalSearch(trees, lsIters)
+		if settle == "centroid" {
+			trees = sa.SettleToCentroid(trees, greedyLSCompactionIters)
+		} else {
+			trees = sa.Compaction(trees, greedyLSCompactionIters)
+		}
+
+		after := tree.CalculateSideLength(trees)
+		fmt.Printf("n=%d: side %.6f -> %.6f after local search (%.6f gained)\n", n, before, after, before-after)
+		return after, trees
+	})
+}
+
+// loadResume loads a submission to resume greedy packing from and picks its largest
+// complete group with at most maxN trees as the seed. A seed with collisions is used anyway
+// after a warning.
+func loadResume(path string, maxN int) (map[int][]tree.ChristmasTree, []tree.ChristmasTree, error) {
+	groups, err := tree.LoadSubmission(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	largest := 0
+	for n, trees := range groups {
+		if n <= maxN && len(trees) == n && n > largest {
+			largest = n
+		}
+	}
+	if largest == 0 {
+		return nil, nil, fmt.Errorf("%s has no complete group with at most %d trees", path, maxN)
+	}
+
+	seed, err := tree.LoadTreesFromCSV(path, largest)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tree.HasCollision(seed) {
+		fmt.Fprintf(os.Stderr, "Warning: resume group n=%d in %s has collisions, continuing anyway\n", largest, path)
+	}
+	fmt.Printf("Resuming greedy from %s: %d complete groups, seeding larger n from n=%d\n", path, len(groups), largest)
+	return groups, seed, nil
+}
+
+// greedyInit builds the greedy layout for n, falling back to the grid layout (with a
+// warning) if greedy placement could not place all n trees
+func greedyInit(n int) ([]tree.ChristmasTree, float64) {
+	return greedyInitFrom(n, nil)
+}
+
+// greedyInitFrom is greedyInit continuing from already placed trees
+func greedyInitFrom(n int, existing []tree.ChristmasTree) ([]tree.ChristmasTree, float64) {
+	trees, side, fellBack := greedy.InitializeTreesWithFallback(n, existing, greedyDirections, greedySource(n))
+	if fellBack {
+		fmt.Fprintf(os.Stderr, "Warning: greedy placed fewer than %d trees, using grid layout instead\n", n)
+	}
+	return trees, side
+}
+
+// greedySource returns n's own seeded source with -deterministic, or nil for the shared one
+func greedySource(n int) greedy.Source {
+	if !deterministic {
+		return nil
+	}
+	return rand.New(rand.NewSource(runSeed + int64(n)))
+}
+
+// loadConfig loads SA config from path or returns defaults, applying -score
 func loadConfig(configPath string) *sa.Config {
+	config := sa.DefaultConfig()
 	if configPath != "" {
-		config, err := sa.LoadConfig(configPath)
+		loaded, err := sa.LoadConfig(configPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to load config: %v, using defaults\n", err)
-			return sa.DefaultConfig()
+		} else {
+			config = loaded
 		}
-		return config
 	}
-	return sa.DefaultConfig()
+	if scoreObjective != "" {
+		config.Objective = sa.Objective(scoreObjective)
+	}
+	return config
+}
+
+// loadGAConfig loads the "ga" section of the config file, or the defaults if no path is given
+func loadGAConfig(configPath string) *grid.GAConfig {
+	if configPath == "" {
+		return grid.DefaultGAConfig()
+	}
+	config, err := grid.LoadGAConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load GA config: %v, using defaults\n", err)
+		return grid.DefaultGAConfig()
+	}
+	return config
 }
 
 // runSimulatedAnnealing runs SA optimization in parallel
@@ -217,26 +706,30 @@ func runSimulatedAnnealing(numTrees int, configPath string, outputPath string, u
 		algoName = "SA-Penalty"
 	}
 
-	return runParallel(numTrees, configPath, outputPath, algoName, startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+	// Ctrl+C stops the pool: running solves return their best so far and get written
+	ctx, stop := signal.NotifyContext(runCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return runParallel(ctx, numTrees, configPath, outputPath, algoName, startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
 		var initialTrees []tree.ChristmasTree
 		if len(startNodes) > 0 {
 			initialTrees = startNodes // copy? usually safe to use as is if solver doesn't mutate in place blindly
 		} else {
-			initialTrees, _ = greedy.InitializeTrees(n, nil)
+			initialTrees, _ = greedyInit(n)
 		}
 
 		if usePenalty {
 			solver := sa.NewSimulatedAnnealingPenalty(initialTrees, config)
-			return solver.SolvePenalty()
+			solver.OnImprove = curveHook(n)
+			return solver.SolvePenaltyContext(ctx)
 		}
-		solver := sa.NewSimulatedAnnealing(initialTrees, config)
-		return solver.Solve()
+		return solveSA(ctx, n, initialTrees, config)
 	})
 }
 
 // runGrid runs the grid-based placement algorithm in parallel
 func runGrid(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
-	return runParallel(numTrees, "", outputPath, "Grid", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+	return runParallel(runCtx, numTrees, "", outputPath, "Grid", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
 		if len(startNodes) > 0 {
 			// If provided, just evaluate them
 			return tree.CalculateScore(startNodes), startNodes
@@ -246,6 +739,30 @@ func runGrid(numTrees int, outputPath string, startingPoints map[int][]tree.Chri
 	})
 }
 
+// runHex runs the hexagonal-lattice placement algorithm in parallel
+func runHex(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
+	return runParallel(runCtx, numTrees, "", outputPath, "Hex", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+		if len(startNodes) > 0 {
+			// If provided, just evaluate them
+			return tree.CalculateScore(startNodes), startNodes
+		}
+		trees, score := tree.InitializeTreesHex(n, nil)
+		return score, trees
+	})
+}
+
+// runSpiral runs the deterministic spiral placement algorithm in parallel
+func runSpiral(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
+	return runParallel(runCtx, numTrees, "", outputPath, "Spiral", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+		if len(startNodes) > 0 {
+			// If provided, just evaluate them
+			return tree.CalculateScore(startNodes), startNodes
+		}
+		trees, side := tree.InitializeTreesSpiral(n)
+		return side, trees
+	})
+}
+
 // runGridSA runs grid-based initialization followed by SA optimization in parallel
 func runGridSA(numTrees int, configPath string, outputPath string, usePenalty bool, startingPoints map[int][]tree.ChristmasTree) [][]string {
 	algoName := "Grid+SA"
@@ -253,7 +770,7 @@ func runGridSA(numTrees int, configPath string, outputPath string, usePenalty bo
 		algoName = "Grid+SA-Penalty"
 	}
 
-	return runParallel(numTrees, configPath, outputPath, algoName, startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+	return runParallel(runCtx, numTrees, configPath, outputPath, algoName, startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
 		var gridTrees []tree.ChristmasTree
 		if len(startNodes) > 0 {
 			gridTrees = startNodes
@@ -263,52 +780,42 @@ func runGridSA(numTrees int, configPath string, outputPath string, usePenalty bo
 
 		if usePenalty {
 			solver := sa.NewSimulatedAnnealingPenalty(gridTrees, config)
-			return solver.SolvePenalty()
+			solver.OnImprove = curveHook(n)
+			return solver.SolvePenaltyContext(runCtx)
 		}
-		solver := sa.NewSimulatedAnnealing(gridTrees, config)
-		return solver.Solve()
+		return solveSA(runCtx, n, gridTrees, config)
 	})
 }
 
 // runAdvancedSA runs the advanced SA algorithm in parallel
 func runAdvancedSA(numTrees int, configPath string, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
-	return runParallel(numTrees, configPath, outputPath, "Advanced SA", startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+	return runParallel(runCtx, numTrees, configPath, outputPath, "Advanced SA", startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
 		var initialTrees []tree.ChristmasTree
 		if len(startNodes) > 0 {
 			initialTrees = startNodes
 		} else {
-			initialTrees, _ = greedy.InitializeTrees(n, nil)
+			initialTrees, _ = greedyInit(n)
 		}
 
-		bestTrees := sa.RunAdvancedSA(initialTrees, config)
+		bestTrees := sa.RunWarmRestarts(runCtx, initialTrees, config)
 		return tree.CalculateScore(bestTrees), bestTrees
 	})
 }
 
 // runAdvancedSAPenalty runs the advanced SA algorithm with penalty
 func runAdvancedSAPenalty(numTrees int, configPath string, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
-	return runParallel(numTrees, configPath, outputPath, "Advanced SA Penalty", startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+	return runParallel(runCtx, numTrees, configPath, outputPath, "Advanced SA Penalty", startingPoints, func(n int, config *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
 		var initialTrees []tree.ChristmasTree
 		if len(startNodes) > 0 {
 			initialTrees = startNodes
 		} else {
-			initialTrees, _ = greedy.InitializeTrees(n, nil)
+			initialTrees, _ = greedyInit(n)
 		}
-		bestTrees := sa.RunAdvancedSAPenalty(initialTrees, config)
+		bestTrees := sa.RunAdvancedSAPenaltyContext(runCtx, initialTrees, config)
 		return tree.CalculateScore(bestTrees), bestTrees
 	})
 }
 
-// formatTree formats a tree for CSV output
-func formatTree(n, idx int, t tree.ChristmasTree) []string {
-	return []string{
-		fmt.Sprintf("%03d_%d", n, idx),
-		fmt.Sprintf("s%.6f", t.X),
-		fmt.Sprintf("s%.6f", t.Y),
-		fmt.Sprintf("s%.6f", t.Angle),
-	}
-}
-
 // writeCSV writes tree data to a CSV file
 func writeCSV(path string, data [][]string) error {
 	dir := filepath.Dir(path)
@@ -332,61 +839,390 @@ func writeCSV(path string, data [][]string) error {
 	return writer.WriteAll(data)
 }
 
-// runGridGA runs the genetic algorithm grid placement in parallel
-func runGridGA(numTrees int, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
-	return runParallel(numTrees, "", outputPath, "Grid GA", startingPoints, func(n int, _ *sa.Config, _ []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
-		score, trees := grid.FindBestGridGASolution(n)
-		return score, trees
-	})
+// groupRows formats group n as submission rows (tree.SubmissionRows), first warning if
+// the group fails tree.CheckGroupIntegrity so a short or malformed group is not written
+// silently. The trees are kept in finalGroups for the JSON output.
+func groupRows(n int, trees []tree.ChristmasTree) [][]string {
+	if err := tree.CheckGroupIntegrity(n, trees); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	finalGroups.Lock()
+	finalGroups.trees[n] = trees
+	finalGroups.Unlock()
+	return tree.SubmissionRows(n, trees)
 }
 
-func loadStartingPoints(path string) (map[int][]tree.ChristmasTree, error) {
-	f, err := os.Open(path)
+// jsonResults returns the layouts to write as JSON: the in-memory trees of every group
+// formatted by groupRows, and the written CSV's for groups copied from an input file as
+// they were (e.g. those refine does not touch)
+func jsonResults(csvPath string) (map[int][]tree.ChristmasTree, error) {
+	results, err := tree.LoadSubmission(csvPath)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	finalGroups.Lock()
+	defer finalGroups.Unlock()
+	for n, trees := range finalGroups.trees {
+		results[n] = trees
+	}
+	return results, nil
+}
+
+// jsonTree is one tree of the JSON output, with the true rotation like the CSV
+type jsonTree struct {
+	ID    string  `json:"id"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+}
+
+// jsonGroup is the layout for one n in the JSON output
+type jsonGroup struct {
+	N     int        `json:"n"`
+	Side  float64    `json:"side"`
+	Trees []jsonTree `json:"trees"`
+}
+
+// writeJSON writes the layouts as a JSON array of groups ordered by n, each with its side
+// length and trees; values are plain numbers rather than the CSV's "s"-prefixed strings
+func writeJSON(path string, results map[int][]tree.ChristmasTree) error {
+	ns := tree.SortedGroups(results)
+	groups := make([]jsonGroup, 0, len(ns))
+	for _, n := range ns {
+		trees := tree.SortByID(results[n])
+		g := jsonGroup{N: n, Side: tree.CalculateSideLength(trees), Trees: make([]jsonTree, len(trees))}
+		for i, t := range trees {
+			g.Trees[i] = jsonTree{
+				ID:    fmt.Sprintf("%03d_%d", n, i),
+				X:     t.X,
+				Y:     t.Y,
+				Angle: t.Angle + tree.BaseAngleOffset,
+			}
+		}
+		groups = append(groups, g)
+	}
 
-	reader := csv.NewReader(f)
-	records, err := reader.ReadAll()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(groups, "", "  ")
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
 
-	result := make(map[int][]tree.ChristmasTree)
-	startIdx := 0
-	if len(records) > 0 && len(records[0]) > 0 && strings.ToLower(records[0][0]) == "id" {
-		startIdx = 1
+// runGridGA runs the genetic algorithm grid placement in parallel
+func runGridGA(numTrees int, configPath string, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
+	gaConfig := loadGAConfig(configPath)
+	return runParallel(runCtx, numTrees, "", outputPath, "Grid GA", startingPoints, func(n int, _ *sa.Config, startNodes []tree.ChristmasTree) (float64, []tree.ChristmasTree) {
+		if len(startNodes) > 0 {
+			// Warm-start from the pair pattern of the loaded layout
+			return grid.FindBestGridGASolutionFromSeed(n, startNodes, gaConfig)
+		}
+		score, trees := grid.FindBestGridGASolution(n, gaConfig)
+		return score, trees
+	})
+}
+
+// runFit checks whether numTrees trees fit in a square of the given side
+func runFit(numTrees int, side float64, configPath string) [][]string {
+	if side <= 0 {
+		fmt.Fprintf(os.Stderr, "The fit algorithm requires -side > 0\n")
+		os.Exit(1)
+	}
+	config := sa.ConfigForN(loadConfig(configPath), numTrees)
+	trees, ok := sa.FitInSquare(numTrees, side, config)
+	if ok {
+		fmt.Printf("Fit: n=%d fits in side %.5f (actual side %.5f)\n", numTrees, side, tree.CalculateSideLength(trees))
+	} else {
+		fmt.Printf("Fit: n=%d does NOT fit in side %.5f, writing least-overlapping layout\n", numTrees, side)
 	}
 
-	for _, record := range records[startIdx:] {
-		if len(record) < 4 {
-			continue
+	return groupRows(numTrees, trees)
+}
+
+// runRefine re-optimizes the groups listed in only (e.g. "47,83,190") of the submission
+// at inputPath with SA, penalty SA for a group that has overlaps, and keeps a result only if
+// it is valid and smaller (or the group was invalid). The rows of every other group, and of
+// groups that did not improve, are copied from the input exactly as written.
+func runRefine(inputPath, only, configPath string) [][]string {
+	if inputPath == "" || only == "" {
+		fmt.Fprintf(os.Stderr, "The refine algorithm requires -input and -only\n")
+		os.Exit(1)
+	}
+	var ns []int
+	for _, field := range strings.Split(only, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "Invalid n %q in -only\n", field)
+			os.Exit(1)
 		}
-		// Parse ID: "005_2" -> N=5
-		parts := strings.Split(record[0], "_")
-		if len(parts) != 2 {
+		ns = append(ns, n)
+	}
+	slices.Sort(ns)
+	ns = slices.Compact(ns)
+
+	rows, err := tree.LoadSubmissionRows(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading submission: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(runCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	config := loadConfig(configPath)
+	for _, n := range ns {
+		if ctx.Err() != nil {
+			fmt.Printf("Refine: stopped, keeping n=%d as is\n", n)
 			continue
 		}
-		n, err := strconv.Atoi(parts[0])
+		trees, err := tree.LoadTreesFromCSV(inputPath, n)
 		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping n=%d: %v\n", n, err)
 			continue
 		}
 
-		// Parse X, Y, Angle (remove 's')
-		parseVal := func(s string) float64 {
-			s = strings.TrimPrefix(s, "s")
-			v, _ := strconv.ParseFloat(s, 64)
-			return v
+		nConfig := *sa.ConfigForN(config, n)
+		nConfig.RandomSeed += int64(n)
+		if quiet {
+			nConfig.LogLevel = sa.LogSilent
+		}
+		before, valid := tree.CalculateSideLength(trees), !tree.HasCollision(trees)
+		var best []tree.ChristmasTree
+		if valid {
+			_, best = solveSA(ctx, n, trees, &nConfig)
+		} else {
+			_, best = sa.NewSimulatedAnnealingPenalty(trees, &nConfig).SolvePenaltyContext(ctx)
 		}
 
-		t := tree.ChristmasTree{
-			ID:    len(result[n]) + 1,
-			X:     parseVal(record[1]),
-			Y:     parseVal(record[2]),
-			Angle: parseVal(record[3]),
+		after := tree.CalculateSideLength(best)
+		if len(best) != n || tree.HasCollision(best) || (valid && after >= before-1e-12) {
+			fmt.Printf("Refine: n=%d side %.5f, no valid improvement (kept)\n", n, before)
+			continue
 		}
-		result[n] = append(result[n], t)
+		fmt.Printf("Refine: n=%d side %.5f -> %.5f (improved)\n", n, before, after)
+		rows[n] = groupRows(n, best)
+	}
+
+	var treeData [][]string
+	for _, n := range slices.Sorted(maps.Keys(rows)) {
+		treeData = append(treeData, rows[n]...)
 	}
-	return result, nil
+	return treeData
+}
+
+// runRepair loads a submission and repairs every group that has overlaps
+func runRepair(inputPath string) [][]string {
+	if inputPath == "" {
+		fmt.Fprintf(os.Stderr, "The repair algorithm requires -input\n")
+		os.Exit(1)
+	}
+	groups, err := tree.LoadSubmission(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading submission: %v\n", err)
+		os.Exit(1)
+	}
+
+	var treeData [][]string
+	for _, n := range tree.SortedGroups(groups) {
+		trees := groups[n]
+		if tree.HasCollision(trees) {
+			before := tree.CalculateSideLength(trees)
+			trees = sa.Repair(trees, 2000)
+			status := "valid"
+			if tree.HasCollision(trees) {
+				status = "STILL INVALID"
+			}
+			fmt.Printf("Repair: n=%d side %.5f -> %.5f (%s)\n", n, before, tree.CalculateSideLength(trees), status)
+		}
+		treeData = append(treeData, groupRows(n, trees)...)
+	}
+	return treeData
+}
+
+// runValidate checks a submission for missing groups, wrong tree counts and overlaps,
+// printing every problem found; it returns whether the submission is valid
+func runValidate(inputPath string) bool {
+	if inputPath == "" {
+		fmt.Fprintln(os.Stderr, "validate requires -input")
+		os.Exit(1)
+	}
+	report, err := tree.ValidateSubmission(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	invalid := 0
+	for _, g := range report.Groups {
+		if g.Valid() {
+			continue
+		}
+		invalid++
+		if g.Count != g.N {
+			fmt.Printf("Validate: n=%3d has %d trees, expected %d\n", g.N, g.Count, g.N)
+		} else if g.Err != nil {
+			fmt.Printf("Validate: %v\n", g.Err)
+		}
+		for _, p := range g.Pairs {
+			fmt.Printf("Validate: n=%3d trees %d and %d overlap\n", g.N, p[0], p[1])
+		}
+	}
+	if len(report.Missing) > 0 {
+		fmt.Printf("Validate: missing groups %v\n", report.Missing)
+	}
+	fmt.Printf("Validate: %d groups, %d invalid, %d missing, score %.6f\n",
+		len(report.Groups), invalid, len(report.Missing), report.Score)
+	return report.Valid()
+}
+
+// runBench times greedy, grid and one SA pass on n trees and prints each elapsed time and
+// side, for tracking solver performance across code changes. Greedy and SA are seeded from
+// seed (1 when 0) and SA starts from the greedy layout, so repeated runs do the same work.
+func runBench(n int, configPath string, seed int64) {
+	if seed == 0 {
+		seed = 1
+	}
+	config := loadConfig(configPath)
+	config.RandomSeed = seed
+	config.LogLevel = sa.LogSilent
+
+	fmt.Printf("Benchmark: n=%d, seed=%d\n", n, seed)
+	fmt.Printf("%-8s %12s %12s %10s\n", "solver", "elapsed", "side", "score")
+	report := func(name string, elapsed time.Duration, trees []tree.ChristmasTree) {
+		valid := ""
+		if tree.HasCollision(trees) {
+			valid = " (overlaps)"
+		}
+		fmt.Printf("%-8s %12s %12.6f %10.6f%s\n", name, elapsed.Round(time.Microsecond), tree.Side(trees), tree.Score(trees), valid)
+	}
+
+	start := time.Now()
+	initial, _, _ := greedy.InitializeTreesWithFallback(n, nil, greedyDirections, rand.New(rand.NewSource(seed)))
+	report("greedy", time.Since(start), initial)
+
+	start = time.Now()
+	_, gridTrees := grid.FindBestSolution(n)
+	report("grid", time.Since(start), gridTrees)
+
+	start = time.Now()
+	_, saTrees := sa.NewSimulatedAnnealing(initial, config).Solve()
+	report("sa", time.Since(start), saTrees)
+}
+
+// runCompare prints a per-n comparison table of two submissions and their aggregate scores
+func runCompare(pathA, pathB string) {
+	if pathA == "" || pathB == "" {
+		fmt.Fprintln(os.Stderr, "compare requires -input and -input2")
+		os.Exit(1)
+	}
+	report, err := tree.CompareSubmissions(pathA, pathB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// side formats a group's side, flagging invalid and missing groups
+	side := func(has, valid bool, s float64) string {
+		switch {
+		case !has:
+			return "missing"
+		case !valid:
+			return fmt.Sprintf("%.6f!", s)
+		}
+		return fmt.Sprintf("%.6f", s)
+	}
+
+	fmt.Printf("A: %s\nB: %s\n", pathA, pathB)
+	fmt.Printf("%5s %12s %12s %7s\n", "n", "side A", "side B", "winner")
+	for _, g := range report.Groups {
+		winner := g.Winner
+		if winner == "" {
+			winner = "none"
+		}
+		fmt.Printf("%5d %12s %12s %7s\n", g.N, side(g.HasA, g.ValidA, g.SideA), side(g.HasB, g.ValidB, g.SideB), winner)
+	}
+	fmt.Println("(! = overlaps or wrong tree count)")
+	if len(report.OnlyA) > 0 || len(report.OnlyB) > 0 {
+		fmt.Printf("Only in A: %v, only in B: %v\n", report.OnlyA, report.OnlyB)
+	}
+	fmt.Printf("Wins: A %d, B %d, ties %d\n", report.WinsA, report.WinsB, report.Ties)
+	fmt.Printf("Sum of sides: A %.6f, B %.6f\n", report.SideSumA, report.SideSumB)
+	fmt.Printf("Score (sum side^2/n): A %.6f, B %.6f\n", report.ScoreA, report.ScoreB)
+}
+
+// runBestMerge loads several submissions and keeps, per n, the best valid group among them
+func runBestMerge(inputPaths string) [][]string {
+	var paths []string
+	for _, p := range strings.Split(inputPaths, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "The best-merge algorithm requires -inputs (or -input) with comma-separated CSV paths\n")
+		os.Exit(1)
+	}
+
+	merged, source, fallback, err := tree.MergeBest(paths)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading submission: %v\n", err)
+		os.Exit(1)
+	}
+	if len(fallback) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no valid group for n=%v; using the least overlapping one\n", fallback)
+	}
+	wins := make([]int, len(paths))
+	for _, k := range source {
+		wins[k]++
+	}
+	for k, p := range paths {
+		fmt.Printf("Best-merge: %s provides %d groups\n", p, wins[k])
+	}
+	fmt.Printf("Best-merge: merged score %.5f over %d groups\n", tree.ScoreSubmission(merged), len(merged))
+
+	var treeData [][]string
+	for _, n := range tree.SortedGroups(merged) {
+		treeData = append(treeData, groupRows(n, merged[n])...)
+	}
+	return treeData
+}
+
+// runForever keeps improving every n with short advanced SA passes until interrupted,
+// writing a checkpoint to outputPath after each pass
+func runForever(numTrees int, configPath string, outputPath string, startingPoints map[int][]tree.ChristmasTree) [][]string {
+	config := loadConfig(configPath)
+
+	best := make(map[int][]tree.ChristmasTree)
+	for n := 1; n <= numTrees; n++ {
+		if len(startingPoints[n]) == n {
+			best[n] = startingPoints[n]
+		} else {
+			best[n], _ = greedyInit(n)
+		}
+	}
+
+	toTreeData := func() [][]string {
+		var data [][]string
+		for n := 1; n <= numTrees; n++ {
+			data = append(data, groupRows(n, best[n])...)
+		}
+		return data
+	}
+
+	ctx, stop := signal.NotifyContext(runCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Running forever mode, press Ctrl+C to stop")
+	sa.ImproveForever(ctx, best, config, 0, func(pass int) {
+		if err := writeCSV(outputPath, toTreeData()); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write checkpoint: %v\n", err)
+		} else {
+			fmt.Printf("Checkpoint after pass %d written to %s\n", pass, outputPath)
+		}
+	})
+	fmt.Println("Interrupted, writing best results")
+
+	return toTreeData()
 }
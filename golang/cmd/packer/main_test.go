@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestDeadlineWritesPartialSubmission(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	runCtx = ctx
+	defer func() { runCtx = context.Background() }()
+
+	output := filepath.Join(t.TempDir(), "submission.csv")
+	start := time.Now()
+	data := runSimulatedAnnealing(30, "", output, false, nil)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Run did not stop promptly after the deadline: took %s", elapsed)
+	}
+
+	if err := writeCSV(output, data); err != nil {
+		t.Fatalf("writeCSV: %v", err)
+	}
+	groups, err := tree.LoadSubmission(output)
+	if err != nil {
+		t.Fatalf("Partial submission is not loadable: %v", err)
+	}
+	if len(groups) == 0 {
+		t.Fatalf("Expected at least one completed n in the partial submission")
+	}
+	for n, trees := range groups {
+		if len(trees) != n {
+			t.Errorf("n=%d: expected %d trees, got %d", n, n, len(trees))
+		}
+		if tree.HasCollision(trees) {
+			t.Errorf("n=%d: partial result has collisions", n)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	results := map[int][]tree.ChristmasTree{
+		2: {{ID: 1, X: 0.5, Y: -0.25, Angle: 45}, {ID: 2, X: 1.5, Y: 0, Angle: 0}},
+		1: {{ID: 1, X: 0, Y: 0, Angle: 90}},
+	}
+	path := filepath.Join(t.TempDir(), "out", "submission.json")
+	if err := writeJSON(path, results); err != nil {
+		t.Fatalf("writeJSON: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups []jsonGroup
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if len(groups) != 2 || groups[0].N != 1 || groups[1].N != 2 {
+		t.Fatalf("Expected groups ordered by n, got %+v", groups)
+	}
+	g := groups[1]
+	if g.Side != tree.CalculateSideLength(results[2]) {
+		t.Errorf("Side %v, want %v", g.Side, tree.CalculateSideLength(results[2]))
+	}
+	want := jsonTree{ID: "002_0", X: 0.5, Y: -0.25, Angle: 45}
+	if g.Trees[0] != want {
+		t.Errorf("First tree %+v, want %+v", g.Trees[0], want)
+	}
+}
+
+func TestJSONResultsKeepRawFloats(t *testing.T) {
+	// groupRows keeps the solver's trees; the CSV rounds them
+	trees := []tree.ChristmasTree{{ID: 0, X: 0.123456789012345, Y: -1.0 / 3, Angle: 12.3456789012345}}
+	path := filepath.Join(t.TempDir(), "submission.csv")
+	if err := writeCSV(path, groupRows(1, trees)); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { delete(finalGroups.trees, 1) })
+
+	results, err := jsonResults(path)
+	if err != nil {
+		t.Fatalf("jsonResults: %v", err)
+	}
+	if got := results[1][0]; got.X != trees[0].X || got.Y != trees[0].Y || got.Angle != trees[0].Angle {
+		t.Errorf("Got %+v, want the in-memory %+v", got, trees[0])
+	}
+}
+
+func TestGreedyLSNotWorseThanGreedy(t *testing.T) {
+	// With -deterministic each n's greedy layout is reproducible, so the greedy side can be
+	// compared with what greedy-ls made of the same layout
+	deterministic, runSeed = true, 7
+	defer func() { deterministic, runSeed = false, 0 }()
+
+	const numTrees = 6
+	output := filepath.Join(t.TempDir(), "submission.csv")
+	if err := writeCSV(output, runGreedyLS(numTrees, output, nil, 200, "")); err != nil {
+		t.Fatalf("writeCSV: %v", err)
+	}
+	groups, err := tree.LoadSubmission(output)
+	if err != nil {
+		t.Fatalf("LoadSubmission: %v", err)
+	}
+	for n := 1; n <= numTrees; n++ {
+		_, greedySide := greedyInit(n)
+		trees := groups[n]
+		if len(trees) != n || tree.HasCollision(trees) {
+			t.Fatalf("n=%d: greedy-ls returned %d trees (collision: %v)", n, len(trees), tree.HasCollision(trees))
+		}
+		// The CSV rounds coordinates, so allow for that much
+		if side := tree.CalculateSideLength(trees); side > greedySide+1e-5 {
+			t.Errorf("n=%d: greedy-ls side %.6f is worse than greedy's %.6f", n, side, greedySide)
+		}
+	}
+}
@@ -6,17 +6,36 @@ import (
 	"math"
 	"math/rand"
 
+	"tree-packing-challenge/pkg/solvers/grid"
 	"tree-packing-challenge/pkg/tree"
 
 	"github.com/tidwall/rtree"
 )
 
+// randomAttempts is the number of weighted random directions tried per tree
+var randomAttempts = 10
+
+// Source is the randomness greedy placement draws from; *rand.Rand satisfies it
+type Source interface {
+	Float64() float64
+}
+
+// globalSource draws from the shared math/rand source
+type globalSource struct{}
+
+func (globalSource) Float64() float64 { return rand.Float64() }
+
 // GenerateWeightedAngle generates a random angle in DEGREES with distribution weighted by abs(sin(2*angle))
 func GenerateWeightedAngle() float64 {
+	return weightedAngle(globalSource{})
+}
+
+// weightedAngle is GenerateWeightedAngle drawing from rng
+func weightedAngle(rng Source) float64 {
 	for {
-		angleDeg := rand.Float64() * 360.0
+		angleDeg := rng.Float64() * 360.0
 		angleRad := angleDeg * math.Pi / 180.0
-		if rand.Float64() < math.Abs(math.Sin(2*angleRad)) {
+		if rng.Float64() < math.Abs(math.Sin(2*angleRad)) {
 			return angleDeg
 		}
 	}
@@ -24,6 +43,21 @@ func GenerateWeightedAngle() float64 {
 
 // InitializeTrees builds a greedy packing of n trees
 func InitializeTrees(numTrees int, existingTrees []tree.ChristmasTree) ([]tree.ChristmasTree, float64) {
+	return InitializeTreesWithDirections(numTrees, existingTrees, 0)
+}
+
+// InitializeTreesWithDirections builds a greedy packing of n trees like InitializeTrees, but
+// each new tree also slides in along D evenly spaced directions in addition to the 10
+// weighted random ones, keeping the minimal-radius placement over all of them.
+// Larger D finds tighter pockets at the cost of D extra slides per tree; D = 0 disables the sweep.
+func InitializeTreesWithDirections(numTrees int, existingTrees []tree.ChristmasTree, directions int) ([]tree.ChristmasTree, float64) {
+	return InitializeTreesWithSource(numTrees, existingTrees, directions, globalSource{})
+}
+
+// InitializeTreesWithSource is InitializeTreesWithDirections drawing all its randomness
+// from rng instead of the shared math/rand source, so a seeded rng makes it reproducible
+// even while other goroutines use math/rand.
+func InitializeTreesWithSource(numTrees int, existingTrees []tree.ChristmasTree, directions int, rng Source) ([]tree.ChristmasTree, float64) {
 	if numTrees == 0 {
 		return []tree.ChristmasTree{}, 0
 	}
@@ -31,167 +65,203 @@ func InitializeTrees(numTrees int, existingTrees []tree.ChristmasTree) ([]tree.C
 	placedTrees := make([]tree.ChristmasTree, len(existingTrees))
 	copy(placedTrees, existingTrees)
 
-	// Spatial Index for fast collision detection
+	// Spatial Index for fast collision detection, plus running bounds for the side length
 	tr := rtree.RTree{}
+	bounds := tree.NewBounds()
 	for i, t := range placedTrees {
 		minX, minY, maxX, maxY := t.GetBoundingBox()
 		tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+		bounds.Extend(minX, minY, maxX, maxY)
 	}
 
 	numToAdd := numTrees - len(placedTrees)
 	if numToAdd > 0 {
 		// If starting from scratch, place first tree at origin
 		if len(placedTrees) == 0 {
-			t := tree.ChristmasTree{ID: 0, X: 0, Y: 0, Angle: rand.Float64() * 360.0}
+			t := tree.ChristmasTree{ID: 0, X: 0, Y: 0, Angle: rng.Float64() * 360.0}
 			placedTrees = append(placedTrees, t)
 			minX, minY, maxX, maxY := t.GetBoundingBox()
 			tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, 0)
+			bounds.Extend(minX, minY, maxX, maxY)
 			numToAdd--
 		}
 
 		for i := 0; i < numToAdd; i++ {
 			newID := len(placedTrees)
-			treeToPlace := tree.ChristmasTree{ID: newID, Angle: rand.Float64() * 360.0}
-
-			var bestX, bestY float64
-			minRadius := math.Inf(1)
-			foundPlacement := false
-
-			// Try 10 random starting attempts
-			for attempt := 0; attempt < 10; attempt++ {
-				angle := GenerateWeightedAngle()
-				angleRad := angle * math.Pi / 180.0
-				vx := math.Cos(angleRad)
-				vy := math.Sin(angleRad)
-
-				radius := 20.0
-				stepIn := 0.5
-
-				collisionFound := false
-
-				// Move towards center
-				for radius >= 0 {
-					px := radius * vx
-					py := radius * vy
-
-					treeToPlace.X = px
-					treeToPlace.Y = py
-
-					// Check collision using spatial index
-					candidateBoundsMinX, candidateBoundsMinY, candidateBoundsMaxX, candidateBoundsMaxY := treeToPlace.GetBoundingBox()
-
-					// Query RTree for potential collisions
-					possibleCollisions := []int{}
-					tr.Search(
-						[2]float64{candidateBoundsMinX, candidateBoundsMinY},
-						[2]float64{candidateBoundsMaxX, candidateBoundsMaxY},
-						func(min, max [2]float64, data interface{}) bool {
-							possibleCollisions = append(possibleCollisions, data.(int))
-							return true
-						},
-					)
-
-					// Check for actual collisions
-					isColliding := false
-					for _, otherIdx := range possibleCollisions {
-						if treeToPlace.Intersect(&placedTrees[otherIdx]) {
-							isColliding = true
-							break
-						}
-					}
+			treeToPlace := tree.ChristmasTree{ID: newID, Angle: rng.Float64() * 360.0}
 
-					if isColliding {
-						collisionFound = true
-						break
-					}
-
-					radius -= stepIn
-				}
-
-				// Back up if collision was found
-				if collisionFound {
-					stepOut := 0.05
-					for {
-						radius += stepOut
-						px := radius * vx
-						py := radius * vy
-
-						treeToPlace.X = px
-						treeToPlace.Y = py
-
-						// Check collision again
-						candidateBoundsMinX, candidateBoundsMinY, candidateBoundsMaxX, candidateBoundsMaxY := treeToPlace.GetBoundingBox()
-
-						possibleCollisions := []int{}
-						tr.Search(
-							[2]float64{candidateBoundsMinX, candidateBoundsMinY},
-							[2]float64{candidateBoundsMaxX, candidateBoundsMaxY},
-							func(min, max [2]float64, data interface{}) bool {
-								possibleCollisions = append(possibleCollisions, data.(int))
-								return true
-							},
-						)
-
-						isColliding := false
-						for _, otherIdx := range possibleCollisions {
-							if treeToPlace.Intersect(&placedTrees[otherIdx]) {
-								isColliding = true
-								break
-							}
-						}
-
-						if !isColliding {
-							break
-						}
-					}
-				} else {
-					// No collision found even at center
-					radius = 0
-					treeToPlace.X = 0
-					treeToPlace.Y = 0
-				}
-
-				if radius < minRadius {
-					minRadius = radius
-					bestX = treeToPlace.X
-					bestY = treeToPlace.Y
-					foundPlacement = true
-				}
+			// Weighted random directions, plus the evenly spaced sweep
+			dirs := make([]float64, 0, randomAttempts+directions)
+			for attempt := 0; attempt < randomAttempts; attempt++ {
+				dirs = append(dirs, weightedAngle(rng))
+			}
+			for d := 0; d < directions; d++ {
+				dirs = append(dirs, float64(d)*360.0/float64(directions))
 			}
 
-			if foundPlacement {
-				treeToPlace.X = bestX
-				treeToPlace.Y = bestY
+			if bestPlacement(&tr, placedTrees, &treeToPlace, dirs) {
 				placedTrees = append(placedTrees, treeToPlace)
 				minX, minY, maxX, maxY := treeToPlace.GetBoundingBox()
 				tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, newID)
+				bounds.Extend(minX, minY, maxX, maxY)
 			}
 		}
 	}
 
-	// Calculate side length
-	minX, minY := math.MaxFloat64, math.MaxFloat64
-	maxX, maxY := -math.MaxFloat64, -math.MaxFloat64
+	return placedTrees, bounds.Side()
+}
 
-	for _, t := range placedTrees {
-		tMinX, tMinY, tMaxX, tMaxY := t.GetBoundingBox()
-		if tMinX < minX {
-			minX = tMinX
-		}
-		if tMinY < minY {
-			minY = tMinY
+// InitializeTreesWithFallback runs InitializeTreesWithSource (on the shared math/rand source
+// if rng is nil) and, if greedy placement came up short of numTrees trees, falls back to
+// grid.FindBestSolution so the count is always correct. The returned bool reports whether
+// the fallback was used.
+func InitializeTreesWithFallback(numTrees int, existingTrees []tree.ChristmasTree, directions int, rng Source) ([]tree.ChristmasTree, float64, bool) {
+	if rng == nil {
+		rng = globalSource{}
+	}
+	trees, side := InitializeTreesWithSource(numTrees, existingTrees, directions, rng)
+	if len(trees) >= numTrees {
+		return trees, side, false
+	}
+	side, trees = grid.FindBestSolution(numTrees)
+	return trees, side, true
+}
+
+// bestPlacement slides the tree in along each direction (degrees) and moves it to the
+// placement closest to the origin. Returns false if dirs is empty.
+func bestPlacement(tr *rtree.RTree, placedTrees []tree.ChristmasTree, treeToPlace *tree.ChristmasTree, dirs []float64) bool {
+	var bestX, bestY float64
+	minRadius := math.Inf(1)
+	foundPlacement := false
+
+	for _, angle := range dirs {
+		radius := slideIn(tr, placedTrees, treeToPlace, angle)
+		if radius < minRadius {
+			minRadius = radius
+			bestX = treeToPlace.X
+			bestY = treeToPlace.Y
+			foundPlacement = true
 		}
-		if tMaxX > maxX {
-			maxX = tMaxX
+	}
+
+	if foundPlacement {
+		treeToPlace.X = bestX
+		treeToPlace.Y = bestY
+	}
+	return foundPlacement
+}
+
+// slideIn moves the tree from far out towards the origin along the given direction (degrees)
+// until it collides, then backs out until it is free. The tree is left at the final
+// position and its distance from the origin is returned.
+func slideIn(tr *rtree.RTree, placedTrees []tree.ChristmasTree, treeToPlace *tree.ChristmasTree, angle float64) float64 {
+	angleRad := angle * math.Pi / 180.0
+	vx := math.Cos(angleRad)
+	vy := math.Sin(angleRad)
+
+	radius := 20.0
+	stepIn := 0.5
+
+	collisionFound := false
+
+	// Move towards center
+	for radius >= 0 {
+		treeToPlace.X = radius * vx
+		treeToPlace.Y = radius * vy
+
+		if collidesWithPlaced(tr, placedTrees, treeToPlace) {
+			collisionFound = true
+			break
 		}
-		if tMaxY > maxY {
-			maxY = tMaxY
+
+		radius -= stepIn
+	}
+
+	// Back up if collision was found
+	if collisionFound {
+		stepOut := 0.05
+		for {
+			radius += stepOut
+			treeToPlace.X = radius * vx
+			treeToPlace.Y = radius * vy
+
+			if !collidesWithPlaced(tr, placedTrees, treeToPlace) {
+				break
+			}
 		}
+	} else {
+		// No collision found even at center
+		radius = 0
+		treeToPlace.X = 0
+		treeToPlace.Y = 0
+	}
+
+	return radius
+}
+
+// collidesWithPlaced checks the tree against the placed trees using the spatial index and
+// IntersectSAT, which agrees with Intersect at a fraction of its cost
+func collidesWithPlaced(tr *rtree.RTree, placedTrees []tree.ChristmasTree, treeToPlace *tree.ChristmasTree) bool {
+	minX, minY, maxX, maxY := treeToPlace.GetBoundingBox()
+
+	isColliding := false
+	tr.Search(
+		[2]float64{minX, minY},
+		[2]float64{maxX, maxY},
+		func(min, max [2]float64, data interface{}) bool {
+			if treeToPlace.IntersectSAT(&placedTrees[data.(int)]) {
+				isColliding = true
+				return false
+			}
+			return true
+		},
+	)
+	return isColliding
+}
+
+// AddTree adds one tree at the lowest, then leftmost, collision-free position that keeps
+// it inside the square [0, side] x [0, side]. Returns the extended slice and whether a
+// position was found; the input slice is returned unchanged when the square is full.
+func AddTree(placedTrees []tree.ChristmasTree, side float64) ([]tree.ChristmasTree, bool) {
+	const step = 0.05
+	angles := []float64{0, 180, 90, 270}
+
+	tr := rtree.RTree{}
+	for i, t := range placedTrees {
+		minX, minY, maxX, maxY := t.GetBoundingBox()
+		tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
 	}
 
-	width := maxX - minX
-	height := maxY - minY
-	sideLength := math.Max(width, height)
+	candidate := tree.ChristmasTree{ID: len(placedTrees)}
+	for y := 0.0; y <= side; y += step {
+		for x := 0.0; x <= side; x += step {
+			for _, angle := range angles {
+				candidate.X, candidate.Y, candidate.Angle = x, y, angle
+
+				minX, minY, maxX, maxY := candidate.GetBoundingBox()
+				if minX < 0 || minY < 0 || maxX > side || maxY > side {
+					continue
+				}
+
+				isColliding := false
+				tr.Search(
+					[2]float64{minX, minY},
+					[2]float64{maxX, maxY},
+					func(min, max [2]float64, data interface{}) bool {
+						if candidate.Intersect(&placedTrees[data.(int)]) {
+							isColliding = true
+							return false
+						}
+						return true
+					},
+				)
+
+				if !isColliding {
+					return append(placedTrees, candidate), true
+				}
+			}
+		}
+	}
 
-	return placedTrees, sideLength
+	return placedTrees, false
 }
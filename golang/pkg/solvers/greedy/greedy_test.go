@@ -0,0 +1,102 @@
+package greedy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+
+	"github.com/tidwall/rtree"
+)
+
+func TestMoreDirectionsNeverWorsenPlacement(t *testing.T) {
+	// Fixed layout so the comparison does not depend on the global RNG
+	placed := []tree.ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 1.0, Y: 0, Angle: 180},
+		{ID: 2, X: -1.0, Y: 0.1, Angle: 90},
+		{ID: 3, X: 0.2, Y: 1.2, Angle: 45},
+	}
+	tr := rtree.RTree{}
+	for i, p := range placed {
+		minX, minY, maxX, maxY := p.GetBoundingBox()
+		tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+	}
+
+	sweep := func(d int) []float64 {
+		dirs := []float64{17, 133, 251}
+		for k := 0; k < d; k++ {
+			dirs = append(dirs, float64(k)*360.0/float64(d))
+		}
+		return dirs
+	}
+
+	if tree.HasCollision(placed) {
+		t.Fatalf("Test layout must be collision-free")
+	}
+
+	prevRadius := math.Inf(1)
+	for _, d := range []int{0, 4, 16, 64} {
+		candidate := tree.ChristmasTree{ID: 4, Angle: 30}
+		if !bestPlacement(&tr, placed, &candidate, sweep(d)) {
+			t.Fatalf("D=%d: no placement found", d)
+		}
+		if tree.HasCollision(append(append([]tree.ChristmasTree{}, placed...), candidate)) {
+			t.Fatalf("D=%d: placement collides", d)
+		}
+
+		// Sweeps of 4, 16 and 64 are nested, so a larger D only adds candidate directions
+		radius := math.Hypot(candidate.X, candidate.Y)
+		if radius > prevRadius+1e-9 {
+			t.Errorf("D=%d worsened the placement radius: %.5f > %.5f", d, radius, prevRadius)
+		}
+		prevRadius = radius
+	}
+
+	trees, side := InitializeTreesWithDirections(8, nil, 16)
+	if len(trees) != 8 || tree.HasCollision(trees) {
+		t.Errorf("Expected 8 collision-free trees, got %d", len(trees))
+	}
+	if side <= 0 {
+		t.Errorf("Expected positive side, got %v", side)
+	}
+}
+
+func TestFallbackToGridWhenShort(t *testing.T) {
+	// Without any directions greedy cannot place anything after the first tree
+	saved := randomAttempts
+	randomAttempts = 0
+	defer func() { randomAttempts = saved }()
+
+	short, _ := InitializeTreesWithDirections(5, nil, 0)
+	if len(short) >= 5 {
+		t.Fatalf("Expected a short greedy placement, got %d trees", len(short))
+	}
+
+	trees, side, fellBack := InitializeTreesWithFallback(5, nil, 0, nil)
+	if !fellBack {
+		t.Errorf("Expected the grid fallback to trigger")
+	}
+	if len(trees) != 5 || tree.HasCollision(trees) {
+		t.Errorf("Expected 5 collision-free trees from the fallback, got %d", len(trees))
+	}
+	if side <= 0 {
+		t.Errorf("Expected positive side, got %v", side)
+	}
+}
+
+func TestInitializeTreesWithSourceReproducible(t *testing.T) {
+	a, sideA := InitializeTreesWithSource(8, nil, 4, rand.New(rand.NewSource(3)))
+	// Draws from the shared source in between must not affect a seeded run
+	rand.Float64()
+	b, sideB := InitializeTreesWithSource(8, nil, 4, rand.New(rand.NewSource(3)))
+	if sideA != sideB || len(a) != len(b) {
+		t.Fatalf("Same seed gave different layouts: side %.6f vs %.6f", sideA, sideB)
+	}
+	for i := range a {
+		if !a[i].Equal(&b[i]) {
+			t.Fatalf("Tree %d differs between runs: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
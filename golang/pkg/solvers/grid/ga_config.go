@@ -0,0 +1,49 @@
+package grid
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GAConfig holds the parameters of the grid Genetic Algorithm
+type GAConfig struct {
+	PopulationSize int     `yaml:"population_size"` // Individuals per generation
+	Generations    int     `yaml:"generations"`     // Number of generations
+	MutationRate   float64 `yaml:"mutation_rate"`   // Probability a child is mutated
+	CrossoverRate  float64 `yaml:"crossover_rate"`  // Probability a child is a crossover of two parents
+	TournamentSize int     `yaml:"tournament_size"` // Individuals compared per tournament selection
+}
+
+// DefaultGAConfig returns the default GA configuration
+func DefaultGAConfig() *GAConfig {
+	return &GAConfig{
+		PopulationSize: 20,
+		Generations:    50,
+		MutationRate:   0.3,
+		CrossoverRate:  0.7,
+		TournamentSize: 3,
+	}
+}
+
+// LoadGAConfig reads the GA configuration from the top-level "ga" key of the YAML config
+// file shared with SA (whose parameters live under "params"). Keys that are not set,
+// including a missing "ga" section, keep their DefaultGAConfig values.
+func LoadGAConfig(path string) (*GAConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	wrapper := struct {
+		GA *GAConfig `yaml:"ga"`
+	}{GA: DefaultGAConfig()}
+	if err := yaml.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if wrapper.GA.PopulationSize < 1 || wrapper.GA.TournamentSize < 1 {
+		return nil, fmt.Errorf("ga: population_size and tournament_size must be at least 1")
+	}
+	return wrapper.GA, nil
+}
@@ -0,0 +1,40 @@
+package grid
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadGAConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Only the keys present override the defaults
+	cfg, err := LoadGAConfig(write("ga.yaml", "params:\n  Tmax: 1\nga:\n  population_size: 8\n  generations: 5\n"))
+	if err != nil {
+		t.Fatalf("LoadGAConfig: %v", err)
+	}
+	want := *DefaultGAConfig()
+	want.PopulationSize = 8
+	want.Generations = 5
+	if *cfg != want {
+		t.Errorf("Loaded %+v, want %+v", *cfg, want)
+	}
+
+	// An SA-only config gives the defaults
+	cfg, err = LoadGAConfig(write("sa.yaml", "params:\n  Tmax: 1\n"))
+	if err != nil || *cfg != *DefaultGAConfig() {
+		t.Errorf("Config without a ga section loaded %+v (err %v), want defaults", cfg, err)
+	}
+
+	if _, err := LoadGAConfig(write("bad.yaml", "ga:\n  population_size: 0\n")); err == nil {
+		t.Errorf("Expected an error for an empty population")
+	}
+}
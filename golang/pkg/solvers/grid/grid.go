@@ -11,21 +11,11 @@ import (
 )
 
 // Config holds configuration for grid-based tree placement
-type Config struct {
-	HorizontalSpacing float64 // Spacing between trees in a row (default: 0.7)
-	EvenRowY          float64 // Y spacing for even rows (default: 1.0)
-	OddRowOffsetY     float64 // Y offset for odd rows (default: 0.8)
-	OddRowOffsetX     float64 // X offset for odd rows (default: 0.35, which is 0.7/2)
-}
+type Config = tree.GridConfig
 
 // DefaultConfig returns the default grid configuration
 func DefaultConfig() *Config {
-	return &Config{
-		HorizontalSpacing: 0.7,
-		EvenRowY:          1.0,
-		OddRowOffsetY:     0.8,
-		OddRowOffsetX:     0.35, // 0.7/2 for staggered placement
-	}
+	return tree.DefaultGridConfig()
 }
 
 // Solution represents a grid-based solution attempt
@@ -1,6 +1,7 @@
 package grid
 
 import (
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/rand"
@@ -11,70 +12,129 @@ import (
 	"github.com/tidwall/rtree"
 )
 
+// TreeOffset places one tree of a block relative to the block origin
+type TreeOffset struct {
+	X     float64 // Horizontal offset from the block origin
+	Y     float64 // Vertical offset from the block origin
+	Angle float64 // Rotation in degrees
+}
+
 // GridIndividual represents a candidate solution with simplified genome.
-// The genome consists of:
-//   - Angle (alpha): One tree is at alpha, the other at alpha+180 (trunks facing outward)
-//   - Dx: Horizontal offset between the two trees in a block
-//   - Dy: Vertical offset between the two trees in a block
+// The genome is the block template: one TreeOffset per tree of a block, which is
+// repeated over the grid. The default block is a pair (see PairBlock): one tree at
+// alpha, the other at alpha+180 (trunks facing outward), offset by (dx, dy).
 //
-// The number of rows and pairs per row are CALCULATED from the block dimensions
+// The number of rows and blocks per row are CALCULATED from the block dimensions
 // and target number of trees - they are NOT part of the genome.
 type GridIndividual struct {
-	Angle float64 // Base angle (alpha). Tree A: alpha, Tree B: alpha+180
-	Dx    float64 // Horizontal offset between trees in a pair
-	Dy    float64 // Vertical offset between trees in a pair
+	Block []TreeOffset // Block template, any number of trees
 
 	Score float64              // Cached score (SideLength)
 	Trees []tree.ChristmasTree // Generated trees
 }
 
-// Config for GA
+// PairBlock returns the classic 2-tree block: tree A at the origin with angle alpha and
+// tree B at (dx, dy) with angle alpha+180
+func PairBlock(alpha, dx, dy float64) []TreeOffset {
+	return []TreeOffset{
+		{X: 0, Y: 0, Angle: alpha},
+		{X: dx, Y: dy, Angle: alpha + 180.0},
+	}
+}
+
+// Warm-start settings of FindBestGridGASolutionFromSeed
 const (
-	PopulationSize = 20
-	Generations    = 50
-	MutationRate   = 0.3
-	CrossoverRate  = 0.7
-	TournamentSize = 3
+	seedIndividuals    = 4    // Individuals built from the seed's pair
+	seedAngleTolerance = 10.0 // Max deviation (degrees) from 180° between the seed's first two trees
 )
 
 // FindBestGridGASolution runs the Genetic Algorithm to optimize block parameters
-func FindBestGridGASolution(numTrees int) (float64, []tree.ChristmasTree) {
+// starting from 2-tree blocks (nil cfg = DefaultGAConfig)
+func FindBestGridGASolution(numTrees int, cfg *GAConfig) (float64, []tree.ChristmasTree) {
+	return FindBestGridGASolutionWithBlock(numTrees, nil, cfg)
+}
+
+// FindBestGridGASolutionWithBlock runs the Genetic Algorithm seeded with jittered copies
+// of the given block template (e.g. a 3- or 4-tree motif); a nil template uses the
+// 2-tree default
+func FindBestGridGASolutionWithBlock(numTrees int, template []TreeOffset, cfg *GAConfig) (float64, []tree.ChristmasTree) {
+	if cfg == nil {
+		cfg = DefaultGAConfig()
+	}
 	rand.Seed(time.Now().UnixNano())
-	fmt.Printf("Running Block-Based Grid GA Solver for N=%d...\n", numTrees)
+	return runGA(numTrees, initPopulation(template, cfg.PopulationSize), cfg)
+}
+
+// FindBestGridGASolutionFromSeed warm-starts the Genetic Algorithm from an existing
+// layout: the pair (Angle, Dx, Dy) read off the seed's first two trees replaces a few
+// individuals of the default population. A seed that is not such a pair is ignored.
+func FindBestGridGASolutionFromSeed(numTrees int, seed []tree.ChristmasTree, cfg *GAConfig) (float64, []tree.ChristmasTree) {
+	if cfg == nil {
+		cfg = DefaultGAConfig()
+	}
+	rand.Seed(time.Now().UnixNano())
+	pop := initPopulation(nil, cfg.PopulationSize)
+	if block, ok := pairFromSeed(seed); ok {
+		copy(pop, initPopulation(block, min(seedIndividuals, len(pop))))
+	} else {
+		fmt.Printf("Seed for N=%d is not a 2-tree block; using the default population\n", numTrees)
+	}
+	return runGA(numTrees, pop, cfg)
+}
+
+// pairFromSeed reverse-engineers a 2-tree block from the first two trees of a layout:
+// tree A gives the angle and tree B, rotated about 180° from A, gives the offset
+func pairFromSeed(seed []tree.ChristmasTree) ([]TreeOffset, bool) {
+	if len(seed) < 2 {
+		return nil, false
+	}
+	a, b := seed[0], seed[1]
+	if math.Abs(tree.NormalizeAngle(b.Angle-a.Angle)-180) > seedAngleTolerance {
+		return nil, false
+	}
+	return PairBlock(tree.NormalizeAngle(a.Angle), b.X-a.X, b.Y-a.Y), true
+}
 
-	// Initialize Population
-	pop := initPopulation()
+// runGA evolves the population and returns the best score and trees found
+func runGA(numTrees int, pop []GridIndividual, cfg *GAConfig) (float64, []tree.ChristmasTree) {
+	return evolve(numTrees, pop, cfg, newSpacingCache())
+}
+
+// evolve is runGA with the block spacing cache of this run; every individual is evaluated
+// through it, so unchanged blocks (elites, uncrossed and unmutated children) are looked up
+func evolve(numTrees int, pop []GridIndividual, cfg *GAConfig, spacing *spacingCache) (float64, []tree.ChristmasTree) {
+	fmt.Printf("Running Block-Based Grid GA Solver for N=%d...\n", numTrees)
 
 	var bestInd GridIndividual
 	bestInd.Score = math.MaxFloat64
 
-	for gen := 0; gen < Generations; gen++ {
+	for gen := 0; gen < cfg.Generations; gen++ {
 		// Evaluate fitness
 		for i := range pop {
-			evaluate(&pop[i], numTrees)
+			evaluate(&pop[i], numTrees, spacing)
 			if pop[i].Score < bestInd.Score {
 				bestInd = pop[i]
-				fmt.Printf("Gen %d: New Best Score=%.5f (Angle=%.1f°, Dx=%.3f, Dy=%.3f)\n",
-					gen, bestInd.Score, bestInd.Angle, bestInd.Dx, bestInd.Dy)
+				fmt.Printf("Gen %d: New Best Score=%.5f (Block: %s)\n",
+					gen, bestInd.Score, formatBlock(bestInd.Block))
 			}
 		}
 
 		// Selection & Evolution
-		newPop := make([]GridIndividual, 0, PopulationSize)
+		newPop := make([]GridIndividual, 0, cfg.PopulationSize)
 
 		// Elitism - keep the best
 		newPop = append(newPop, bestInd)
 
-		for len(newPop) < PopulationSize {
-			p1 := tournamentSelection(pop)
-			p2 := tournamentSelection(pop)
+		for len(newPop) < cfg.PopulationSize {
+			p1 := tournamentSelection(pop, cfg.TournamentSize)
+			p2 := tournamentSelection(pop, cfg.TournamentSize)
 
 			child := p1 // Default clone
-			if rand.Float64() < CrossoverRate {
+			if rand.Float64() < cfg.CrossoverRate {
 				child = crossover(p1, p2)
 			}
 
-			if rand.Float64() < MutationRate {
+			if rand.Float64() < cfg.MutationRate {
 				mutate(&child)
 			}
 			newPop = append(newPop, child)
@@ -85,93 +145,192 @@ func FindBestGridGASolution(numTrees int) (float64, []tree.ChristmasTree) {
 	return bestInd.Score, bestInd.Trees
 }
 
-// FIXME: unused n param?
-func initPopulation() []GridIndividual {
-	pop := make([]GridIndividual, PopulationSize)
+// initPopulation creates an initial population of size individuals: jittered copies of
+// template, or heuristic 2-tree blocks when template is nil
+func initPopulation(template []TreeOffset, size int) []GridIndividual {
+	pop := make([]GridIndividual, size)
 	for i := range pop {
+		if template != nil {
+			// Keep one exact copy of the template; jitter the rest
+			block := cloneBlock(template)
+			if i > 0 {
+				for k := range block {
+					if k > 0 {
+						block[k].X += (rand.Float64() - 0.5) * 0.4
+						block[k].Y += (rand.Float64() - 0.5) * 0.4
+					}
+					block[k].Angle = tree.NormalizeAngle(block[k].Angle + (rand.Float64()-0.5)*40.0)
+				}
+			}
+			pop[i] = GridIndividual{Block: block}
+			continue
+		}
+
 		// Heuristic initialization for angles and offsets
 		// Start from a known good configuration and add variance
-		pop[i] = GridIndividual{
-			Angle: 60.0 + (rand.Float64()-0.5)*40.0, // 40-80 degrees
-			Dx:    -0.6 + (rand.Float64()-0.5)*0.4,  // -0.8 to -0.4
-			Dy:    -0.1 + (rand.Float64()-0.5)*0.4,  // -0.3 to 0.1
-		}
+		pop[i] = GridIndividual{Block: PairBlock(
+			60.0+(rand.Float64()-0.5)*40.0, // 40-80 degrees
+			-0.6+(rand.Float64()-0.5)*0.4,  // -0.8 to -0.4
+			-0.1+(rand.Float64()-0.5)*0.4,  // -0.3 to 0.1
+		)}
 	}
 	return pop
 }
 
-// checkPairCollision checks if two trees in a pair intersect
-func checkPairCollision(angle, dx, dy float64) bool {
-	tA := tree.ChristmasTree{X: 0, Y: 0, Angle: angle}
-	tB := tree.ChristmasTree{X: dx, Y: dy, Angle: angle + 180.0}
-	return tA.Intersect(&tB)
+// cloneBlock copies a block template so individuals never share offsets
+func cloneBlock(block []TreeOffset) []TreeOffset {
+	return append([]TreeOffset(nil), block...)
+}
+
+// formatBlock renders a block template for progress output
+func formatBlock(block []TreeOffset) string {
+	s := ""
+	for k, o := range block {
+		if k > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("(%.3f, %.3f, %.1f°)", o.X, o.Y, o.Angle)
+	}
+	return s
+}
+
+// blockTrees places the trees of a block template with its origin at (baseX, baseY)
+func blockTrees(block []TreeOffset, baseX, baseY float64) []tree.ChristmasTree {
+	trees := make([]tree.ChristmasTree, len(block))
+	for k, o := range block {
+		trees[k] = tree.ChristmasTree{ID: k, X: baseX + o.X, Y: baseY + o.Y, Angle: o.Angle}
+	}
+	return trees
+}
+
+// checkBlockCollision checks if any two trees within a block intersect
+func checkBlockCollision(block []TreeOffset) bool {
+	return tree.HasCollision(blockTrees(block, 0, 0))
+}
+
+// spacingQuantum is the resolution of spacingCache keys. It only absorbs floating-point
+// noise: a cached spacing is returned for the exact block it was found for, so it has to
+// be collision-free for that block.
+const spacingQuantum = 1e-9
+
+// spacingCache memoizes findValidBlockSpacing for one GA run, keyed on the quantized
+// (angle, dx, dy) of every tree of the block. It is not safe for concurrent use; each
+// FindBestGridGASolution call has its own, so parallel runs over n never share one. The
+// zero value only counts checks, without memoizing.
+type spacingCache struct {
+	entries map[string]spacingResult
+	checks  int // checkBlockCollision calls made on misses
 }
 
-// findValidPairSpacing adjusts dx, dy to avoid collision within a pair
-// Returns the adjusted dx, dy and whether a valid configuration was found
-func findValidPairSpacing(angle, dx, dy float64) (float64, float64, bool) {
+// spacingResult is a cached findValidBlockSpacing outcome
+type spacingResult struct {
+	block []TreeOffset
+	found bool
+}
+
+// newSpacingCache returns an empty cache
+func newSpacingCache() *spacingCache {
+	return &spacingCache{entries: make(map[string]spacingResult)}
+}
+
+// find returns findValidBlockSpacing(block), computing it only for blocks not seen before
+func (c *spacingCache) find(block []TreeOffset) ([]TreeOffset, bool) {
+	key := spacingKey(block)
+	if r, ok := c.entries[key]; ok {
+		return cloneBlock(r.block), r.found
+	}
+	adjusted, found := findValidBlockSpacing(block, func(b []TreeOffset) bool {
+		c.checks++
+		return checkBlockCollision(b)
+	})
+	if c.entries != nil {
+		c.entries[key] = spacingResult{block: cloneBlock(adjusted), found: found}
+	}
+	return adjusted, found
+}
+
+// spacingKey encodes the block's offsets rounded to spacingQuantum
+func spacingKey(block []TreeOffset) string {
+	key := make([]byte, 0, len(block)*24)
+	for _, o := range block {
+		for _, v := range [3]float64{tree.NormalizeAngle(o.Angle), o.X, o.Y} {
+			key = binary.LittleEndian.AppendUint64(key, uint64(int64(math.Round(v/spacingQuantum))))
+		}
+	}
+	return string(key)
+}
+
+// findValidBlockSpacing adjusts the block offsets to avoid collisions within a block by
+// scaling them away from the origin and nudging every tree but the first, testing each
+// candidate with collides (checkBlockCollision, possibly instrumented).
+// Returns the adjusted block and whether a valid configuration was found
+func findValidBlockSpacing(block []TreeOffset, collides func([]TreeOffset) bool) ([]TreeOffset, bool) {
 	// First check if current position is valid
-	if !checkPairCollision(angle, dx, dy) {
-		return dx, dy, true
+	if !collides(block) {
+		return block, true
+	}
+
+	test := cloneBlock(block)
+	place := func(scale, pdx, pdy float64) {
+		for k := 1; k < len(block); k++ {
+			test[k].X = block[k].X*scale + pdx
+			test[k].Y = block[k].Y*scale + pdy
+		}
 	}
 
 	// Try to find a valid position by expanding outward
 	step := 0.05
 	for scale := 1.0; scale <= 3.0; scale += 0.1 {
 		// Try expanding in the current direction
-		testDx := dx * scale
-		testDy := dy * scale
-		if !checkPairCollision(angle, testDx, testDy) {
-			return testDx, testDy, true
+		place(scale, 0, 0)
+		if !collides(test) {
+			return test, true
 		}
 
 		// Try with small perturbations
 		for _, pdx := range []float64{-step, 0, step} {
 			for _, pdy := range []float64{-step, 0, step} {
-				testDx := dx*scale + pdx
-				testDy := dy*scale + pdy
-				if !checkPairCollision(angle, testDx, testDy) {
-					return testDx, testDy, true
+				place(scale, pdx, pdy)
+				if !collides(test) {
+					return test, true
 				}
 			}
 		}
 	}
 
-	return dx, dy, false
+	return block, false
 }
 
-// evaluate builds the solution from the genome and calculates the score
-func evaluate(ind *GridIndividual, targetN int) {
-	// First, ensure the pair configuration is valid (no intra-pair collision)
-	validDx, validDy, found := findValidPairSpacing(ind.Angle, ind.Dx, ind.Dy)
-	if !found {
-		// Invalid pair configuration - heavily penalize
+// evaluate builds the solution from the genome and calculates the score, looking up the
+// block spacing in the run's cache
+func evaluate(ind *GridIndividual, targetN int, spacing *spacingCache) {
+	// First, ensure the block configuration is valid (no intra-block collision)
+	block, found := spacing.find(ind.Block)
+	if !found || len(block) == 0 {
+		// Invalid block configuration - heavily penalize
 		ind.Score = 10000.0
 		ind.Trees = nil
 		return
 	}
 
-	// Use validated spacing
-	dx, dy := validDx, validDy
-
-	// Calculate the bounding box of a single 2-tree block
-	blockWidth, blockHeight := calculateBlockDimensions(ind.Angle, dx, dy)
+	// Calculate the bounding box of a single block
+	blockWidth, blockHeight := calculateBlockDimensions(block)
 
 	// Add some spacing between blocks to prevent inter-block collisions
 	blockSpacingX := blockWidth * 1.05 // 5% extra spacing
 	blockSpacingY := blockHeight * 1.05
 
 	// Calculate grid layout based on target number of trees and block size
-	numBlocks := (targetN + 1) / 2 // Each block contains 2 trees
+	numBlocks := (targetN + len(block) - 1) / len(block)
 
 	// Find optimal number of blocks per row to minimize overall bounding box
 	blocksPerRow, numRows := calculateOptimalLayout(numBlocks, blockSpacingX, blockSpacingY)
 
 	// Generate all tree positions with collision checking
-	trees := generateTreesWithCollisionCheck(ind.Angle, dx, dy, blocksPerRow, numRows, blockSpacingX, blockSpacingY, targetN)
+	trees := generateTreesWithCollisionCheck(block, blocksPerRow, numRows, blockSpacingX, blockSpacingY, targetN)
 
 	// Compact the trees: slide left and up as much as possible
-	trees = compactTrees(trees, blocksPerRow)
+	trees = compactTrees(trees, blocksPerRow, len(block))
 
 	ind.Trees = trees
 
@@ -208,8 +367,8 @@ func countCollisions(trees []tree.ChristmasTree) int {
 // then all trees in blocks N and beyond can move X together.
 // Similarly for rows: if the first block of row N can move Y, all rows N+ can move Y.
 // Uses R-tree for efficient collision detection.
-func compactTrees(trees []tree.ChristmasTree, blocksPerRow int) []tree.ChristmasTree {
-	if len(trees) <= 2 || blocksPerRow <= 0 {
+func compactTrees(trees []tree.ChristmasTree, blocksPerRow, blockSize int) []tree.ChristmasTree {
+	if len(trees) <= blockSize || blocksPerRow <= 0 {
 		return trees
 	}
 
@@ -220,7 +379,7 @@ func compactTrees(trees []tree.ChristmasTree, blocksPerRow int) []tree.Christmas
 	result := make([]tree.ChristmasTree, len(trees))
 	copy(result, trees)
 
-	treesPerRow := blocksPerRow * 2
+	treesPerRow := blocksPerRow * blockSize
 
 	// Build R-tree for collision detection
 	buildRTree := func(trees []tree.ChristmasTree) rtree.RTree {
@@ -252,22 +411,19 @@ func compactTrees(trees []tree.ChristmasTree, blocksPerRow int) []tree.Christmas
 		return collision
 	}
 
-	// Compact columns (blocks) left - starting from block 1 (trees 2,3)
+	// Compact columns (blocks) left - starting from block 1
 	for block := 1; block < blocksPerRow; block++ {
 		// Get indices of all trees from this block onwards in all rows
 		affectedIndices := []int{}
 		affectedSet := make(map[int]bool)
 		for row := 0; row*treesPerRow < len(result); row++ {
 			for b := block; b < blocksPerRow; b++ {
-				treeIdxA := row*treesPerRow + b*2
-				treeIdxB := row*treesPerRow + b*2 + 1
-				if treeIdxA < len(result) {
-					affectedIndices = append(affectedIndices, treeIdxA)
-					affectedSet[treeIdxA] = true
-				}
-				if treeIdxB < len(result) {
-					affectedIndices = append(affectedIndices, treeIdxB)
-					affectedSet[treeIdxB] = true
+				for k := 0; k < blockSize; k++ {
+					treeIdx := row*treesPerRow + b*blockSize + k
+					if treeIdx < len(result) {
+						affectedIndices = append(affectedIndices, treeIdx)
+						affectedSet[treeIdx] = true
+					}
 				}
 			}
 		}
@@ -355,22 +511,10 @@ func compactTrees(trees []tree.ChristmasTree, blocksPerRow int) []tree.Christmas
 	return result
 }
 
-// calculateBlockDimensions returns the width and height of a single 2-tree block
-func calculateBlockDimensions(angle, dx, dy float64) (float64, float64) {
-	// Create the two trees of a block at origin
-	tA := tree.ChristmasTree{X: 0, Y: 0, Angle: angle}
-	tB := tree.ChristmasTree{X: dx, Y: dy, Angle: angle + 180.0}
-
-	// Get bounding boxes
-	minXA, minYA, maxXA, maxYA := tA.GetBoundingBox()
-	minXB, minYB, maxXB, maxYB := tB.GetBoundingBox()
-
-	// Combined bounding box
-	minX := math.Min(minXA, minXB)
-	minY := math.Min(minYA, minYB)
-	maxX := math.Max(maxXA, maxXB)
-	maxY := math.Max(maxYA, maxYB)
-
+// calculateBlockDimensions returns the width and height of a single block
+func calculateBlockDimensions(block []TreeOffset) (float64, float64) {
+	// Combined bounding box of the block's trees placed at the origin
+	minX, minY, maxX, maxY := tree.GetBounds(blockTrees(block, 0, 0))
 	return maxX - minX, maxY - minY
 }
 
@@ -405,7 +549,7 @@ func calculateOptimalLayout(numBlocks int, blockWidth, blockHeight float64) (int
 }
 
 // generateTreesWithCollisionCheck creates trees and verifies no collisions using R-tree
-func generateTreesWithCollisionCheck(angle, dx, dy float64, blocksPerRow, numRows int, blockWidth, blockHeight float64, targetN int) []tree.ChristmasTree {
+func generateTreesWithCollisionCheck(block []TreeOffset, blocksPerRow, numRows int, blockWidth, blockHeight float64, targetN int) []tree.ChristmasTree {
 	trees := make([]tree.ChristmasTree, 0, targetN)
 	tr := rtree.RTree{} // R-tree for fast collision detection
 	cnt := 0
@@ -416,42 +560,20 @@ func generateTreesWithCollisionCheck(angle, dx, dy float64, blocksPerRow, numRow
 		for col := 0; col < blocksPerRow && cnt < targetN; col++ {
 			baseX := float64(col) * blockWidth
 
-			// Tree A: angle = alpha
-			tA := tree.ChristmasTree{
-				ID:    cnt,
-				X:     baseX,
-				Y:     baseY,
-				Angle: angle,
-			}
-
-			// Check collision with existing trees before adding
-			if !checkTreeCollisionRTree(tA, trees, &tr) {
-				trees = append(trees, tA)
-				// Add to R-tree
-				minX, minY, maxX, maxY := tA.GetBoundingBox()
-				tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, len(trees)-1)
-				cnt++
-			}
-
-			if cnt >= targetN {
-				break
-			}
-
-			// Tree B: angle = alpha + 180 (rotated 180° from Tree A)
-			tB := tree.ChristmasTree{
-				ID:    cnt,
-				X:     baseX + dx,
-				Y:     baseY + dy,
-				Angle: angle + 180.0,
-			}
-
-			// Check collision with existing trees before adding
-			if !checkTreeCollisionRTree(tB, trees, &tr) {
-				trees = append(trees, tB)
-				// Add to R-tree
-				minX, minY, maxX, maxY := tB.GetBoundingBox()
-				tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, len(trees)-1)
-				cnt++
+			for _, t := range blockTrees(block, baseX, baseY) {
+				if cnt >= targetN {
+					break
+				}
+				t.ID = cnt
+
+				// Check collision with existing trees before adding
+				if !checkTreeCollisionRTree(t, trees, &tr) {
+					trees = append(trees, t)
+					// Add to R-tree
+					minX, minY, maxX, maxY := t.GetBoundingBox()
+					tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, len(trees)-1)
+					cnt++
+				}
 			}
 		}
 	}
@@ -479,9 +601,9 @@ func checkTreeCollisionRTree(t tree.ChristmasTree, existing []tree.ChristmasTree
 	return collision
 }
 
-func tournamentSelection(pop []GridIndividual) GridIndividual {
+func tournamentSelection(pop []GridIndividual, size int) GridIndividual {
 	best := pop[rand.Intn(len(pop))]
-	for i := 0; i < TournamentSize-1; i++ {
+	for i := 0; i < size-1; i++ {
 		challenger := pop[rand.Intn(len(pop))]
 		if challenger.Score < best.Score {
 			best = challenger
@@ -491,31 +613,44 @@ func tournamentSelection(pop []GridIndividual) GridIndividual {
 }
 
 func crossover(p1, p2 GridIndividual) GridIndividual {
-	// Arithmetic crossover for all parameters
-	alpha := rand.Float64()
+	// Blocks of different sizes do not mix
+	if len(p1.Block) != len(p2.Block) {
+		return GridIndividual{Block: cloneBlock(p1.Block)}
+	}
 
-	return GridIndividual{
-		Angle: p1.Angle*alpha + p2.Angle*(1-alpha),
-		Dx:    p1.Dx*alpha + p2.Dx*(1-alpha),
-		Dy:    p1.Dy*alpha + p2.Dy*(1-alpha),
+	// Arithmetic crossover per offset
+	block := make([]TreeOffset, len(p1.Block))
+	for k := range block {
+		alpha := rand.Float64()
+		a, b := p1.Block[k], p2.Block[k]
+		block[k] = TreeOffset{
+			X:     a.X*alpha + b.X*(1-alpha),
+			Y:     a.Y*alpha + b.Y*(1-alpha),
+			Angle: a.Angle*alpha + b.Angle*(1-alpha),
+		}
 	}
+	return GridIndividual{Block: block}
 }
 
 func mutate(ind *GridIndividual) {
-	// Mutate each gene with some probability
-	if rand.Float64() < 0.5 {
-		ind.Angle += rand.NormFloat64() * 10.0
-		// Keep angle in reasonable range [0, 360)
-		if ind.Angle < 0 {
-			ind.Angle += 360.0
-		} else if ind.Angle >= 360.0 {
-			ind.Angle -= 360.0
+	// The block may be shared with its parent
+	ind.Block = cloneBlock(ind.Block)
+
+	// Mutate each gene of each offset with some probability; the first tree stays at the
+	// block origin since moving it only shifts the whole block
+	for k := range ind.Block {
+		o := &ind.Block[k]
+		if rand.Float64() < 0.5 {
+			o.Angle = tree.NormalizeAngle(o.Angle + rand.NormFloat64()*10.0)
+		}
+		if k == 0 {
+			continue
+		}
+		if rand.Float64() < 0.5 {
+			o.X += rand.NormFloat64() * 0.2
+		}
+		if rand.Float64() < 0.5 {
+			o.Y += rand.NormFloat64() * 0.2
 		}
-	}
-	if rand.Float64() < 0.5 {
-		ind.Dx += rand.NormFloat64() * 0.2
-	}
-	if rand.Float64() < 0.5 {
-		ind.Dy += rand.NormFloat64() * 0.2
 	}
 }
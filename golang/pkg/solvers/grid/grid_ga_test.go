@@ -0,0 +1,97 @@
+package grid
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestPairFromSeed(t *testing.T) {
+	seed := []tree.ChristmasTree{
+		{X: 1.0, Y: 2.0, Angle: -60},
+		{X: 0.4, Y: 1.9, Angle: 120},
+		{X: 5, Y: 5, Angle: 0},
+	}
+	block, ok := pairFromSeed(seed)
+	if !ok {
+		t.Fatalf("Expected the seed to be interpreted as a pair")
+	}
+	want := PairBlock(300, -0.6, -0.1)
+	for k := range want {
+		if math.Abs(block[k].X-want[k].X) > 1e-9 || math.Abs(block[k].Y-want[k].Y) > 1e-9 ||
+			math.Abs(block[k].Angle-want[k].Angle) > 1e-9 {
+			t.Errorf("Offset %d = %+v, want %+v", k, block[k], want[k])
+		}
+	}
+
+	for _, bad := range [][]tree.ChristmasTree{
+		nil,
+		{{Angle: 30}},
+		{{Angle: 30}, {X: 0.7, Angle: 30}}, // Same orientation, not a pair
+	} {
+		if _, ok := pairFromSeed(bad); ok {
+			t.Errorf("Seed %+v should not be interpreted", bad)
+		}
+	}
+}
+
+func TestSpacingCache(t *testing.T) {
+	// Trees on top of each other: the spacing search has to push them apart
+	block := PairBlock(60, 0.1, 0)
+	want, wantFound := findValidBlockSpacing(block, checkBlockCollision)
+	if !wantFound {
+		t.Fatalf("Test block has no valid spacing")
+	}
+
+	cache := newSpacingCache()
+	got, found := cache.find(block)
+	checks := cache.checks
+	if found != wantFound || !slices.Equal(got, want) || checks == 0 {
+		t.Fatalf("Cache miss returned %+v (%v) after %d checks, want %+v", got, found, checks, want)
+	}
+
+	// Repeated lookups, also of a copy within the quantum, are answered from the cache
+	nudged := cloneBlock(block)
+	nudged[1].X += spacingQuantum / 10
+	for _, b := range [][]TreeOffset{block, nudged} {
+		got, found = cache.find(b)
+		if found != wantFound || !slices.Equal(got, want) {
+			t.Errorf("Cache hit returned %+v (%v), want %+v", got, found, want)
+		}
+	}
+	if cache.checks != checks {
+		t.Errorf("Cache hits ran %d more collision checks", cache.checks-checks)
+	}
+
+	// Callers own the returned block
+	got[1].X = 99
+	if again, _ := cache.find(block); again[1].X == 99 {
+		t.Errorf("Modifying a returned block changed the cached entry")
+	}
+}
+
+func BenchmarkGridGASpacing(b *testing.B) {
+	cfg := DefaultGAConfig()
+	cfg.Generations = 50
+	for _, mode := range []struct {
+		name  string
+		cache func() *spacingCache
+	}{
+		{"uncached", func() *spacingCache { return &spacingCache{} }},
+		{"cached", newSpacingCache},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			checks := 0
+			for b.Loop() {
+				rand.Seed(1)
+				spacing := mode.cache()
+				evolve(8, initPopulation(nil, cfg.PopulationSize), cfg, spacing)
+				checks += spacing.checks
+			}
+			b.ReportMetric(float64(checks)/float64(b.N), "checks/op")
+		})
+	}
+}
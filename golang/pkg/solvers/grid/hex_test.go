@@ -0,0 +1,22 @@
+package grid
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestHexBeatsGrid(t *testing.T) {
+	// The hexagonal lattice nests its rows deeper than the staggered grid
+	_, gridScore := InitializeTrees(7, nil)
+	hex, hexScore := tree.InitializeTreesHex(7, DefaultConfig())
+	if len(hex) != 7 || tree.HasCollision(hex) {
+		t.Fatalf("Hex placement returned %d trees (collision: %v)", len(hex), tree.HasCollision(hex))
+	}
+	if hexScore >= gridScore {
+		t.Errorf("Hex score %.5f at n=7 is not below the grid's %.5f", hexScore, gridScore)
+	}
+	if got := calculateGridScore(hex); got != hexScore {
+		t.Errorf("Returned score %.5f does not match the layout's %.5f", hexScore, got)
+	}
+}
@@ -0,0 +1,50 @@
+// Package solvers_test runs every solver end to end on small instances to guard the
+// whole pipeline against regressions.
+package solvers_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+	"tree-packing-challenge/pkg/solvers/grid"
+	"tree-packing-challenge/pkg/solvers/sa"
+	"tree-packing-challenge/pkg/tree"
+)
+
+func checkLayout(t *testing.T, algo string, n int, trees []tree.ChristmasTree) {
+	t.Helper()
+	if len(trees) != n {
+		t.Errorf("%s n=%d: expected %d trees, got %d", algo, n, n, len(trees))
+	}
+	if tree.HasCollision(trees) {
+		t.Errorf("%s n=%d: output has collisions", algo, n)
+	}
+}
+
+func TestSolversEndToEnd(t *testing.T) {
+	conf := sa.DefaultConfig()
+	conf.RandomSeed = 42
+	conf.NSteps = 10
+	conf.NStepsPerT = 20
+	conf.LogFreq = 1000000
+
+	for n := 1; n <= 10; n++ {
+		// Seed greedy too, so the whole pipeline is reproducible (grid is deterministic)
+		greedyTrees, _ := greedy.InitializeTreesWithSource(n, nil, 0, rand.New(rand.NewSource(42)))
+		checkLayout(t, "greedy", n, greedyTrees)
+
+		_, gridTrees := grid.FindBestSolution(n)
+		checkLayout(t, "grid", n, gridTrees)
+
+		initScore := tree.CalculateScore(greedyTrees)
+		score, saTrees := sa.NewSimulatedAnnealing(greedyTrees, conf).Solve()
+		checkLayout(t, "sa", n, saTrees)
+		if score > initScore+1e-12 {
+			t.Errorf("sa n=%d: score %.6f worse than initialization %.6f", n, score, initScore)
+		}
+		if got := tree.CalculateScore(saTrees); got > score+1e-12 {
+			t.Errorf("sa n=%d: reported score %.6f does not match trees (%.6f)", n, score, got)
+		}
+	}
+}
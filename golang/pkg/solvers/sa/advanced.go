@@ -1,8 +1,8 @@
 package sa
 
 import (
+	"context"
 	"math"
-	"math/rand"
 
 	"tree-packing-challenge/pkg/tree"
 )
@@ -33,13 +33,35 @@ func Squeeze(trees []tree.ChristmasTree) []tree.ChristmasTree {
 
 // Compaction attempts to move trees towards the center to reduce bounds
 func Compaction(trees []tree.ChristmasTree, iters int) []tree.ChristmasTree {
+	return settleTowards(trees, iters, func(c []tree.ChristmasTree) (float64, float64) {
+		gx0, gy0, gx1, gy1 := tree.GetBounds(c)
+		return (gx0 + gx1) / 2.0, (gy0 + gy1) / 2.0
+	})
+}
+
+// SettleToCentroid is an alternative to Compaction that moves trees towards the mean of
+// all tree positions instead of the bounding-box center, which can pack denser when the
+// layout is lopsided. Like Compaction it only accepts collision-free moves that reduce Side.
+func SettleToCentroid(trees []tree.ChristmasTree, iters int) []tree.ChristmasTree {
+	return settleTowards(trees, iters, func(c []tree.ChristmasTree) (float64, float64) {
+		cx, cy := 0.0, 0.0
+		for i := range c {
+			cx += c[i].X
+			cy += c[i].Y
+		}
+		return cx / float64(len(c)), cy / float64(len(c))
+	})
+}
+
+// settleTowards is the loop of Compaction and SettleToCentroid: every iteration each tree
+// steps towards the point target returns for the current layout, keeping only
+// collision-free moves that reduce Side, until an iteration improves nothing
+func settleTowards(trees []tree.ChristmasTree, iters int, target func(c []tree.ChristmasTree) (float64, float64)) []tree.ChristmasTree {
 	c := CloneTrees(trees)
 	bs := tree.Side(c)
 
 	for it := 0; it < iters; it++ {
-		gx0, gy0, gx1, gy1 := tree.GetBounds(c)
-		cx := (gx0 + gx1) / 2.0
-		cy := (gy0 + gy1) / 2.0
+		cx, cy := target(c)
 		improved := false
 
 		for i := range c {
@@ -177,8 +199,231 @@ func LocalSearch(trees []tree.ChristmasTree, maxIter int) []tree.ChristmasTree {
 	return c
 }
 
-// PerturbAdvanced perturbs the configuration based on strength
-func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng *rand.Rand) []tree.ChristmasTree {
+// TwoOptExchange moves tree i to tree j's position and vice versa, re-optimizing each
+// tree's angle at its new position (unlike SwapTrees, which carries the angles along).
+// The exchange is kept only if both trees end up collision-free and the side shrinks;
+// otherwise the trees are restored. Note: this modifies the input slice directly.
+func TwoOptExchange(trees []tree.ChristmasTree, i, j int) bool {
+	if i == j || i < 0 || j < 0 || i >= len(trees) || j >= len(trees) {
+		return false
+	}
+
+	oldI, oldJ := trees[i], trees[j]
+	oldSide := tree.Side(trees)
+
+	trees[i].X, trees[i].Y = oldJ.X, oldJ.Y
+	trees[j].X, trees[j].Y = oldI.X, oldI.Y
+
+	// Tree i ignores tree j while choosing its angle, since j is re-oriented next
+	okI := bestExchangeAngle(trees, i, j, oldI.Angle, oldJ.Angle)
+	okJ := okI && bestExchangeAngle(trees, j, -1, oldJ.Angle, oldI.Angle)
+
+	if !okJ || tree.Side(trees) >= oldSide-1e-12 {
+		trees[i], trees[j] = oldI, oldJ
+		return false
+	}
+	return true
+}
+
+// bestExchangeAngle sets tree i to the collision-free candidate angle giving the smallest side.
+// Candidates are the tree's own angle, its partner's angle and 15 degree steps around both.
+// Collisions with tree ignore are not checked (pass -1 to check all trees).
+func bestExchangeAngle(trees []tree.ChristmasTree, i, ignore int, own, partner float64) bool {
+	bestAngle, bestSide := 0.0, math.MaxFloat64
+	for k := 0; k < 24; k++ {
+		for _, base := range []float64{own, partner} {
+			trees[i].Angle = math.Mod(base+float64(k)*15.0, 360)
+			if collidesExcept(trees, i, ignore) {
+				continue
+			}
+			if side := tree.Side(trees); side < bestSide {
+				bestSide, bestAngle = side, trees[i].Angle
+			}
+		}
+	}
+	trees[i].Angle = bestAngle
+	return bestSide < math.MaxFloat64
+}
+
+// FineRotate turns the tree in place by a small Gaussian angle (1 degree std),
+// fine-tuning its orientation without the temperature-scaled jumps of move 2
+func FineRotate(t *tree.ChristmasTree, rng RNG) {
+	t.Angle = math.Mod(t.Angle+rng.NormFloat64()*1.0+360, 360)
+}
+
+// SlideToContact moves tree i straight toward its nearest neighbor (by SeparationDistance)
+// by a random 50-99% of the gap between them. Since the move is shorter than the gap it can
+// never overlap that neighbor; other trees are checked. Returns false (tree unchanged) if
+// there is no neighbor within reach or the slide would collide.
+func SlideToContact(trees []tree.ChristmasTree, i int, rng RNG) bool {
+	const reach = 3.0 // Neighbors with centers farther than this are not considered
+
+	nearest, gap := -1, math.MaxFloat64
+	for j := range trees {
+		if j == i || math.Hypot(trees[j].X-trees[i].X, trees[j].Y-trees[i].Y) > reach {
+			continue
+		}
+		if d := tree.SeparationDistance(&trees[i], &trees[j]); d < gap {
+			nearest, gap = j, d
+		}
+	}
+	if nearest < 0 || gap < 1e-9 {
+		return false
+	}
+
+	dx := trees[nearest].X - trees[i].X
+	dy := trees[nearest].Y - trees[i].Y
+	d := math.Hypot(dx, dy)
+	if d < 1e-9 {
+		return false
+	}
+
+	ox, oy := trees[i].X, trees[i].Y
+	step := gap * (0.5 + 0.49*rng.Float64())
+	trees[i].X += dx / d * step
+	trees[i].Y += dy / d * step
+	if tree.HasOvl(trees, i) {
+		trees[i].X, trees[i].Y = ox, oy
+		return false
+	}
+	return true
+}
+
+// Replant pulls the boundary tree farthest from the mean tree position out with RemoveTree
+// and re-inserts it by a small spiral search around the largest empty gap
+// (LargestEmptyCircle) of the remaining trees: points on an Archimedean spiral out from
+// the gap center are tried in four orientations until one is collision-free. A single tree
+// stuck on the edge can block every other move from shrinking the box; this relocates it
+// in one step. Returns the new layout (the replanted tree last) and false if no free spot
+// was found within the remaining trees' side, in which case trees is returned unchanged.
+func Replant(trees []tree.ChristmasTree, rng RNG) ([]tree.ChristmasTree, bool) {
+	if len(trees) < 2 {
+		return trees, false
+	}
+	cx, cy := 0.0, 0.0
+	for i := range trees {
+		cx += trees[i].X / float64(len(trees))
+		cy += trees[i].Y / float64(len(trees))
+	}
+	outlier, farthest := -1, -1.0
+	for _, i := range tree.GetBoundary(trees) {
+		if d := math.Hypot(trees[i].X-cx, trees[i].Y-cy); d > farthest {
+			outlier, farthest = i, d
+		}
+	}
+	if outlier < 0 {
+		return trees, false
+	}
+
+	rest := tree.RemoveTree(trees, outlier)
+	gapX, gapY, _ := tree.LargestEmptyCircle(rest)
+	maxR := tree.Side(rest)
+	candidate := append(rest, trees[outlier])
+	t := &candidate[len(candidate)-1]
+	baseAngle := trees[outlier].Angle + 90*float64(rng.Intn(4))
+
+	// r = pitch * theta / 2pi, stepping theta so consecutive points are about step apart
+	const pitch, step = 0.05, 0.02
+	for theta := 0.0; ; {
+		r := pitch * theta / (2 * math.Pi)
+		if r > maxR {
+			return trees, false
+		}
+		t.X = gapX + r*math.Cos(theta)
+		t.Y = gapY + r*math.Sin(theta)
+		for k := 0; k < 4; k++ {
+			t.Angle = math.Mod(baseAngle+90*float64(k), 360)
+			if !tree.HasOvl(candidate, len(candidate)-1) {
+				return candidate, true
+			}
+		}
+		theta += step / math.Max(r, step)
+	}
+}
+
+// boundaryEpsFraction is the boundary tolerance of the boundary move as a fraction of the side
+const boundaryEpsFraction = 0.005
+
+// activeMoveTypes returns how many move types may be drawn at temperature T: the
+// interior jitter is a late-phase move, enabled only below Config.InteriorJitterT
+func activeMoveTypes(T float64, config *Config) int {
+	if T < config.InteriorJitterT {
+		return NumMoveTypes
+	}
+	return NumMoveTypes - 1
+}
+
+// pickMoveType draws one of the first active move types with probability proportional to
+// weights. Empty weights mean uniform, types beyond the end of weights keep weight 1 and a
+// zero weight disables a type; if every active type is disabled the draw is uniform.
+func pickMoveType(rng RNG, active int, weights []float64) int {
+	if len(weights) == 0 {
+		return rng.Intn(active)
+	}
+	weight := func(mt int) float64 {
+		if mt < len(weights) {
+			return math.Max(weights[mt], 0)
+		}
+		return 1
+	}
+
+	total := 0.0
+	for mt := 0; mt < active; mt++ {
+		total += weight(mt)
+	}
+	if total == 0 {
+		return rng.Intn(active)
+	}
+	r := rng.Float64() * total
+	last := 0
+	for mt := 0; mt < active; mt++ {
+		if w := weight(mt); w > 0 {
+			if r < w {
+				return mt
+			}
+			r -= w
+			last = mt
+		}
+	}
+	return last // Rounding left r just above the last weight
+}
+
+// JitterInterior moves every interior (non-boundary) tree by a uniform random offset of up
+// to amp in each axis at once. Interior trees do not define the box, but shuffling them
+// together can open micro-space that lets boundary trees move in later, which single-tree
+// moves cannot coordinate. Each tree is checked on its own and reverted if it collides.
+// Returns the number of trees that moved.
+func JitterInterior(trees []tree.ChristmasTree, amp float64, rng RNG) int {
+	onBoundary := make(map[int]bool)
+	for _, i := range tree.GetBoundary(trees) {
+		onBoundary[i] = true
+	}
+
+	moved := 0
+	for i := range trees {
+		if onBoundary[i] {
+			continue
+		}
+		ox, oy := trees[i].X, trees[i].Y
+		trees[i].X += (rng.Float64()*2 - 1) * amp
+		trees[i].Y += (rng.Float64()*2 - 1) * amp
+		if tree.HasOvl(trees, i) {
+			trees[i].X, trees[i].Y = ox, oy
+			continue
+		}
+		moved++
+	}
+	return moved
+}
+
+// minOverlapGradient is the overlap gradient magnitude (area per unit shift) below which
+// PerturbAdvanced's repair falls back to the radial push
+const minOverlapGradient = 1e-6
+
+// PerturbAdvanced perturbs the configuration based on strength. Trees left overlapping are
+// nudged down their OverlapGradient (radially outward where it is flat) and turned slightly
+// until free; if that fails within 150 rounds the original configuration is returned.
+func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng RNG) []tree.ChristmasTree {
 	c := CloneTrees(trees)
 	original := CloneTrees(trees) // Keep original in case we fail to fix overlaps
 
@@ -196,20 +441,25 @@ func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng *rand.Rand) []
 		c[i].Angle = math.Mod(c[i].Angle+360, 360)
 	}
 
-	// Try to fix overlaps
+	// Try to fix overlaps; the index follows every nudge so later checks see current boxes
+	index := tree.BuildRTree(c)
 	for iter := 0; iter < 150; iter++ {
 		fixed := true
 		for i := 0; i < n; i++ {
-			if tree.HasOvl(c, i) {
+			if tree.HasOvlIndexed(c, i, index) {
 				fixed = false
-				gx0, gy0, gx1, gy1 := tree.GetBounds(c)
-				cx := (gx0 + gx1) / 2.0
-				cy := (gy0 + gy1) / 2.0
-
-				dx := c[i].X - cx
-				dy := c[i].Y - cy
-				d := math.Sqrt(dx*dx + dy*dy)
-				if d > 1e-6 {
+				oldMinX, oldMinY, oldMaxX, oldMaxY := c[i].GetBoundingBox()
+
+				// Step down the overlap gradient; where it is flat, push radially out
+				// from the layout center instead
+				dx, dy := tree.OverlapGradient(c, i)
+				dx, dy = -dx, -dy
+				if math.Hypot(dx, dy) < minOverlapGradient {
+					gx0, gy0, gx1, gy1 := tree.GetBounds(c)
+					dx = c[i].X - (gx0+gx1)/2.0
+					dy = c[i].Y - (gy0+gy1)/2.0
+				}
+				if d := math.Hypot(dx, dy); d > 1e-6 {
 					c[i].X += dx / d * 0.02
 					c[i].Y += dy / d * 0.02
 				}
@@ -217,6 +467,9 @@ func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng *rand.Rand) []
 				rf2 := rng.Float64()*2 - 1
 				c[i].Angle += rf2 * 15.0
 				c[i].Angle = math.Mod(c[i].Angle+360, 360)
+				minX, minY, maxX, maxY := c[i].GetBoundingBox()
+				index.Replace([2]float64{oldMinX, oldMinY}, [2]float64{oldMaxX, oldMaxY}, i,
+					[2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
 			}
 		}
 		if fixed {
@@ -224,7 +477,7 @@ func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng *rand.Rand) []
 		}
 	}
 
-	if tree.AnyOvl(c) {
+	if tree.AnyOvlIndexed(c) {
 		return original
 	}
 	return c
@@ -232,19 +485,38 @@ func PerturbAdvanced(trees []tree.ChristmasTree, str float64, rng *rand.Rand) []
 
 // RunAdvancedSA runs the advanced Simulated Annealing optimization
 func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.ChristmasTree {
-	rng := rand.New(rand.NewSource(config.RandomSeed))
-	c := CloneTrees(initialTrees)
+	best, _ := RunAdvancedSAWithStats(initialTrees, config)
+	return best
+}
+
+// RunAdvancedSAContext runs RunAdvancedSA until ctx is done, returning the best so far
+func RunAdvancedSAContext(ctx context.Context, initialTrees []tree.ChristmasTree, config *Config) []tree.ChristmasTree {
+	best, _ := runAdvancedSA(ctx, initialTrees, config)
+	return best
+}
+
+// RunAdvancedSAWithStats runs RunAdvancedSA and also returns per-move-type statistics
+func RunAdvancedSAWithStats(initialTrees []tree.ChristmasTree, config *Config) ([]tree.ChristmasTree, SolveStats) {
+	return runAdvancedSA(context.Background(), initialTrees, config)
+}
+
+// runAdvancedSA is the advanced SA loop; ctx is checked at every temperature step
+func runAdvancedSA(ctx context.Context, initialTrees []tree.ChristmasTree, config *Config) ([]tree.ChristmasTree, SolveStats) {
+	var stats SolveStats
+	rng := NewRNG(config.RandomSeed)
+	c := config.snapStart(initialTrees)
 	best := CloneTrees(c)
 	cur := CloneTrees(c)
 
 	bs := tree.Side(best)
 	cs := bs
 	T := config.Tmax
+	config.logEstimate(len(c), bs)
 	noImp := 0
 
 	n := len(c)
 	if n == 0 {
-		return c
+		return c, stats
 	}
 
 	iter := config.NSteps * config.NStepsPerT
@@ -252,8 +524,16 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 	// Track total steps for cooling schedule
 	step := 0
 	for it := 0; it < iter; it++ {
+		if it%config.NStepsPerT == 0 && ctx.Err() != nil {
+			config.logf(LogSummary, "[n=%3d] Cancelled at temperature step %d, best %.5f\n", n, it/config.NStepsPerT, bs)
+			break
+		}
 		step++
-		mt := rng.Intn(11) // 0-10 move types
+		mt := MoveReplant
+		if config.ReplantProb <= 0 || rng.Float64() >= config.ReplantProb {
+			mt = pickMoveType(rng, activeMoveTypes(T, config), config.MoveWeights) // 0-11 move types, plus 12 late in the run
+		}
+		stats.move(mt).Attempts++
 		sc := T / config.Tmax
 		valid := true
 		savedCur := CloneTrees(cur) // Save state before mutation
@@ -284,7 +564,7 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 		case 2:
 			i := rng.Intn(n)
 			cur[i].Angle += rng.NormFloat64() * 80.0 * sc
-			cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+			cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 			if tree.HasOvl(cur, i) {
 				valid = false
 			}
@@ -296,15 +576,18 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 			cur[i].X += rf2x * 0.5 * sc
 			cur[i].Y += rf2y * 0.5 * sc
 			cur[i].Angle += rf2a * 60.0 * sc
-			cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+			cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 			if tree.HasOvl(cur, i) {
 				valid = false
 			}
 		case 4:
-			boundary := tree.GetBoundary(cur)
+			// The boundary tolerance scales with the side, so the move sees a similar share
+			// of trees for every n
+			gx0, gy0, gx1, gy1 := tree.GetBounds(cur)
+			side := math.Max(gx1-gx0, gy1-gy0)
+			boundary := tree.GetBoundaryEps(cur, boundaryEpsFraction*side)
 			if len(boundary) > 0 {
 				i := boundary[rng.Intn(len(boundary))]
-				gx0, gy0, gx1, gy1 := tree.GetBounds(cur)
 				dx := (gx0+gx1)/2.0 - cur[i].X
 				dy := (gy0+gy1)/2.0 - cur[i].Y
 				d := math.Sqrt(dx*dx + dy*dy)
@@ -315,7 +598,7 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 				}
 				rf2 := rng.Float64()*2 - 1
 				cur[i].Angle += rf2 * 50.0 * sc
-				cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+				cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 				if tree.HasOvl(cur, i) {
 					valid = false
 				}
@@ -363,22 +646,48 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 					valid = false
 				}
 			}
-			// Case 8, 9 to be implemented
+		case 8:
+			i := rng.Intn(n)
+			FineRotate(&cur[i], rng)
+			cur[i].Angle = config.SnapAngle(cur[i].Angle)
+			if tree.HasOvl(cur, i) {
+				valid = false
+			}
+		case 9:
+			if !SlideToContact(cur, rng.Intn(n), rng) {
+				valid = false
+			}
 		case 10:
 			if n > 1 {
 				i := rng.Intn(n)
 				j := rng.Intn(n)
-				if !tree.SwapTrees(cur, i, j) {
+				// Fall back to an exchange with angle search when the plain swap collides
+				// (its free angle search is skipped when angles are restricted to AngleSet)
+				if !tree.SwapTrees(cur, i, j) && (len(config.AngleSet) > 0 || !TwoOptExchange(cur, i, j)) {
 					valid = false
 				}
 			}
-		default: // mt 8, 9 fall here
-			i := rng.Intn(n)
-			rf2x := rng.Float64()*2 - 1
-			rf2y := rng.Float64()*2 - 1
-			cur[i].X += rf2x * 0.002
-			cur[i].Y += rf2y * 0.002
-			if tree.HasOvl(cur, i) {
+		case MoveGlobalRotate:
+			// A rigid rotation keeps every relative placement, so it is only worth taking
+			// when it shrinks the axis-aligned box; rotated angles would leave AngleSet
+			deg := (rng.Float64()*2 - 1) * 5.0 * math.Max(sc, 0.01)
+			rotated := tree.RotateAll(cur, deg)
+			if len(config.AngleSet) == 0 && tree.Side(rotated) < tree.Side(cur) && !tree.AnyOvl(rotated) {
+				cur = rotated
+			} else {
+				valid = false
+			}
+		case MoveInteriorJitter:
+			if JitterInterior(cur, 0.002, rng) == 0 {
+				valid = false
+			}
+		case MoveReplant:
+			var ok bool
+			if cur, ok = Replant(cur, rng); ok {
+				last := &cur[len(cur)-1]
+				last.Angle = config.SnapAngle(last.Angle)
+			}
+			if !ok || tree.AnyOvl(cur) {
 				valid = false
 			}
 		}
@@ -399,9 +708,10 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 		ns := tree.Side(cur)
 		delta := ns - cs
 
-		if delta < 0 || rng.Float64() < math.Exp(-delta/T) {
+		if Metropolis(delta, T, config.TieBreak, rng) {
+			stats.move(mt).record(cs - ns)
 			cs = ns
-			if ns < bs {
+			if betterBest(ns, bs, cur, best, config.PreferSquare) {
 				bs = ns
 				best = CloneTrees(cur)
 				noImp = 0
@@ -421,5 +731,5 @@ func RunAdvancedSA(initialTrees []tree.ChristmasTree, config *Config) []tree.Chr
 		}
 	}
 
-	return best
+	return best, stats
 }
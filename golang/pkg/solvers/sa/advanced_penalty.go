@@ -1,9 +1,8 @@
 package sa
 
 import (
-	"fmt"
+	"context"
 	"math"
-	"math/rand"
 	"time"
 
 	"tree-packing-challenge/pkg/tree"
@@ -12,17 +11,33 @@ import (
 // RunAdvancedSAPenalty runs the advanced Simulated Annealing optimization with penalty scoring.
 // It allows overlaps but penalizes them, enabling traversal through invalid states.
 func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []tree.ChristmasTree {
+	return RunAdvancedSAPenaltyContext(context.Background(), initialTrees, config)
+}
+
+// RunAdvancedSAPenaltyContext runs RunAdvancedSAPenalty until ctx is done; ctx is checked
+// at every temperature step and the best valid solution so far is returned on cancellation
+func RunAdvancedSAPenaltyContext(ctx context.Context, initialTrees []tree.ChristmasTree, config *Config) []tree.ChristmasTree {
+	return runAdvancedSAPenalty(ctx, initialTrees, config, false)
+}
+
+// runAdvancedSAPenalty is the advanced penalty SA loop. The total overlap and bounding box
+// are tracked incrementally: single and pair moves only re-evaluate the moved trees, and a
+// full recompute happens only after the global squeeze (which also resyncs any float drift).
+// fullRecompute evaluates every step from scratch instead, as a reference for tests.
+func runAdvancedSAPenalty(ctx context.Context, initialTrees []tree.ChristmasTree, config *Config, fullRecompute bool) []tree.ChristmasTree {
 	startTime := time.Now()
-	rng := rand.New(rand.NewSource(config.RandomSeed))
+	rng := NewRNG(config.RandomSeed)
 
 	// Working copy
-	cur := CloneTrees(initialTrees)
+	cur := config.snapStart(initialTrees)
 	n := len(cur)
 
 	// Initial score
-	curBBox := tree.CalculateSideLength(cur)
+	curBounds := boundsOf(cur)
+	curBBox, curSkew := curBounds.terms()
 	curOverlap := tree.CalculateTotalOverlap(cur)
-	curScore := curBBox + config.OverlapPenalty*curOverlap
+	curScore := curBBox + config.OverlapPenalty*curOverlap + config.AspectPenalty*curSkew
+	config.logEstimate(n, curBBox)
 
 	// Best VALID solution (overlap == 0)
 	// Initialize with input if valid, otherwise keep best found so far
@@ -35,6 +50,7 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 
 	iter := config.NSteps * config.NStepsPerT
 	T := config.Tmax
+	logGate := newLogGate(config)
 
 	// Helper to update best valid
 	updateBest := func() {
@@ -42,7 +58,7 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 			if curBBox < bestValidScore {
 				bestValidScore = curBBox
 				bestValidTrees = CloneTrees(cur)
-				fmt.Printf("[AdvPenalty] [n=%d] NEW BEST VALID: %.5f\n", n, bestValidScore)
+				config.logf(LogVerbose, "[AdvPenalty] [n=%d] NEW BEST VALID: %.5f\n", n, bestValidScore)
 			}
 		}
 	}
@@ -50,7 +66,11 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 	updateBest()
 
 	for it := 0; it < iter; it++ {
-		mt := rng.Intn(11) // 0-10 move types
+		if it%config.NStepsPerT == 0 && ctx.Err() != nil {
+			config.logf(LogSummary, "[AdvPenalty] [n=%d] Cancelled at temperature step %d, best valid %.5f\n", n, it/config.NStepsPerT, bestValidScore)
+			break
+		}
+		mt := pickMoveType(rng, NumPenaltyMoveTypes, config.MoveWeights)
 		sc := T / config.Tmax
 		if sc > 1 {
 			sc = 1
@@ -77,9 +97,8 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 			undoIdx = []int{i}
 			undoTrees = []tree.ChristmasTree{cur[i]}
 
-			gx0, gy0, gx1, gy1 := tree.GetBounds(cur)
-			dx := (gx0+gx1)/2.0 - cur[i].X
-			dy := (gy0+gy1)/2.0 - cur[i].Y
+			dx := (curBounds.minX+curBounds.maxX)/2.0 - cur[i].X
+			dy := (curBounds.minY+curBounds.maxY)/2.0 - cur[i].Y
 			d := math.Sqrt(dx*dx + dy*dy)
 			if d > 1e-6 {
 				rf := rng.Float64()
@@ -93,7 +112,7 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 			undoTrees = []tree.ChristmasTree{cur[i]}
 
 			cur[i].Angle += rng.NormFloat64() * 80.0 * sc
-			cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+			cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 
 		case 3: // Translate + Rotate
 			i := rng.Intn(n)
@@ -106,7 +125,7 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 			cur[i].X += rf2x * 0.5 * sc
 			cur[i].Y += rf2y * 0.5 * sc
 			cur[i].Angle += rf2a * 60.0 * sc
-			cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+			cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 
 		case 4: // Boundary move
 			boundary := tree.GetBoundary(cur)
@@ -115,9 +134,8 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 				undoIdx = []int{i}
 				undoTrees = []tree.ChristmasTree{cur[i]}
 
-				gx0, gy0, gx1, gy1 := tree.GetBounds(cur)
-				dx := (gx0+gx1)/2.0 - cur[i].X
-				dy := (gy0+gy1)/2.0 - cur[i].Y
+				dx := (curBounds.minX+curBounds.maxX)/2.0 - cur[i].X
+				dy := (curBounds.minY+curBounds.maxY)/2.0 - cur[i].Y
 				d := math.Sqrt(dx*dx + dy*dy)
 				if d > 1e-6 {
 					rf := rng.Float64()
@@ -126,15 +144,14 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 				}
 				rf2 := rng.Float64()*2 - 1
 				cur[i].Angle += rf2 * 50.0 * sc
-				cur[i].Angle = math.Mod(cur[i].Angle+360, 360)
+				cur[i].Angle = config.SnapAngle(math.Mod(cur[i].Angle+360, 360))
 			}
 		// TODO: not fully implemented
 		case 5: // Squeeze (global)
 			savedCur := CloneTrees(cur)
 			factor := 1.0 - rng.Float64()*0.004*sc
-			gx0, gy0, gx1, gy1 := tree.GetBounds(cur)
-			cx := (gx0 + gx1) / 2.0
-			cy := (gy0 + gy1) / 2.0
+			cx := (curBounds.minX + curBounds.maxX) / 2.0
+			cy := (curBounds.minY + curBounds.maxY) / 2.0
 			for i := range cur {
 				cur[i].X = cx + (cur[i].X-cx)*factor
 				cur[i].Y = cy + (cur[i].Y-cy)*factor
@@ -197,21 +214,60 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 			cur[i].Y += rf2y * 0.002 * sc
 		}
 
-		newBBox := tree.CalculateSideLength(cur)
-		newOverlap := tree.CalculateTotalOverlap(cur)
+		var newBounds treeBounds
+		var newOverlap float64
+		switch {
+		case fullRecompute || (undoIdx == nil && len(undoTrees) > 0):
+			// Reference mode, or the squeeze moved every tree
+			newBounds = boundsOf(cur)
+			newOverlap = tree.CalculateTotalOverlap(cur)
+		case undoIdx == nil:
+			// No-op move (e.g. a swap that drew the same tree twice)
+			newBounds = curBounds
+			newOverlap = curOverlap
+		default:
+			// Evaluate the moved trees in their old poses, then in their new ones
+			moved := make([]tree.ChristmasTree, len(undoIdx))
+			onEdge := false
+			for k, idx := range undoIdx {
+				moved[k] = cur[idx]
+				cur[idx] = undoTrees[k]
+				onEdge = onEdge || curBounds.touches(&cur[idx])
+			}
+			oldContribution := movedOverlap(cur, undoIdx)
+			for k, idx := range undoIdx {
+				cur[idx] = moved[k]
+			}
+			newOverlap = curOverlap - oldContribution + movedOverlap(cur, undoIdx)
+
+			// A tree leaving the edge may shrink the box, which needs a full pass
+			if onEdge {
+				newBounds = boundsOf(cur)
+			} else {
+				newBounds = curBounds
+				for _, idx := range undoIdx {
+					newBounds = newBounds.extend(&cur[idx])
+				}
+			}
+		}
+		newBBox, newSkew := newBounds.terms()
 
-		newScore := newBBox + config.OverlapPenalty*newOverlap
+		newScore := newBBox + config.OverlapPenalty*newOverlap + config.AspectPenalty*newSkew
 		delta := newScore - curScore
 
 		// Metropolis acceptance
-		if delta < 0 || rng.Float64() < math.Exp(-delta/T) {
+		if Metropolis(delta, T, config.TieBreak, rng) {
 			accepted = true
 		}
 
 		if accepted {
+			curBounds = newBounds
 			curBBox = newBBox
 			curOverlap = newOverlap
 			curScore = newScore
+			if config.Debug {
+				assertOverlapTotal(cur, curOverlap, it)
+			}
 			updateBest()
 		} else {
 			// Revert
@@ -226,10 +282,10 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 		}
 
 		// Logging
-		if it%config.LogFreq == 0 {
+		if logGate.Due(it) {
 			elapsed := time.Since(startTime).Round(time.Millisecond)
-			fmt.Printf("[AdvPenalty] T: %.3e  Step: %6d  Score: %8.5f  Overlap: %6.4f  BestValid: %8.5f  Time: %s\n",
-				T, it, curScore, curOverlap, bestValidScore, elapsed)
+			config.logf(LogVerbose, "[AdvPenalty] T: %.3e  Step: %6d  Score: %8.5f  Overlap: %6.4f (%d pairs)  BestValid: %8.5f  Time: %s\n",
+				T, it, curScore, curOverlap, tree.CountOverlappingPairs(cur), bestValidScore, elapsed)
 		}
 
 		// Cool temperature
@@ -241,3 +297,47 @@ func RunAdvancedSAPenalty(initialTrees []tree.ChristmasTree, config *Config) []t
 
 	return bestValidTrees
 }
+
+// treeBounds is the axis-aligned bounding box of a set of trees
+type treeBounds struct {
+	minX, minY, maxX, maxY float64
+}
+
+// boundsOf returns the bounding box of all trees
+func boundsOf(trees []tree.ChristmasTree) treeBounds {
+	minX, minY, maxX, maxY := tree.GetBounds(trees)
+	return treeBounds{minX, minY, maxX, maxY}
+}
+
+// terms returns the side and skew of the box, like boxTerms
+func (b treeBounds) terms() (side, skew float64) {
+	width := b.maxX - b.minX
+	height := b.maxY - b.minY
+	return math.Max(width, height), math.Abs(width - height)
+}
+
+// touches reports whether the tree's bounding box reaches an edge of b, so that removing
+// the tree could shrink b
+func (b treeBounds) touches(t *tree.ChristmasTree) bool {
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	return minX <= b.minX || minY <= b.minY || maxX >= b.maxX || maxY >= b.maxY
+}
+
+// extend returns b grown to cover the tree
+func (b treeBounds) extend(t *tree.ChristmasTree) treeBounds {
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	return treeBounds{math.Min(b.minX, minX), math.Min(b.minY, minY), math.Max(b.maxX, maxX), math.Max(b.maxY, maxY)}
+}
+
+// movedOverlap returns the part of the total overlap that involves the trees at idx: their
+// overlap with all other trees, counting overlaps among themselves once
+func movedOverlap(trees []tree.ChristmasTree, idx []int) float64 {
+	total := 0.0
+	for k, i := range idx {
+		total += tree.CalculateTreeOverlap(trees, i)
+		for _, j := range idx[:k] {
+			total -= trees[i].IntersectionArea(&trees[j])
+		}
+	}
+	return total
+}
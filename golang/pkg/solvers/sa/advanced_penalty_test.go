@@ -0,0 +1,77 @@
+package sa
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// penaltyTestTrees lays out n upright trees on a loose grid so that the run starts valid
+// with room to shrink, and soon explores overlapping states
+func penaltyTestTrees(n int) []tree.ChristmasTree {
+	cols := int(math.Ceil(math.Sqrt(float64(n))))
+	trees := make([]tree.ChristmasTree, n)
+	for i := range trees {
+		trees[i] = tree.ChristmasTree{ID: i + 1, X: float64(i%cols) * 1.0, Y: float64(i/cols) * 1.3}
+	}
+	return trees
+}
+
+func penaltyTestConfig() *Config {
+	conf := DefaultConfig()
+	conf.RandomSeed = 11
+	conf.Tmax = 0.01
+	conf.Tmin = 1e-5
+	conf.NSteps = 10
+	conf.NStepsPerT = 100
+	conf.OverlapPenalty = 200
+	conf.LogLevel = LogSilent
+	return conf
+}
+
+func TestAdvancedSAPenaltyIncrementalMatchesFull(t *testing.T) {
+	trees := penaltyTestTrees(9)
+	conf := penaltyTestConfig()
+
+	reference := runAdvancedSAPenalty(context.Background(), trees, conf, true)
+
+	// Debug also asserts the running overlap against a recompute after every accepted move
+	conf.Debug = true
+	incremental := runAdvancedSAPenalty(context.Background(), trees, conf, false)
+
+	if len(incremental) != len(reference) {
+		t.Fatalf("Expected %d trees, got %d", len(reference), len(incremental))
+	}
+	for i := range reference {
+		r, c := reference[i], incremental[i]
+		if math.Abs(r.X-c.X) > 1e-9 || math.Abs(r.Y-c.Y) > 1e-9 || math.Abs(r.Angle-c.Angle) > 1e-9 {
+			t.Errorf("Tree %d differs: reference %+v, incremental %+v", i, r, c)
+		}
+	}
+	if tree.Side(incremental) >= tree.Side(trees) {
+		t.Errorf("Expected the run to improve on the start layout")
+	}
+	if tree.HasCollision(incremental) {
+		t.Errorf("Best valid layout has collisions")
+	}
+}
+
+func BenchmarkAdvancedSAPenalty(b *testing.B) {
+	trees := penaltyTestTrees(100)
+	conf := penaltyTestConfig()
+	conf.NSteps = 4
+	conf.NStepsPerT = 100
+
+	for _, mode := range []struct {
+		name string
+		full bool
+	}{{"full", true}, {"incremental", false}} {
+		b.Run(mode.name, func(b *testing.B) {
+			for b.Loop() {
+				runAdvancedSAPenalty(context.Background(), trees, conf, mode.full)
+			}
+		})
+	}
+}
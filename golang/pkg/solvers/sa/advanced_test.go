@@ -1,6 +1,7 @@
 package sa
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 
@@ -47,6 +48,103 @@ func TestCompaction(t *testing.T) {
 	}
 }
 
+func TestSettleToCentroid(t *testing.T) {
+	// Three loose trees, the middle one off the line between the others
+	trees := []tree.ChristmasTree{
+		{ID: 1, X: 0, Y: 0, Angle: 0},
+		{ID: 2, X: 2, Y: 1.5, Angle: 90},
+		{ID: 3, X: 4, Y: 0.5, Angle: 0},
+	}
+
+	settled := SettleToCentroid(trees, 100)
+
+	origSide := tree.Side(trees)
+	newSide := tree.Side(settled)
+
+	if newSide > origSide {
+		t.Errorf("SettleToCentroid increased the side: got %f, want <= %f", newSide, origSide)
+	}
+	if newSide >= origSide {
+		t.Errorf("Expected the loose layout to tighten, side stayed at %f", newSide)
+	}
+	if tree.HasCollision(settled) {
+		t.Errorf("SettleToCentroid produced overlapping trees")
+	}
+	if trees[0].X != 0 || trees[0].Y != 0 {
+		t.Errorf("SettleToCentroid modified its input")
+	}
+}
+
+func TestReplant(t *testing.T) {
+	// A 2x2 block with a hole in the middle row and one tree stranded far to the right
+	trees := []tree.ChristmasTree{
+		{ID: 1, X: 0, Y: 0},
+		{ID: 2, X: 1.6, Y: 0},
+		{ID: 3, X: 0, Y: 2.2},
+		{ID: 4, X: 1.6, Y: 2.2},
+		{ID: 5, X: 6, Y: 1},
+	}
+
+	replanted, ok := Replant(trees, NewRNG(3))
+	if !ok {
+		t.Fatalf("Expected a free spot for the stranded tree")
+	}
+	if len(replanted) != len(trees) || replanted[len(replanted)-1].ID != 5 {
+		t.Fatalf("Expected tree 5 re-inserted last, got %+v", replanted)
+	}
+	if tree.AnyOvl(replanted) {
+		t.Errorf("Replant produced overlapping trees")
+	}
+	if got, was := tree.Side(replanted), tree.Side(trees); got >= was {
+		t.Errorf("Expected the side to shrink from %.4f, got %.4f", was, got)
+	}
+	if trees[4].X != 6 {
+		t.Errorf("Replant modified its input")
+	}
+
+	// The move stays valid inside the SA loop
+	conf := DefaultConfig()
+	conf.RandomSeed = 5
+	conf.NSteps = 5
+	conf.NStepsPerT = 40
+	conf.ReplantProb = 0.2
+	conf.LogLevel = LogSilent
+	best, stats := RunAdvancedSAWithStats(trees, conf)
+	if stats.Replant.Attempts == 0 {
+		t.Errorf("Replant move was never tried")
+	}
+	if tree.AnyOvl(best) {
+		t.Errorf("Advanced SA with replant returned overlapping trees")
+	}
+}
+
+func TestGlobalRotateMove(t *testing.T) {
+	// Four trees spaced out along a diagonal: turning the line towards an axis shrinks the box
+	var trees []tree.ChristmasTree
+	for i := 0; i < 4; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i + 1, X: float64(i), Y: float64(i)})
+	}
+
+	conf := DefaultConfig()
+	conf.RandomSeed = 2
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.LogLevel = LogSilent
+	conf.MoveWeights = make([]float64, NumMoveTypes)
+	conf.MoveWeights[MoveGlobalRotate] = 1 // Only the global rotation
+
+	best, stats := RunAdvancedSAWithStats(trees, conf)
+	if stats.Moves[MoveGlobalRotate].Improvements == 0 {
+		t.Errorf("Global rotation never improved the side")
+	}
+	if tree.Side(best) >= tree.Side(trees) {
+		t.Errorf("Expected a smaller side than %.4f, got %.4f", tree.Side(trees), tree.Side(best))
+	}
+	if tree.AnyOvl(best) {
+		t.Errorf("Global rotation produced overlapping trees")
+	}
+}
+
 func TestRunAdvancedSA(t *testing.T) {
 	// Setup small problem
 	trees := []tree.ChristmasTree{
@@ -90,3 +188,189 @@ func TestPerturbAdvanced(t *testing.T) {
 	// It's possible for Perturb to return original if overlaps can't be resolved,
 	// so we mainly check for basic validity (no panics, correct count).
 }
+
+func TestPerturbAdvancedRepairsOverlaps(t *testing.T) {
+	// In a tight layout nearly every perturbation creates overlaps; following the overlap
+	// gradient resolves them, so PerturbAdvanced rarely has to give up and return the input
+	trees, _ := tree.InitializeTreesSpiral(30)
+	rng := NewRNG(1)
+	failed := 0
+	for k := 0; k < 20; k++ {
+		perturbed := PerturbAdvanced(trees, 0.3, rng)
+		if tree.AnyOvl(perturbed) {
+			t.Fatalf("PerturbAdvanced returned overlapping trees")
+		}
+		same := true
+		for i := range perturbed {
+			same = same && perturbed[i].Equal(&trees[i])
+		}
+		if same {
+			failed++
+		}
+	}
+	if failed > 2 {
+		t.Errorf("Overlap repair gave up on %d of 20 perturbations", failed)
+	}
+}
+
+func TestTwoOptExchange(t *testing.T) {
+	// Both trees point away from each other, stretching the box horizontally
+	trees := []tree.ChristmasTree{
+		{ID: 1, X: 0, Y: 0, Angle: 90},
+		{ID: 2, X: 2, Y: 0, Angle: 270},
+	}
+	origSide := tree.Side(trees)
+
+	// A plain swap carries the angles along, so it cannot help
+	swapped := CloneTrees(trees)
+	tree.SwapTrees(swapped, 0, 1)
+	if tree.Side(swapped) < origSide-1e-9 {
+		t.Fatalf("Plain swap unexpectedly improved the side")
+	}
+
+	exchanged := CloneTrees(trees)
+	if !TwoOptExchange(exchanged, 0, 1) {
+		t.Fatalf("TwoOptExchange failed to improve the crafted pair")
+	}
+	if newSide := tree.Side(exchanged); newSide >= origSide {
+		t.Errorf("TwoOptExchange did not reduce side: got %f, want < %f", newSide, origSide)
+	}
+	if tree.AnyOvl(exchanged) {
+		t.Errorf("TwoOptExchange produced overlaps")
+	}
+}
+
+func TestRunAdvancedSAWithStats(t *testing.T) {
+	trees := []tree.ChristmasTree{
+		{ID: 1, X: 0, Y: 0, Angle: 0},
+		{ID: 2, X: 2, Y: 0, Angle: 0},
+		{ID: 3, X: 0, Y: 2, Angle: 0},
+	}
+	conf := &Config{
+		Tmax:       1.0,
+		Tmin:       0.01,
+		RandomSeed: 42,
+		NSteps:     20,
+		NStepsPerT: 20,
+		Cooling:    CoolingExponential,
+	}
+	_, stats := RunAdvancedSAWithStats(trees, conf)
+
+	attempts, improvements := 0, 0
+	for mt, m := range stats.Moves {
+		attempts += m.Attempts
+		improvements += m.Improvements
+		if m.TotalImprovement < 0 || m.MaxImprovement < 0 || m.MeanImprovement() < 0 {
+			t.Errorf("Move %d has negative improvement stats: %+v", mt, m)
+		}
+		if m.MeanImprovement() > m.MaxImprovement+1e-12 {
+			t.Errorf("Move %d mean improvement exceeds max: %+v", mt, m)
+		}
+		if m.Improvements > m.Accepted || m.Accepted > m.Attempts {
+			t.Errorf("Move %d has inconsistent counters: %+v", mt, m)
+		}
+	}
+	if attempts != conf.NSteps*conf.NStepsPerT {
+		t.Errorf("Expected %d attempts, got %d", conf.NSteps*conf.NStepsPerT, attempts)
+	}
+	if improvements == 0 {
+		t.Errorf("Expected some improving moves from a loose layout")
+	}
+}
+
+func TestFineRotateAndSlideToContact(t *testing.T) {
+	rng := NewRNG(11)
+
+	// Move 8: small in-place rotation
+	tr := tree.ChristmasTree{X: 1, Y: 2, Angle: 10}
+	FineRotate(&tr, rng)
+	if tr.X != 1 || tr.Y != 2 {
+		t.Errorf("FineRotate moved the tree")
+	}
+	if tr.Angle == 10 || math.Abs(tr.Angle-10) > 10 {
+		t.Errorf("Expected a small non-zero rotation, got angle %v", tr.Angle)
+	}
+
+	// Move 9: slide toward the nearest neighbor closes most of the gap without overlap
+	trees := []tree.ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 1.5, Y: 0, Angle: 0},
+		{ID: 2, X: 0, Y: 2.5, Angle: 0},
+	}
+	before := tree.SeparationDistance(&trees[0], &trees[1])
+	if !SlideToContact(trees, 0, rng) {
+		t.Fatalf("SlideToContact rejected a free slide")
+	}
+	after := tree.SeparationDistance(&trees[0], &trees[1])
+	if after <= 0 || after > before*0.51 {
+		t.Errorf("Expected gap to shrink by at least half without contact: %.4f -> %.4f", before, after)
+	}
+	if tree.AnyOvl(trees) {
+		t.Errorf("SlideToContact produced an overlap")
+	}
+}
+
+func TestJitterInterior(t *testing.T) {
+	// 3x3 grid: only the center tree is interior
+	var trees []tree.ChristmasTree
+	for k := 0; k < 9; k++ {
+		trees = append(trees, tree.ChristmasTree{ID: k, X: float64(k%3) * 1.2, Y: float64(k/3) * 1.2})
+	}
+	orig := CloneTrees(trees)
+
+	moved := JitterInterior(trees, 0.01, NewRNG(4))
+	if moved != 1 {
+		t.Errorf("Expected only the interior tree to move, moved %d", moved)
+	}
+	if len(trees) != len(orig) || tree.AnyOvl(trees) {
+		t.Errorf("Jitter broke count or validity")
+	}
+	for _, i := range tree.GetBoundary(orig) {
+		if !trees[i].Equal(&orig[i]) {
+			t.Errorf("Boundary tree %d moved", i)
+		}
+	}
+
+	conf := DefaultConfig()
+	conf.InteriorJitterT = 0.5
+	if activeMoveTypes(1.0, conf) != NumMoveTypes-1 {
+		t.Errorf("Interior jitter must be inactive above the threshold")
+	}
+	if activeMoveTypes(0.1, conf) != NumMoveTypes {
+		t.Errorf("Interior jitter must be active below the threshold")
+	}
+	conf.InteriorJitterT = 0
+	if activeMoveTypes(0, conf) != NumMoveTypes-1 {
+		t.Errorf("Interior jitter must stay off when disabled")
+	}
+}
+
+func TestPickMoveTypeWeights(t *testing.T) {
+	weights := make([]float64, NumMoveTypes)
+	weights[0], weights[3], weights[7] = 1, 3, 0.5 // Everything else disabled
+	total := 4.5
+
+	rng := NewRNG(11)
+	const draws = 200000
+	var counts [NumMoveTypes]int
+	for k := 0; k < draws; k++ {
+		counts[pickMoveType(rng, NumMoveTypes-1, weights)]++
+	}
+	for mt, c := range counts {
+		want := weights[mt] / total
+		if got := float64(c) / draws; math.Abs(got-want) > 0.01 {
+			t.Errorf("Move type %d drawn with frequency %.4f, want %.4f", mt, got, want)
+		}
+	}
+
+	// Unset weights keep the uniform draw
+	var uniform [NumMoveTypes]int
+	for k := 0; k < draws; k++ {
+		uniform[pickMoveType(rng, NumMoveTypes, nil)]++
+	}
+	for mt, c := range uniform {
+		if got := float64(c) / draws; math.Abs(got-1.0/NumMoveTypes) > 0.01 {
+			t.Errorf("Uniform draw gave move type %d frequency %.4f", mt, got)
+		}
+	}
+}
@@ -5,7 +5,6 @@ package sa
 import (
 	"fmt"
 	"math"
-	"math/rand"
 	"time"
 
 	"tree-packing-challenge/pkg/tree"
@@ -15,7 +14,15 @@ import (
 type Base struct {
 	Trees  []tree.ChristmasTree
 	Config *Config
-	Rng    *rand.Rand
+	Rng    RNG
+
+	// OnImprove, if set, is called with the global step and the square side of the new best
+	// whenever the solver finds a better valid solution
+	OnImprove func(step int, best float64)
+
+	// Trace, if set, receives every applied built-in move of the collision-free Solve
+	// (see ReplayTrace); steps made by Config.Moves are not recorded
+	Trace *Trace
 }
 
 // NewBase creates a new base SA solver with shared setup
@@ -27,25 +34,45 @@ func NewBase(trees []tree.ChristmasTree, config *Config) *Base {
 	return &Base{
 		Trees:  trees,
 		Config: config,
-		Rng:    rand.New(rand.NewSource(config.RandomSeed)),
+		Rng:    NewRNG(config.RandomSeed),
+	}
+}
+
+// notifyImprove invokes the OnImprove hook and Config.OnNewBest, with a clone of the best
+// layout, for those that are set
+func (sa *Base) notifyImprove(step int, best float64, bestTrees []tree.ChristmasTree) {
+	if sa.OnImprove != nil {
+		sa.OnImprove(step, best)
+	}
+	if sa.Config.OnNewBest != nil {
+		sa.Config.OnNewBest(best, CloneTrees(bestTrees))
 	}
 }
 
 // PerturbTree perturbs a tree's position and angle, returns old params
 func (sa *Base) PerturbTree(t *tree.ChristmasTree) (oldX, oldY, oldAngle float64) {
 	oldX, oldY, oldAngle = t.X, t.Y, t.Angle
+	dx, dy, dAngle := sa.drawDelta()
+	sa.ApplyDelta(t, dx, dy, dAngle)
+	return oldX, oldY, oldAngle
+}
 
-	dx := (sa.Rng.Float64()*2 - 1) * sa.Config.PositionDelta
-	dy := (sa.Rng.Float64()*2 - 1) * sa.Config.PositionDelta
+// drawDelta draws a random position and angle change for PerturbTree
+func (sa *Base) drawDelta() (dx, dy, dAngle float64) {
+	dx = (sa.Rng.Float64()*2 - 1) * sa.Config.PositionDelta
+	dy = (sa.Rng.Float64()*2 - 1) * sa.Config.PositionDelta
 	// Gaussian-distributed angle perturbation, clamped to [-180, 180]
-	dAngle := sa.Rng.NormFloat64() * sa.Config.AngleDelta
+	dAngle = sa.Rng.NormFloat64() * sa.Config.AngleDelta
 	dAngle = math.Max(-180, math.Min(180, dAngle))
+	return dx, dy, dAngle
+}
 
+// ApplyDelta moves the tree by (dx, dy) and rotates it by dAngle (snapped to AngleSet).
+// Applying the same deltas to the same pose always gives bit-identical results.
+func (sa *Base) ApplyDelta(t *tree.ChristmasTree, dx, dy, dAngle float64) {
 	t.X += dx
 	t.Y += dy
-	t.Angle = math.Mod(t.Angle+dAngle+360, 360)
-
-	return oldX, oldY, oldAngle
+	t.Angle = sa.Config.SnapAngle(tree.NormalizeAngle(t.Angle + dAngle))
 }
 
 // RestoreTree restores a tree to its previous position
@@ -55,6 +82,41 @@ func (sa *Base) RestoreTree(t *tree.ChristmasTree, x, y, angle float64) {
 	t.Angle = angle
 }
 
+// Accept applies the Metropolis criterion using the solver's RNG
+func (sa *Base) Accept(delta, T float64) bool {
+	return Metropolis(delta, T, sa.Config.TieBreak, sa.Rng)
+}
+
+// Metropolis decides whether a move with score change delta is accepted at temperature T.
+// Improvements are always accepted, and so are ties while T > 0. A quenched (T <= 0)
+// search rejects ties; when tieBreak is set it accepts them with probability 0.5
+// instead, so it can still wander across plateaus rather than always keeping the
+// existing state.
+func Metropolis(delta, T float64, tieBreak bool, rng RNG) bool {
+	if delta < 0 {
+		return true
+	}
+	if T <= 0 {
+		return delta == 0 && tieBreak && rng.Float64() < 0.5
+	}
+	return rng.Float64() < math.Exp(-delta/T)
+}
+
+// betterBest reports whether a candidate configuration should replace the best one.
+// A strictly smaller side always wins; with preferSquare an equal side also wins when
+// the candidate's box is squarer, keeping a more improvable incumbent.
+func betterBest(side, bestSide float64, trees, best []tree.ChristmasTree, preferSquare bool) bool {
+	if side < bestSide-1e-12 {
+		return true
+	}
+	if !preferSquare || side > bestSide+1e-12 {
+		return false
+	}
+	_, skew := boxTerms(trees)
+	_, bestSkew := boxTerms(best)
+	return skew < bestSkew-1e-12
+}
+
 // CoolTemperature applies the cooling schedule and returns the new temperature
 func (sa *Base) CoolTemperature(T float64, step int) float64 {
 	return GetNextTemperature(sa.Config, T, step)
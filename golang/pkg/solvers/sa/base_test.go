@@ -0,0 +1,70 @@
+package sa
+
+import (
+	"math/rand"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestMetropolisTieBreak(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	// At T=0 a tie is never accepted by the plain Metropolis rule
+	for k := 0; k < 100; k++ {
+		if Metropolis(0, 0, false, rng) {
+			t.Fatalf("Tie accepted at T=0 without tie-break")
+		}
+	}
+
+	// With tie-break enabled, roughly half of the ties are accepted
+	accepted := 0
+	for k := 0; k < 1000; k++ {
+		if Metropolis(0, 0, true, rng) {
+			accepted++
+		}
+	}
+	if accepted == 0 || accepted == 1000 {
+		t.Errorf("Expected some but not all ties to be accepted, got %d/1000", accepted)
+	}
+
+	// Improvements are always accepted
+	if !Metropolis(-1, 0, true, rng) {
+		t.Errorf("Improving move rejected")
+	}
+
+	// Above T=0 ties are always accepted, with or without tie-break
+	for _, tieBreak := range []bool{false, true} {
+		for k := 0; k < 1000; k++ {
+			if !Metropolis(0, 0.5, tieBreak, rng) {
+				t.Fatalf("Tie rejected at T=0.5 (tie-break %v)", tieBreak)
+			}
+		}
+	}
+}
+
+func TestBetterBestPrefersSquare(t *testing.T) {
+	// Both layouts are 1.0 tall; the first is much narrower
+	skewed := []tree.ChristmasTree{{ID: 0, X: 0, Y: 0, Angle: 0}}
+	squarer := []tree.ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 0.25, Y: 0, Angle: 0},
+	}
+	side := tree.Side(skewed)
+	if tree.Side(squarer) != side {
+		t.Fatalf("Test layouts must have equal sides")
+	}
+
+	if betterBest(side, side, squarer, skewed, false) {
+		t.Errorf("Without PreferSquare an equal side must not replace the best")
+	}
+	if !betterBest(side, side, squarer, skewed, true) {
+		t.Errorf("With PreferSquare the squarer layout should become best")
+	}
+	if betterBest(side, side, skewed, squarer, true) {
+		t.Errorf("A more skewed layout must not replace the best")
+	}
+	if !betterBest(side-0.1, side, skewed, squarer, false) {
+		t.Errorf("A strictly smaller side must always win")
+	}
+}
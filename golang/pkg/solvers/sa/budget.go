@@ -0,0 +1,72 @@
+package sa
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadWeights reads per-n importance factors from a CSV file with "n,weight" rows
+// (an optional header line is skipped). Weights must be positive.
+func LoadWeights(path string) (map[int]float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open weights file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse weights file: %w", err)
+	}
+
+	weights := make(map[int]float64)
+	for k, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("weights line %d: expected n,weight", k+1)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			if k == 0 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("weights line %d: invalid n %q", k+1, record[0])
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("weights line %d: invalid weight %q", k+1, record[1])
+		}
+		weights[n] = w
+	}
+	return weights, nil
+}
+
+// AllocateSteps splits a total budget of outer temperature steps across ns proportionally
+// to their weights (n missing from weights count as 1). Every n gets at least one step.
+func AllocateSteps(totalSteps int, weights map[int]float64, ns []int) map[int]int {
+	sum := 0.0
+	for _, n := range ns {
+		sum += weightFor(weights, n)
+	}
+
+	steps := make(map[int]int, len(ns))
+	for _, n := range ns {
+		s := int(math.Round(float64(totalSteps) * weightFor(weights, n) / sum))
+		if s < 1 {
+			s = 1
+		}
+		steps[n] = s
+	}
+	return steps
+}
+
+// weightFor returns the weight of n, defaulting to 1
+func weightFor(weights map[int]float64, n int) float64 {
+	if w, ok := weights[n]; ok {
+		return w
+	}
+	return 1
+}
@@ -0,0 +1,44 @@
+package sa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAllocateSteps(t *testing.T) {
+	ns := []int{1, 2, 3, 4}
+	weights := map[int]float64{1: 1, 2: 2, 3: 5} // n=4 defaults to 1
+
+	steps := AllocateSteps(900, weights, ns)
+
+	want := map[int]int{1: 100, 2: 200, 3: 500, 4: 100}
+	for n, w := range want {
+		if steps[n] != w {
+			t.Errorf("n=%d: got %d steps, want %d", n, steps[n], w)
+		}
+	}
+
+	// Uniform weights split the budget evenly
+	uniform := AllocateSteps(400, nil, ns)
+	for _, n := range ns {
+		if uniform[n] != 100 {
+			t.Errorf("Uniform n=%d: got %d steps, want 100", n, uniform[n])
+		}
+	}
+}
+
+func TestLoadWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.csv")
+	if err := os.WriteFile(path, []byte("n,weight\n5,2.5\n10,0.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	weights, err := LoadWeights(path)
+	if err != nil {
+		t.Fatalf("LoadWeights failed: %v", err)
+	}
+	if weights[5] != 2.5 || weights[10] != 0.5 || len(weights) != 2 {
+		t.Errorf("Unexpected weights: %v", weights)
+	}
+}
@@ -1,8 +1,7 @@
 package sa
 
 import (
-	"fmt"
-	"math"
+	"context"
 	"time"
 
 	"tree-packing-challenge/pkg/tree"
@@ -23,56 +22,274 @@ func NewSimulatedAnnealing(trees []tree.ChristmasTree, config *Config) *Simulate
 // Solve runs the collision-free simulated annealing algorithm
 // Moves that cause collisions are rejected
 func (sa *SimulatedAnnealing) Solve() (float64, []tree.ChristmasTree) {
+	return sa.SolveContext(context.Background())
+}
+
+// SolveContext runs Solve until ctx is done; ctx is checked at the top of each
+// temperature step and the best solution so far is returned on cancellation.
+// Config.MaxDuration, if set, also ends the run early once exceeded.
+// Config.TargetScore, if set, ends the run as soon as the best score reaches it.
+// With Config.ReheatAfter set, a run stalled for that many temperature steps is reheated.
+func (sa *SimulatedAnnealing) SolveContext(ctx context.Context) (float64, []tree.ChristmasTree) {
+	score, best, _ := sa.solve(ctx)
+	return score, best
+}
+
+// SolveWithStats runs Solve and also returns acceptance statistics of the run
+func (sa *SimulatedAnnealing) SolveWithStats() (float64, []tree.ChristmasTree, SAStats) {
+	return sa.solve(context.Background())
+}
+
+// solve is the collision-free SA loop behind Solve, SolveContext and SolveWithStats
+func (sa *SimulatedAnnealing) solve(ctx context.Context) (float64, []tree.ChristmasTree, SAStats) {
 	startTime := time.Now()
+	var stats SAStats
 
 	T := sa.Config.Tmax
-	currentTrees := CloneTrees(sa.Trees)
-	currentScore := tree.CalculateScore(currentTrees)
+	currentTrees := sa.Config.snapStart(sa.Trees)
+	// The side length is tracked incrementally; only the moved trees are re-measured
+	bbox := tree.NewBBoxTracker(currentTrees)
+	objective := func() float64 {
+		if sa.Config.Objective == ObjectiveCircle {
+			return tree.CircleScore(currentTrees)
+		}
+		return bbox.Side()
+	}
+	currentScore := objective()
 	bestScore := currentScore
+	sa.Config.logEstimate(len(currentTrees), bbox.Side())
 	bestTrees := CloneTrees(currentTrees)
+	// Consumers of the best (TargetScore, OnImprove, OnNewBest) always get the square side
+	bestSide := func() float64 {
+		if sa.Config.Objective == ObjectiveCircle {
+			return tree.CalculateSideLength(bestTrees)
+		}
+		return bestScore
+	}
+	logGate := newLogGate(sa.Config)
 
-	for step := 0; step < sa.Config.NSteps; step++ {
-		for step1 := 0; step1 < sa.Config.NStepsPerT; step1++ {
-			// Select random tree to perturb
-			i := sa.Rng.Intn(len(currentTrees))
-			oldX, oldY, oldAngle := sa.PerturbTree(&currentTrees[i])
+	// The no-overlap invariant only holds if the start is valid
+	debugValid := sa.Config.Debug && !tree.AnyOvl(currentTrees)
+
+	// The cooling schedule restarts at every reheat, scaled down to the reheat temperature
+	schedStep, schedScale := 0, 1.0
+	stalled := 0
+
+	// targetReached reports (and logs) whether the best score is already good enough
+	targetReached := func(step int) bool {
+		side := bestSide()
+		if sa.Config.TargetScore <= 0 || side > sa.Config.TargetScore {
+			return false
+		}
+		sa.Config.logf(LogSummary, "[n=%3d] Target score %.5f reached at step %d, best %.5f\n",
+			len(currentTrees), sa.Config.TargetScore, step, side)
+		return true
+	}
+	done := targetReached(0)
 
-			// Check for collision - reject if collision detected
+annealing:
+	for step := 0; !done && step < sa.Config.NSteps; step++ {
+		improved := false
+		if ctx.Err() != nil {
+			sa.Config.logf(LogSummary, "[n=%3d] Cancelled at temperature step %d, best %.5f\n", len(currentTrees), step, bestScore)
+			break
+		}
+		for step1 := 0; step1 < sa.Config.NStepsPerT; step1++ {
 			currentStep := step*sa.Config.NStepsPerT + step1
-			if currentStep%sa.Config.LogFreq == 0 {
+			if sa.Config.MaxDuration > 0 && time.Since(startTime) > sa.Config.MaxDuration {
+				sa.Config.logf(LogSummary, "[n=%3d] Time budget %s exhausted at step %d, best %.5f\n",
+					len(currentTrees), sa.Config.MaxDuration, currentStep, bestScore)
+				break annealing
+			}
+			logNow := logGate.Due(currentStep)
+			if logNow {
 				elapsed := FormatDuration(time.Since(startTime))
-				fmt.Printf("[Trees: %d]T: %.3f  Step: %6d  Score: %8.5f  Best: %8.5f  Time: %s\n",
+				sa.Config.logf(LogVerbose, "[Trees: %d]T: %.3f  Step: %6d  Score: %8.5f  Best: %8.5f  Time: %s\n",
 					len(currentTrees), T, currentStep, currentScore, bestScore, elapsed)
 			}
-			if tree.HasCollision(currentTrees) {
-				sa.RestoreTree(&currentTrees[i], oldX, oldY, oldAngle)
+
+			// Perturb the configuration - moves causing a collision are reverted. A traced
+			// plug-in move is recorded by comparing the layout against a copy from before it.
+			var before []tree.ChristmasTree
+			if sa.Trace != nil && len(sa.Config.Moves) > 0 {
+				before = CloneTrees(currentTrees)
+			}
+			moved, undo, ok := sa.applyMove(currentTrees, bbox)
+			if !ok {
+				stats.RejectedCollision++
 				continue
 			}
 
-			newScore := tree.CalculateScore(currentTrees)
+			newScore := objective()
 			delta := newScore - currentScore
 
 			// Accept if better or with probability exp(-delta/T)
-			if delta < 0 || sa.Rng.Float64() < math.Exp(-delta/T) {
+			accepted := sa.Accept(delta, T)
+			if sa.Trace != nil {
+				if moved != nil {
+					sa.Trace.record(moved, accepted)
+				} else {
+					sa.Trace.recordPoses(before, currentTrees, accepted)
+				}
+			}
+			if accepted {
+				stats.Accepted++
 				currentScore = newScore
-				if newScore < bestScore {
+				if debugValid {
+					assertNoOverlap(currentTrees, currentStep)
+				}
+				if betterBest(newScore, bestScore, currentTrees, bestTrees, sa.Config.PreferSquare) {
 					bestScore = newScore
 					bestTrees = CloneTrees(currentTrees)
-					fmt.Printf("[n=%3d] NEW BEST SCORE: %8.5f\n", len(currentTrees), bestScore)
+					stats.NewBest++
+					improved = true
+					sa.Config.logf(LogVerbose, "[n=%3d] NEW BEST SCORE: %8.5f\n", len(currentTrees), bestScore)
+					sa.notifyImprove(currentStep, bestSide(), bestTrees)
+					if targetReached(currentStep) {
+						break annealing
+					}
 				}
 			} else {
-				sa.RestoreTree(&currentTrees[i], oldX, oldY, oldAngle)
+				stats.Rejected++
+				undo()
 			}
 
-			if currentStep%sa.Config.LogFreq == 0 {
+			if logNow {
 				elapsed := FormatDuration(time.Since(startTime))
-				fmt.Printf("[n=%3d] T: %.3e  Step: %6d  Score: %8.5f  Best: %8.5f  Time: %s\n",
+				sa.Config.logf(LogVerbose, "[n=%3d] T: %.3e  Step: %6d  Score: %8.5f  Best: %8.5f  Time: %s\n",
 					len(currentTrees), T, currentStep, currentScore, bestScore, elapsed)
 			}
 		}
 
-		T = sa.CoolTemperature(T, step)
+		if improved {
+			stalled = 0
+		} else {
+			stalled++
+		}
+		if sa.Config.ReheatAfter > 0 && stalled >= sa.Config.ReheatAfter {
+			schedStep, schedScale, stalled = 0, sa.Config.ReheatFactor, 0
+			if schedScale <= 0 {
+				schedScale = 1 // Reheat to Tmax rather than freezing at T=0
+			}
+			T = sa.Config.Tmax * schedScale
+			stats.Reheats++
+			sa.Config.logf(LogSummary, "[n=%3d] No new best for %d temperature steps, reheating to T=%.3e at step %d\n",
+				len(currentTrees), sa.Config.ReheatAfter, T, step)
+			continue
+		}
+
+		T = schedScale * sa.CoolTemperature(T/schedScale, schedStep)
+		schedStep++
+	}
+
+	if sa.Trace != nil {
+		sa.Trace.Final = CloneTrees(currentTrees)
+	}
+	stats.FinalT = T
+	stats.Elapsed = time.Since(startTime)
+	if sa.Config.Objective == ObjectiveCircle {
+		sa.Config.logf(LogSummary, "[n=%3d] Best circle diameter %.5f, square side %.5f\n", len(bestTrees), bestScore, tree.CalculateSideLength(bestTrees))
+		bestScore = tree.CalculateSideLength(bestTrees)
+	}
+	return bestScore, bestTrees, stats
+}
+
+// applyMove performs one step's perturbation and keeps bbox in sync: perturbSet by default,
+// or a random entry of Config.Moves. It returns the perturbSet moves (nil for Config.Moves),
+// a function reverting the step and whether the step left a changed, collision-free layout.
+func (sa *SimulatedAnnealing) applyMove(trees []tree.ChristmasTree, bbox *tree.BBoxTracker) ([]treeUndo, func(), bool) {
+	if len(sa.Config.Moves) == 0 {
+		moved, _ := sa.perturbSet(trees)
+		if len(moved) == 0 {
+			return nil, nil, false
+		}
+		trackMoves(bbox, trees, moved)
+		return moved, func() {
+			sa.revertSet(trees, moved)
+			trackMoves(bbox, trees, moved)
+		}, true
+	}
+
+	// Plug-in moves may touch any tree, so validity and bounds are checked in full
+	move := sa.Config.Moves[sa.Rng.Intn(len(sa.Config.Moves))]
+	undo := move.Apply(trees, sa.Rng)
+	if tree.HasCollision(trees) {
+		undo()
+		return nil, nil, false
+	}
+	*bbox = *tree.NewBBoxTracker(trees)
+	return nil, func() {
+		undo()
+		*bbox = *tree.NewBBoxTracker(trees)
+	}, true
+}
+
+// treeUndo records a tree's pose before it was perturbed and the deltas applied to it
+type treeUndo struct {
+	idx            int
+	x, y, angle    float64
+	dx, dy, dAngle float64
+}
+
+// perturbSet perturbs Config.TreesPerStep random trees (at least one) one after another.
+// Each move is checked for collisions on its own and reverted individually if invalid,
+// so the valid moves of the set are kept. Returns the kept moves (for revertSet) and
+// the indices of all trees considered.
+func (sa *SimulatedAnnealing) perturbSet(trees []tree.ChristmasTree) ([]treeUndo, []int) {
+	k := sa.Config.TreesPerStep
+	if k < 1 {
+		k = 1
+	}
+
+	var moved []treeUndo
+	considered := make([]int, 0, k)
+	for m := 0; m < k; m++ {
+		i := sa.Rng.Intn(len(trees))
+		considered = append(considered, i)
+		oldX, oldY, oldAngle := trees[i].X, trees[i].Y, trees[i].Angle
+		dx, dy, dAngle := sa.drawDelta()
+		sa.ApplyDelta(&trees[i], dx, dy, dAngle)
+		if treeCollides(trees, i) {
+			sa.RestoreTree(&trees[i], oldX, oldY, oldAngle)
+			continue
+		}
+		moved = append(moved, treeUndo{idx: i, x: oldX, y: oldY, angle: oldAngle, dx: dx, dy: dy, dAngle: dAngle})
+	}
+	return moved, considered
+}
+
+// revertSet undoes moves recorded by perturbSet, latest first
+func (sa *SimulatedAnnealing) revertSet(trees []tree.ChristmasTree, moved []treeUndo) {
+	for k := len(moved) - 1; k >= 0; k-- {
+		u := moved[k]
+		sa.RestoreTree(&trees[u.idx], u.x, u.y, u.angle)
+	}
+}
+
+// trackMoves updates the bounding-box tracker for the trees touched by moved
+func trackMoves(bbox *tree.BBoxTracker, trees []tree.ChristmasTree, moved []treeUndo) {
+	for _, u := range moved {
+		bbox.Update(u.idx, &trees[u.idx])
 	}
+}
+
+// treeCollides reports whether tree i intersects any other tree
+func treeCollides(trees []tree.ChristmasTree, i int) bool {
+	return collidesExcept(trees, i, -1)
+}
 
-	return bestScore, bestTrees
+// collidesExcept reports whether tree i intersects any tree other than ignore,
+// skipping the polygon test for trees whose bounding boxes are disjoint
+func collidesExcept(trees []tree.ChristmasTree, i, ignore int) bool {
+	minX, minY, maxX, maxY := trees[i].GetBoundingBox()
+	for j := range trees {
+		if j == i || j == ignore {
+			continue
+		}
+		oMinX, oMinY, oMaxX, oMaxY := trees[j].GetBoundingBox()
+		if minX <= oMaxX && maxX >= oMinX && minY <= oMaxY && maxY >= oMinY && trees[i].Intersect(&trees[j]) {
+			return true
+		}
+	}
+	return false
 }
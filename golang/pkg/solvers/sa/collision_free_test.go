@@ -0,0 +1,279 @@
+package sa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestPerturbSet(t *testing.T) {
+	// Tightly packed row: large moves frequently collide
+	var trees []tree.ChristmasTree
+	for i := 0; i < 6; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.72, Y: 0, Angle: 0})
+	}
+
+	conf := DefaultConfig()
+	conf.RandomSeed = 7
+	conf.TreesPerStep = 3
+	conf.PositionDelta = 0.5
+	solver := NewSimulatedAnnealing(trees, conf)
+
+	rejected := 0
+	for step := 0; step < 50; step++ {
+		cur := CloneTrees(trees)
+		moved, considered := solver.perturbSet(cur)
+
+		if len(considered) != 3 {
+			t.Fatalf("Expected 3 trees considered per step, got %d", len(considered))
+		}
+		if tree.HasCollision(cur) {
+			t.Fatalf("perturbSet left a collision")
+		}
+
+		// Trees that were not kept must be back at their original pose
+		kept := make(map[int]bool)
+		for _, u := range moved {
+			kept[u.idx] = true
+		}
+		for i := range cur {
+			if !kept[i] && !cur[i].Equal(&trees[i]) {
+				t.Fatalf("Tree %d was not reverted", i)
+			}
+		}
+		rejected += len(considered) - len(moved)
+
+		solver.revertSet(cur, moved)
+		for i := range cur {
+			if !cur[i].Equal(&trees[i]) {
+				t.Fatalf("revertSet did not restore tree %d", i)
+			}
+		}
+	}
+
+	if rejected == 0 {
+		t.Errorf("Expected some moves to be rejected individually")
+	}
+}
+
+func TestSolveContextCancelled(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.8, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.NSteps = 1000000
+	solver := NewSimulatedAnnealing(trees, conf)
+
+	// Cancel after the first improvement; the solver must stop at the next temperature step
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	solver.OnImprove = func(int, float64) { cancel() }
+
+	start := time.Now()
+	score, best := solver.SolveContext(ctx)
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("SolveContext did not return promptly after cancellation: %s", elapsed)
+	}
+	if len(best) != len(trees) || tree.HasCollision(best) {
+		t.Fatalf("Cancelled solve returned an invalid layout")
+	}
+	if score > tree.CalculateSideLength(trees)+1e-9 {
+		t.Errorf("Best-so-far %.6f is worse than the initial side %.6f", score, tree.CalculateSideLength(trees))
+	}
+}
+
+func TestSolveMaxDuration(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.8, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.NSteps = 1000000
+	conf.MaxDuration = 50 * time.Millisecond
+
+	start := time.Now()
+	_, best := NewSimulatedAnnealing(trees, conf).Solve()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Solve ignored MaxDuration: took %s", elapsed)
+	}
+	if len(best) != len(trees) || tree.HasCollision(best) {
+		t.Fatalf("Time-limited solve returned an invalid layout")
+	}
+}
+
+func TestSolveTargetScore(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.8, Y: 0, Angle: 0})
+	}
+	initial := tree.CalculateSideLength(trees)
+	conf := DefaultConfig()
+	conf.RandomSeed = 5
+	conf.NSteps = 1000000
+	conf.LogLevel = LogSilent
+
+	// A target the start already meets ends the run before any step
+	conf.TargetScore = initial
+	_, _, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats()
+	if total := stats.Accepted + stats.Rejected + stats.RejectedCollision; total != 0 {
+		t.Errorf("Expected no steps with the target already met, got %d", total)
+	}
+
+	// Otherwise the run stops at the first best that reaches the target
+	conf.TargetScore = initial * 0.98
+	start := time.Now()
+	score, best, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats()
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Solve did not stop at the target score: took %s", elapsed)
+	}
+	if score > conf.TargetScore {
+		t.Errorf("Returned score %.6f above the target %.6f", score, conf.TargetScore)
+	}
+	if len(best) != len(trees) || tree.HasCollision(best) {
+		t.Fatalf("Solve returned an invalid layout")
+	}
+	if stats.NewBest == 0 {
+		t.Errorf("Expected the target to be reached through a new best")
+	}
+}
+
+func TestSolveOnNewBest(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.8, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.RandomSeed = 3
+	conf.NSteps = 20
+	conf.NStepsPerT = 50
+	conf.LogLevel = LogSilent
+
+	var scores []float64
+	var snapshots [][]tree.ChristmasTree
+	conf.OnNewBest = func(score float64, best []tree.ChristmasTree) {
+		scores = append(scores, score)
+		snapshots = append(snapshots, best)
+	}
+	score, best, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats()
+
+	if len(scores) != stats.NewBest || len(scores) == 0 {
+		t.Fatalf("Expected one call per new best (%d), got %d", stats.NewBest, len(scores))
+	}
+	for k := 1; k < len(scores); k++ {
+		if scores[k] >= scores[k-1] {
+			t.Errorf("Call %d reported %.6f after %.6f", k, scores[k], scores[k-1])
+		}
+	}
+	// Every snapshot is its own copy that still matches the score it was reported with
+	for k, snapshot := range snapshots {
+		if got := tree.CalculateSideLength(snapshot); got != scores[k] || tree.HasCollision(snapshot) {
+			t.Errorf("Snapshot %d has side %.6f, reported %.6f", k, got, scores[k])
+		}
+	}
+	last := snapshots[len(snapshots)-1]
+	if scores[len(scores)-1] != score || &last[0] == &best[0] {
+		t.Errorf("The last call should report the returned best as a copy")
+	}
+}
+
+func TestSolveWithStats(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.75, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.RandomSeed = 3
+	conf.NSteps = 20
+	conf.NStepsPerT = 50
+	conf.LogFreq = 1000000
+
+	score, _, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats()
+	if total := stats.Accepted + stats.Rejected + stats.RejectedCollision; total != conf.NSteps*conf.NStepsPerT {
+		t.Errorf("Counters add up to %d steps, expected %d", total, conf.NSteps*conf.NStepsPerT)
+	}
+	if stats.RejectedCollision == 0 {
+		t.Errorf("Expected some collision rejections in a tight row")
+	}
+	if score < tree.CalculateSideLength(trees)-1e-9 && stats.NewBest == 0 {
+		t.Errorf("Score improved but no new-best event was counted")
+	}
+	if stats.FinalT >= conf.Tmax || stats.Elapsed <= 0 {
+		t.Errorf("Unexpected final temperature %.3g or elapsed %s", stats.FinalT, stats.Elapsed)
+	}
+	if r := stats.AcceptanceRate(); r < 0 || r > 1 {
+		t.Errorf("Acceptance rate %.3f out of range", r)
+	}
+}
+
+func TestSolveReheat(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 4; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.9, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.RandomSeed = 5
+	conf.NSteps = 60
+	conf.NStepsPerT = 20
+	conf.LogFreq = 1000000
+	conf.ReheatAfter = 3
+
+	_, best, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats()
+	if stats.Reheats == 0 {
+		t.Errorf("Expected the run to stall and reheat at least once")
+	}
+	if tree.HasCollision(best) || tree.CalculateSideLength(best) > tree.CalculateSideLength(trees)+1e-9 {
+		t.Errorf("Reheating must keep the best valid solution")
+	}
+
+	// Disabled by default
+	conf.ReheatAfter = 0
+	if _, _, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats(); stats.Reheats != 0 {
+		t.Errorf("Reheated %d times with ReheatAfter = 0", stats.Reheats)
+	}
+}
+
+func TestSolveCircleObjective(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 4; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 1.2, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.RandomSeed = 6
+	conf.NSteps = 20
+	conf.NStepsPerT = 30
+	conf.LogFreq = 1000000
+	conf.Objective = ObjectiveCircle
+	// The hooks get the square side of each new best too
+	conf.OnNewBest = func(score float64, best []tree.ChristmasTree) {
+		if side := tree.CalculateSideLength(best); score != side {
+			t.Errorf("OnNewBest got %.6f, want the square side %.6f", score, side)
+		}
+	}
+
+	solver := NewSimulatedAnnealing(trees, conf)
+	improvements := 0
+	solver.OnImprove = func(int, float64) { improvements++ }
+	score, best := solver.Solve()
+	if tree.HasCollision(best) {
+		t.Fatalf("Circle objective produced collisions")
+	}
+	if side := tree.CalculateSideLength(best); score != side {
+		t.Errorf("Solve must report the square side %.6f, got %.6f", side, score)
+	}
+	if improvements == 0 {
+		t.Errorf("No new best was reported")
+	}
+
+	// TargetScore is compared with the square side: the starting side stops the run at once
+	conf.OnNewBest = nil
+	conf.TargetScore = tree.CalculateSideLength(trees)
+	if _, _, stats := NewSimulatedAnnealing(trees, conf).SolveWithStats(); stats.Accepted != 0 {
+		t.Errorf("Run went on for %d accepted moves past a reached target", stats.Accepted)
+	}
+	if tree.CircleScore(best) >= tree.CircleScore(trees) {
+		t.Errorf("Expected the enclosing circle to shrink from %.5f, got %.5f", tree.CircleScore(trees), tree.CircleScore(best))
+	}
+}
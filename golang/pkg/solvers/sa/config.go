@@ -2,9 +2,13 @@ package sa
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"tree-packing-challenge/pkg/tree"
 )
 
 // CoolingSchedule represents the type of cooling schedule
@@ -16,6 +20,14 @@ const (
 	CoolingPolynomial  CoolingSchedule = "polynomial"
 )
 
+// Objective is the quantity the collision-free SA minimizes
+type Objective string
+
+const (
+	ObjectiveSquare Objective = "square" // Side of the bounding square (the competition score)
+	ObjectiveCircle Objective = "circle" // Diameter of the minimum enclosing circle
+)
+
 // Config holds configuration parameters for simulated annealing
 type Config struct {
 	Tmax           float64         `yaml:"Tmax"`
@@ -29,7 +41,70 @@ type Config struct {
 	AngleDelta     float64         `yaml:"angle_delta"`
 	RandomSeed     int64           `yaml:"random_state"`
 	LogFreq        int             `yaml:"log_freq"`
+	LogInterval    time.Duration   `yaml:"log_interval"`    // If set, log every interval instead of every LogFreq steps
+	LogLevel       LogLevel        `yaml:"log_level"`       // silent, summary or verbose (status lines need verbose); empty = summary
 	OverlapPenalty float64         `yaml:"overlap_penalty"` // λ multiplier for penalty-based SA
+	AspectPenalty  float64         `yaml:"aspect_penalty"`  // λ2 multiplier for |width - height| in penalty-based SA
+	TreesPerStep   int             `yaml:"trees_per_step"`  // Trees perturbed per step in collision-free SA
+	TieBreak       bool            `yaml:"tie_break"`       // Accept equal-score moves with probability 0.5 at T <= 0
+	PreferSquare   bool            `yaml:"prefer_square"`   // On equal side, replace best if the box is squarer
+	Debug          bool            `yaml:"debug"`           // Assert solver invariants after every accepted move (slow)
+
+	// If non-empty, rotations snap to the nearest of these angles (degrees), and so do the
+	// angles of the starting layout; empty means continuous
+	AngleSet []float64 `yaml:"angle_set,omitempty"`
+
+	// Below this temperature the advanced SA may also jitter all interior trees at once (0 = never)
+	InteriorJitterT float64 `yaml:"interior_jitter_T"`
+
+	// Warm restarts for the advanced SA: each restart starts from a perturbed copy of the best
+	RestartCount    int     `yaml:"restart_count"`    // Number of warm restarts (0 = single run)
+	RestartStrength float64 `yaml:"restart_strength"` // PerturbAdvanced strength of each restart seed
+
+	// Wall-clock budget per collision-free SA run; the best so far is returned once exceeded (0 = no limit)
+	MaxDuration time.Duration `yaml:"max_duration"`
+
+	// Collision-free SA returns as soon as the square side of its best layout is at or below
+	// this, whatever the Objective, e.g. a known score for n (0 = run the full schedule)
+	TargetScore float64 `yaml:"target_score"`
+
+	// Reheating for the collision-free SA: after ReheatAfter consecutive temperature steps
+	// without a new best, T is reset to Tmax*ReheatFactor and the schedule restarts from there
+	ReheatAfter  int     `yaml:"reheat_after"`  // Stalled temperature steps before reheating (0 = never)
+	ReheatFactor float64 `yaml:"reheat_factor"` // Fraction of Tmax to reheat to
+
+	// Relative selection weight per advanced SA move type (index = move type, NumMoveTypes
+	// entries); 0 disables a type. Empty means uniform, which is the default. The advanced
+	// penalty SA only draws the first NumPenaltyMoveTypes types and ignores the rest.
+	MoveWeights []float64 `yaml:"move_weights,omitempty"`
+
+	// Probability per advanced SA step of trying the replant move (pull the outermost boundary
+	// tree out and spiral it back into the largest gap) instead of a drawn move. It is far
+	// costlier than the other moves, so it is off (0) by default.
+	ReplantProb float64 `yaml:"replant_prob"`
+
+	// Probability per penalty SA step of the reseed move (remove the tree with the largest
+	// overlap and re-add it at the best of a few sampled poses in the bounding box) instead
+	// of a perturbation. 0 disables it, which is the default.
+	ReseedProb float64 `yaml:"reseed_prob"`
+
+	// Plug-in neighbourhood moves for the collision-free SA, one drawn uniformly per step.
+	// Empty means the built-in single-tree perturbation (TreesPerStep trees). Not serialized.
+	Moves []Move `yaml:"-"`
+
+	// OnNewBest, if set, is called by Solve and SolvePenalty with the square side of every
+	// new best valid layout (whatever the Objective) and a clone of it, which the callback may keep. It runs on the solver's
+	// goroutine (several at once under SolveMultiStart or the parallel CLI runs), so a slow
+	// callback throttles the solver. Nil, the default, does nothing. Not serialized.
+	OnNewBest func(score float64, trees []tree.ChristmasTree) `yaml:"-"`
+
+	// Objective minimized by the collision-free SA; the square side is still what Solve
+	// returns and what TargetScore, OnNewBest and OnImprove see. Empty means ObjectiveSquare.
+	Objective Objective `yaml:"objective"`
+
+	// Per-n overrides keyed by n ("12") or an inclusive n range ("10-50"), each holding any
+	// of the keys above; see ConfigForN for how they are applied
+	Overrides map[string]yaml.Node `yaml:"overrides,omitempty"`
 }
 
 // LoadConfig loads SA configuration from a YAML file
@@ -49,12 +124,106 @@ func LoadConfig(path string) (*Config, error) {
 		if err2 := yaml.Unmarshal(data, &config); err2 != nil {
 			return nil, fmt.Errorf("failed to parse config: %w", err)
 		}
+		if err := validateConfig(&config); err != nil {
+			return nil, err
+		}
 		return &config, nil
 	}
 
+	if err := validateConfig(&wrapper.Params); err != nil {
+		return nil, err
+	}
 	return &wrapper.Params, nil
 }
 
+// validateConfig rejects what LoadConfig cannot apply as written: malformed overrides and
+// move weights that do not cover every move type
+func validateConfig(config *Config) error {
+	if err := validateMoveWeights(config.MoveWeights); err != nil {
+		return err
+	}
+	return validateOverrides(config.Overrides)
+}
+
+// validateMoveWeights checks that non-empty move weights have one entry per move type.
+// Weights are indexed by move type, so a list written for another move set would
+// silently weight the wrong moves.
+func validateMoveWeights(weights []float64) error {
+	if len(weights) != 0 && len(weights) != NumMoveTypes {
+		return fmt.Errorf("move_weights has %d entries, want %d (one per move type)", len(weights), NumMoveTypes)
+	}
+	return nil
+}
+
+// SaveConfig writes the configuration to a YAML file in the format read by LoadConfig
+func SaveConfig(path string, config *Config) error {
+	wrapper := struct {
+		Params *Config `yaml:"params"`
+	}{Params: config}
+
+	data, err := yaml.Marshal(&wrapper)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// SnapAngle returns the angle in AngleSet closest to a (measured around the circle),
+// or a unchanged when AngleSet is empty
+func (c *Config) SnapAngle(a float64) float64 {
+	if len(c.AngleSet) == 0 {
+		return a
+	}
+	best, bestDist := a, math.MaxFloat64
+	for _, allowed := range c.AngleSet {
+		d := math.Mod(math.Abs(a-allowed), 360)
+		if d = math.Min(d, 360-d); d < bestDist {
+			best, bestDist = allowed, d
+		}
+	}
+	return best
+}
+
+// snapSeparateIters bounds the SeparateOverlaps passes snapStart spends on overlaps
+// created by snapping
+const snapSeparateIters = 100
+
+// snapStart returns a copy of a solver's starting layout with every angle snapped to
+// AngleSet, so trees the solver never moves end up in the set too. Snapping can make
+// trees overlap: they are pushed apart with SeparateOverlaps and, if that does not
+// suffice, spread out from their centroid until none overlap, so a valid start stays
+// valid. With an empty AngleSet it returns a plain copy.
+func (c *Config) snapStart(trees []tree.ChristmasTree) []tree.ChristmasTree {
+	out := CloneTrees(trees)
+	if len(c.AngleSet) == 0 || len(out) == 0 {
+		return out
+	}
+	for i := range out {
+		out[i].Angle = c.SnapAngle(out[i].Angle)
+	}
+	if !tree.AnyOvl(out) || SeparateOverlaps(out, snapSeparateIters) {
+		return out
+	}
+
+	cx, cy := 0.0, 0.0
+	for i := range out {
+		cx += out[i].X
+		cy += out[i].Y
+	}
+	cx /= float64(len(out))
+	cy /= float64(len(out))
+	for tree.AnyOvl(out) {
+		for i := range out {
+			out[i].X = cx + (out[i].X-cx)*1.05
+			out[i].Y = cy + (out[i].Y-cy)*1.05
+		}
+	}
+	return out
+}
+
 // DefaultConfig returns a default SA configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -69,6 +238,29 @@ func DefaultConfig() *Config {
 		AngleDelta:     15.0,
 		RandomSeed:     0,
 		LogFreq:        10000, // Logging frequency
+		LogInterval:    0,     // Step-based logging by default
+		LogLevel:       LogSummary,
 		OverlapPenalty: 50.0,  // Stronger penalty to enforce valid solutions eventually
+		AspectPenalty:  0,     // No aspect regularization
+		TreesPerStep:   1,     // Classic single-tree moves
+		TieBreak:       false, // Ties follow the plain Metropolis rule
+		PreferSquare:   false, // Keep the first best found among equal sides
+		Debug:          false, // Assertions off for speed
+		AngleSet:       nil,   // Continuous angles
+
+		InteriorJitterT: 0, // Interior jitter move disabled
+
+		RestartCount:    0,   // No warm restarts
+		RestartStrength: 0.3, // Moderate kick: a handful of trees moved
+
+		MaxDuration: 0, // No time limit
+
+		ReheatAfter:  0,   // No reheating
+		ReheatFactor: 0.5, // Reheat to half of Tmax
+
+		MoveWeights: nil, // All move types equally likely
+		Moves:       nil, // Built-in perturbation
+
+		Objective: ObjectiveSquare,
 	}
 }
@@ -0,0 +1,101 @@
+package sa
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestSaveConfigRoundTrip(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 123
+	conf.Cooling = CoolingPolynomial
+	conf.LogInterval = 2 * time.Second
+	conf.TieBreak = true
+	conf.MaxDuration = 90 * time.Second
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := SaveConfig(path, conf); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if !reflect.DeepEqual(conf, loaded) {
+		t.Errorf("Round-tripped config differs:\n got %+v\nwant %+v", loaded, conf)
+	}
+}
+
+func TestLoadConfigRejectsMoveWeightsLength(t *testing.T) {
+	dir := t.TempDir()
+	twelve := "[1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1]"
+	for name, yml := range map[string]string{
+		"params":   "params:\n  move_weights: " + twelve + "\n",
+		"override": "params:\n  overrides:\n    \"10\": { move_weights: " + twelve + " }\n",
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(yml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("%s: expected an error for 12 move weights", name)
+		}
+	}
+
+	path := filepath.Join(dir, "ok.yaml")
+	yml := "params:\n  move_weights: [1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1]\n"
+	if err := os.WriteFile(path, []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(path); err != nil {
+		t.Errorf("LoadConfig rejected %d move weights: %v", NumMoveTypes, err)
+	}
+}
+
+func TestAngleSetRestrictsOutput(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 9
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.LogFreq = 1000000
+	conf.AngleSet = []float64{0, 90, 180, 270}
+
+	if got := conf.SnapAngle(350); got != 0 {
+		t.Errorf("SnapAngle(350) = %v, expected 0 across the wrap-around", got)
+	}
+
+	// Greedy angles are arbitrary, and with a short run some trees are never perturbed:
+	// those must be snapped along with the start
+	initial, _ := greedy.InitializeTreesWithSource(10, nil, 0, rand.New(rand.NewSource(9)))
+	conf.NSteps = 1
+	conf.NStepsPerT = 5
+
+	_, saTrees := NewSimulatedAnnealing(initial, conf).Solve()
+	_, penaltyTrees := NewSimulatedAnnealingPenalty(initial, conf).SolvePenalty()
+	results := map[string][]tree.ChristmasTree{
+		"sa":               saTrees,
+		"advanced":         RunAdvancedSA(initial, conf),
+		"penalty":          penaltyTrees,
+		"advanced-penalty": RunAdvancedSAPenalty(initial, conf),
+	}
+	for name, trees := range results {
+		for _, tr := range trees {
+			if conf.SnapAngle(tr.Angle) != tr.Angle {
+				t.Errorf("%s: angle %v is not in the allowed set", name, tr.Angle)
+			}
+		}
+	}
+	for _, name := range []string{"sa", "advanced"} {
+		if tree.HasCollision(results[name]) {
+			t.Errorf("%s: snapping the start left overlapping trees", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package sa
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// CurvePoint is one improvement of the best score during a solve
+type CurvePoint struct {
+	Step int
+	Best float64
+}
+
+// CurveRecorder collects best-score trajectories for many n from parallel solves.
+// Only improvements are recorded, so it is cheap enough to leave on for full runs.
+type CurveRecorder struct {
+	mu     sync.Mutex
+	curves map[int][]CurvePoint
+}
+
+// NewCurveRecorder creates an empty recorder
+func NewCurveRecorder() *CurveRecorder {
+	return &CurveRecorder{curves: make(map[int][]CurvePoint)}
+}
+
+// Hook returns an OnImprove callback that records into the curve for n
+func (r *CurveRecorder) Hook(n int) func(step int, best float64) {
+	return func(step int, best float64) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.curves[n] = append(r.curves[n], CurvePoint{Step: step, Best: best})
+	}
+}
+
+// Curve returns a copy of the recorded points for n, in recording order
+func (r *CurveRecorder) Curve(n int) []CurvePoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]CurvePoint(nil), r.curves[n]...)
+}
+
+// WriteCSV writes all curves as "n,step,best" rows sorted by n
+func (r *CurveRecorder) WriteCSV(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create curve file: %w", err)
+	}
+	defer f.Close()
+
+	ns := make([]int, 0, len(r.curves))
+	for n := range r.curves {
+		ns = append(ns, n)
+	}
+	sort.Ints(ns)
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"n", "step", "best"}); err != nil {
+		return err
+	}
+	for _, n := range ns {
+		for _, p := range r.curves[n] {
+			row := []string{strconv.Itoa(n), strconv.Itoa(p.Step), strconv.FormatFloat(p.Best, 'f', 6, 64)}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,51 @@
+package sa
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+)
+
+func TestCurveRecorderMonotone(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 7
+	conf.NSteps = 20
+	conf.NStepsPerT = 100
+	conf.LogFreq = 1000000
+
+	initial, _ := greedy.InitializeTrees(6, nil)
+	rec := NewCurveRecorder()
+
+	solver := NewSimulatedAnnealing(initial, conf)
+	solver.OnImprove = rec.Hook(6)
+	solver.Solve()
+
+	curve := rec.Curve(6)
+	if len(curve) == 0 {
+		t.Fatalf("Expected at least one improvement to be recorded")
+	}
+	for k := 1; k < len(curve); k++ {
+		if curve[k].Best > curve[k-1].Best {
+			t.Errorf("Best score increased at point %d: %v -> %v", k, curve[k-1].Best, curve[k].Best)
+		}
+		if curve[k].Step < curve[k-1].Step {
+			t.Errorf("Steps out of order at point %d", k)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "curve.csv")
+	if err := rec.WriteCSV(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(curve)+1 || lines[0] != "n,step,best" {
+		t.Errorf("Unexpected curve CSV:\n%s", data)
+	}
+}
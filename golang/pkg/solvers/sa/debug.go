@@ -0,0 +1,28 @@
+package sa
+
+import (
+	"fmt"
+	"math"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// Debug assertions, enabled with Config.Debug. They recompute state from scratch after
+// every accepted move, so they are far too slow for production runs; use them in tests
+// and when changing a solver's incremental bookkeeping.
+
+// assertNoOverlap panics if any two trees overlap
+func assertNoOverlap(trees []tree.ChristmasTree, step int) {
+	if tree.AnyOvl(trees) {
+		panic(fmt.Sprintf("sa debug: overlap after accepted move at step %d (n=%d)", step, len(trees)))
+	}
+}
+
+// assertOverlapTotal panics if the incrementally tracked overlap drifts from a full recompute
+func assertOverlapTotal(trees []tree.ChristmasTree, incremental float64, step int) {
+	full := tree.CalculateTotalOverlap(trees)
+	if math.Abs(full-incremental) > 1e-6*math.Max(1, full) {
+		panic(fmt.Sprintf("sa debug: incremental overlap %.9f != recomputed %.9f at step %d (n=%d)",
+			incremental, full, step, len(trees)))
+	}
+}
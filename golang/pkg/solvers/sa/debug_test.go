@@ -0,0 +1,22 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+)
+
+func TestDebugAssertionsFullSolve(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 3
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.LogFreq = 1000000
+	conf.Debug = true
+
+	initial, _ := greedy.InitializeTrees(5, nil)
+
+	// Any broken invariant panics and fails the test
+	NewSimulatedAnnealing(initial, conf).Solve()
+	NewSimulatedAnnealingPenalty(initial, conf).SolvePenalty()
+}
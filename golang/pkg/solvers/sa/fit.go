@@ -0,0 +1,127 @@
+package sa
+
+import (
+	"time"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+	"tree-packing-challenge/pkg/tree"
+)
+
+// FitInSquare answers "can n trees fit in a box of the given side?".
+// It runs penalty SA where the score is only the overlap area and any move that
+// takes a tree outside the square [0, side] x [0, side] is rejected.
+// Returns the least-overlapping layout found and whether it is a valid packing.
+func FitInSquare(n int, side float64, config *Config) ([]tree.ChristmasTree, bool) {
+	if n == 0 {
+		return []tree.ChristmasTree{}, true
+	}
+
+	startTime := time.Now()
+	sa := NewBase(nil, config)
+
+	// Random initial placement inside the square (overlaps allowed)
+	cur := make([]tree.ChristmasTree, n)
+	for i := range cur {
+		placed := false
+		for attempt := 0; attempt < 100; attempt++ {
+			cur[i] = tree.ChristmasTree{
+				ID:    i,
+				X:     sa.Rng.Float64() * side,
+				Y:     sa.Rng.Float64() * side,
+				Angle: sa.Config.SnapAngle(sa.Rng.Float64() * 360.0),
+			}
+			if insideSquare(&cur[i], side) {
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			// A single tree does not fit, so no packing can
+			return cur, false
+		}
+	}
+
+	curOverlap := tree.CalculateTotalOverlap(cur)
+	bestOverlap := curOverlap
+	best := CloneTrees(cur)
+	T := sa.Config.Tmax
+	logGate := newLogGate(sa.Config)
+
+	for step := 0; step < sa.Config.NSteps; step++ {
+		for step1 := 0; step1 < sa.Config.NStepsPerT; step1++ {
+			if curOverlap < 1e-9 && !tree.HasCollision(cur) {
+				sa.Config.logf(LogSummary, "[Fit n=%3d] Found valid packing in side %.5f\n", n, side)
+				return cur, true
+			}
+
+			i := sa.Rng.Intn(n)
+			oldTreeOverlap := tree.CalculateTreeOverlap(cur, i)
+			oldX, oldY, oldAngle := sa.PerturbTree(&cur[i])
+
+			// Hard boundary constraint
+			if !insideSquare(&cur[i], side) {
+				sa.RestoreTree(&cur[i], oldX, oldY, oldAngle)
+				continue
+			}
+
+			newOverlap := curOverlap - oldTreeOverlap + tree.CalculateTreeOverlap(cur, i)
+			delta := sa.Config.OverlapPenalty * (newOverlap - curOverlap)
+
+			if sa.Accept(delta, T) {
+				curOverlap = newOverlap
+				if curOverlap < bestOverlap {
+					bestOverlap = curOverlap
+					best = CloneTrees(cur)
+				}
+			} else {
+				sa.RestoreTree(&cur[i], oldX, oldY, oldAngle)
+			}
+
+			currentStep := step*sa.Config.NStepsPerT + step1
+			if logGate.Due(currentStep) {
+				elapsed := FormatDuration(time.Since(startTime))
+				sa.Config.logf(LogVerbose, "[Fit n=%3d] T: %.3e  Step: %6d  Overlap: %6.4f  Best: %6.4f  Time: %s\n",
+					n, T, currentStep, curOverlap, bestOverlap, elapsed)
+			}
+		}
+
+		T = sa.CoolTemperature(T, step)
+	}
+
+	if curOverlap < 1e-9 && !tree.HasCollision(cur) {
+		return cur, true
+	}
+	return best, !tree.HasCollision(best)
+}
+
+// MaxTreesInSquare estimates how many trees fit in a square of the given side.
+// Trees are added greedily with greedy.AddTree until no position is left; when
+// config is non-nil, FitInSquare is then tried with one more tree at a time
+// until it fails. The result is a lower bound on the true maximum.
+func MaxTreesInSquare(side float64, config *Config) int {
+	var trees []tree.ChristmasTree
+	for {
+		var ok bool
+		trees, ok = greedy.AddTree(trees, side)
+		if !ok {
+			break
+		}
+	}
+
+	n := len(trees)
+	if config == nil || n == 0 {
+		return n
+	}
+	for {
+		if _, ok := FitInSquare(n+1, side, config); !ok {
+			return n
+		}
+		n++
+	}
+}
+
+// insideSquare reports whether the tree lies within [0, side] x [0, side]
+func insideSquare(t *tree.ChristmasTree, side float64) bool {
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	return minX >= 0 && minY >= 0 && maxX <= side && maxY <= side
+}
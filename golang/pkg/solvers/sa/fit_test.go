@@ -0,0 +1,51 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestFitInSquare(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 42
+	conf.NSteps = 20
+	conf.NStepsPerT = 50
+	conf.Tmax = 1.0
+	conf.Tmin = 0.001
+	conf.PositionDelta = 0.2
+
+	// Two trees easily fit in a 3x3 box
+	trees, ok := FitInSquare(2, 3.0, conf)
+	if !ok {
+		t.Fatalf("Expected 2 trees to fit in side 3")
+	}
+	if len(trees) != 2 {
+		t.Errorf("Expected 2 trees, got %d", len(trees))
+	}
+	if tree.HasCollision(trees) {
+		t.Errorf("FitInSquare reported success with colliding trees")
+	}
+	for i := range trees {
+		if !insideSquare(&trees[i], 3.0) {
+			t.Errorf("Tree %d lies outside the square", i)
+		}
+	}
+
+	// Ten trees cannot fit in a 1x1 box
+	if _, ok := FitInSquare(10, 1.0, conf); ok {
+		t.Errorf("Expected 10 trees not to fit in side 1")
+	}
+}
+
+func TestMaxTreesInSquare(t *testing.T) {
+	small := MaxTreesInSquare(1.5, nil)
+	large := MaxTreesInSquare(2.5, nil)
+
+	if small < 1 {
+		t.Errorf("Expected at least one tree in side 1.5, got %d", small)
+	}
+	if large < small {
+		t.Errorf("Larger square admits fewer trees: %d < %d", large, small)
+	}
+}
@@ -0,0 +1,52 @@
+package sa
+
+import (
+	"context"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// foreverPassSteps caps the outer temperature steps ImproveForever spends on one n per pass
+const foreverPassSteps = 20
+
+// ImproveForever cycles through every group in best, spending one short RunAdvancedSA
+// budget (config with its per-n overrides, at most foreverPassSteps outer steps) on each and keeping only valid improvements, so
+// a stored group never gets worse. After each full pass onPass is called (e.g. to write a
+// checkpoint). It stops when ctx is cancelled, cutting the running solve short and keeping
+// its best so far if valid and better, or after maxPasses passes (0 means no limit), and
+// returns the number of completed passes. The best map is updated in place.
+func ImproveForever(ctx context.Context, best map[int][]tree.ChristmasTree, config *Config, maxPasses int, onPass func(pass int)) int {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	ns := tree.SortedGroups(best)
+
+	pass := 0
+	for maxPasses == 0 || pass < maxPasses {
+		improved := 0
+		for _, n := range ns {
+			if ctx.Err() != nil {
+				return pass
+			}
+
+			// Vary the seed per pass and n so every pass explores differently
+			passConfig := *ConfigForN(config, n)
+			passConfig.RandomSeed += int64(pass)*100003 + int64(n)
+			passConfig.NSteps = min(passConfig.NSteps, foreverPassSteps)
+
+			candidate := RunAdvancedSAContext(ctx, best[n], &passConfig)
+			if len(candidate) == len(best[n]) && !tree.HasCollision(candidate) &&
+				tree.Side(candidate) < tree.Side(best[n])-1e-12 {
+				best[n] = candidate
+				improved++
+			}
+		}
+
+		pass++
+		config.logf(LogSummary, "[Forever] Pass %d done: %d/%d groups improved\n", pass, improved, len(ns))
+		if onPass != nil {
+			onPass(pass)
+		}
+	}
+	return pass
+}
@@ -0,0 +1,61 @@
+package sa
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestImproveForever(t *testing.T) {
+	best := map[int][]tree.ChristmasTree{
+		2: {{ID: 0, X: 0, Y: 0, Angle: 0}, {ID: 1, X: 2, Y: 0, Angle: 0}},
+		3: {{ID: 0, X: 0, Y: 0, Angle: 0}, {ID: 1, X: 2, Y: 0, Angle: 0}, {ID: 2, X: 0, Y: 2, Angle: 0}},
+	}
+
+	conf := DefaultConfig()
+	conf.RandomSeed = 42
+	conf.NSteps = 5
+	conf.NStepsPerT = 20
+
+	sides := map[int]float64{}
+	for n, trees := range best {
+		sides[n] = tree.Side(trees)
+	}
+
+	passes := ImproveForever(context.Background(), best, conf, 3, func(pass int) {
+		for n, trees := range best {
+			side := tree.Side(trees)
+			if side > sides[n]+1e-12 {
+				t.Errorf("Pass %d worsened n=%d: %.5f > %.5f", pass, n, side, sides[n])
+			}
+			if len(trees) != n || tree.HasCollision(trees) {
+				t.Errorf("Pass %d stored an invalid group for n=%d", pass, n)
+			}
+			sides[n] = side
+		}
+	})
+
+	if passes != 3 {
+		t.Errorf("Expected 3 passes, got %d", passes)
+	}
+
+	// A cancelled context stops before doing any work
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if passes := ImproveForever(ctx, best, conf, 0, nil); passes != 0 {
+		t.Errorf("Expected 0 passes with cancelled context, got %d", passes)
+	}
+
+	// Cancelling mid-pass cuts the running solve short, however large its budget
+	conf.NSteps = 1000000
+	conf.NStepsPerT = 1000
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	ImproveForever(ctx, best, conf, 0, nil)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("ImproveForever took %s to stop after cancellation", elapsed)
+	}
+}
@@ -0,0 +1,77 @@
+package sa
+
+import (
+	"fmt"
+	"time"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// LogLevel controls how much the SA solvers print
+type LogLevel string
+
+const (
+	LogSilent  LogLevel = "silent"  // Nothing
+	LogSummary LogLevel = "summary" // One-off events: cancellation, time budget, reheats
+	LogVerbose LogLevel = "verbose" // Also new bests and the periodic status lines
+)
+
+// rank orders the levels; empty and unknown values count as LogSummary
+func (l LogLevel) rank() int {
+	switch l {
+	case LogSilent:
+		return 0
+	case LogVerbose:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// logf prints the message when the configured LogLevel includes level
+func (c *Config) logf(level LogLevel, format string, args ...any) {
+	if c.LogLevel.rank() >= level.rank() {
+		fmt.Printf(format, args...)
+	}
+}
+
+// logEstimate prints, in verbose mode, the density-based side estimate for n next to the
+// side the solver starts from, as a yardstick for how far the run can still go
+func (c *Config) logEstimate(n int, start float64) {
+	rows, cols := tree.EstimateGrid(n)
+	c.logf(LogVerbose, "[n=%3d] Estimated side %.5f at density %.2f (~%d rows x %d cols), starting from %.5f\n",
+		n, tree.EstimateSide(n), tree.EstimateDensity, rows, cols, start)
+}
+
+// logGate decides when a solver loop should print a status line.
+// By default it fires every LogFreq steps; when LogInterval is set it fires
+// at most once per interval of wall-clock time instead, so log volume does not
+// depend on n or on how fast the machine iterates.
+type logGate struct {
+	freq     int
+	interval time.Duration
+	now      func() time.Time
+	last     time.Time
+}
+
+// newLogGate creates a log gate from the config using the real clock
+func newLogGate(config *Config) *logGate {
+	return &logGate{
+		freq:     config.LogFreq,
+		interval: config.LogInterval,
+		now:      time.Now,
+	}
+}
+
+// Due reports whether the status line for the given step should be printed
+func (g *logGate) Due(step int) bool {
+	if g.interval > 0 {
+		t := g.now()
+		if t.Sub(g.last) < g.interval {
+			return false
+		}
+		g.last = t
+		return true
+	}
+	return g.freq > 0 && step%g.freq == 0
+}
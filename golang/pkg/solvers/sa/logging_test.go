@@ -0,0 +1,91 @@
+package sa
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureStdout returns what f prints to stdout
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	f()
+	os.Stdout = orig
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestLogLevel(t *testing.T) {
+	cases := []struct {
+		level            LogLevel
+		summary, verbose bool
+	}{
+		{LogSilent, false, false},
+		{"", true, false},
+		{LogSummary, true, false},
+		{LogVerbose, true, true},
+	}
+	for _, c := range cases {
+		conf := &Config{LogLevel: c.level}
+		out := captureStdout(t, func() {
+			conf.logf(LogSummary, "summary line\n")
+			conf.logf(LogVerbose, "verbose line\n")
+		})
+		if strings.Contains(out, "summary line") != c.summary || strings.Contains(out, "verbose line") != c.verbose {
+			t.Errorf("Level %q printed %q", c.level, out)
+		}
+	}
+}
+
+func TestLogGateInterval(t *testing.T) {
+	clock := time.Unix(0, 0)
+	gate := &logGate{
+		freq:     1, // Would fire every step if the interval were ignored
+		interval: 2 * time.Second,
+		now:      func() time.Time { return clock },
+	}
+
+	// Simulate 100 steps, each taking 150ms
+	var fired []time.Time
+	for step := 0; step < 100; step++ {
+		if gate.Due(step) {
+			fired = append(fired, clock)
+		}
+		clock = clock.Add(150 * time.Millisecond)
+	}
+
+	if len(fired) < 2 {
+		t.Fatalf("Expected several log lines, got %d", len(fired))
+	}
+	for k := 1; k < len(fired); k++ {
+		gap := fired[k].Sub(fired[k-1])
+		if gap < 2*time.Second || gap > 2*time.Second+150*time.Millisecond {
+			t.Errorf("Log lines %d and %d are %v apart, want ~2s", k-1, k, gap)
+		}
+	}
+}
+
+func TestLogGateSteps(t *testing.T) {
+	gate := newLogGate(&Config{LogFreq: 10})
+	count := 0
+	for step := 0; step < 100; step++ {
+		if gate.Due(step) {
+			count++
+		}
+	}
+	if count != 10 {
+		t.Errorf("Expected 10 step-based log lines, got %d", count)
+	}
+}
@@ -0,0 +1,36 @@
+package sa
+
+import "tree-packing-challenge/pkg/tree"
+
+// Move is a neighbourhood move for the collision-free SA (see Config.Moves). Apply perturbs
+// trees in place and returns a function that restores them exactly; the solver calls it
+// when the result collides or is rejected by the Metropolis rule. Moves must not change
+// the number of trees.
+type Move interface {
+	Apply(trees []tree.ChristmasTree, rng RNG) (undo func())
+}
+
+// MoveFunc adapts an ordinary function to the Move interface
+type MoveFunc func(trees []tree.ChristmasTree, rng RNG) (undo func())
+
+// Apply calls f(trees, rng)
+func (f MoveFunc) Apply(trees []tree.ChristmasTree, rng RNG) func() {
+	return f(trees, rng)
+}
+
+// PerturbMove is the classic single-tree move as a Move: one random tree is shifted by up
+// to PositionDelta in each axis and rotated by a Gaussian angle of AngleDelta degrees,
+// snapped to AngleSet, exactly like Base.PerturbTree
+type PerturbMove struct {
+	Config *Config
+}
+
+// Apply perturbs one random tree
+func (m PerturbMove) Apply(trees []tree.ChristmasTree, rng RNG) func() {
+	b := &Base{Config: m.Config, Rng: rng}
+	i := rng.Intn(len(trees))
+	oldX, oldY, oldAngle := b.PerturbTree(&trees[i])
+	return func() {
+		b.RestoreTree(&trees[i], oldX, oldY, oldAngle)
+	}
+}
@@ -0,0 +1,68 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestSolveWithPluginMoves(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 1.5, Y: 0, Angle: 0})
+	}
+
+	// Squeeze pulls every tree 5% toward the origin in one move
+	applied, undone := 0, 0
+	squeeze := MoveFunc(func(ts []tree.ChristmasTree, _ RNG) func() {
+		applied++
+		old := CloneTrees(ts)
+		for i := range ts {
+			ts[i].X *= 0.95
+		}
+		return func() {
+			undone++
+			copy(ts, old)
+		}
+	})
+
+	conf := DefaultConfig()
+	conf.RandomSeed = 2
+	conf.NSteps = 20
+	conf.NStepsPerT = 20
+	conf.LogFreq = 1000000
+	conf.Moves = []Move{squeeze, PerturbMove{Config: conf}}
+
+	score, best := NewSimulatedAnnealing(trees, conf).Solve()
+	if applied == 0 || undone == 0 {
+		t.Fatalf("Expected the plug-in move to be applied and undone, got %d applied, %d undone", applied, undone)
+	}
+	if tree.HasCollision(best) || len(best) != len(trees) {
+		t.Fatalf("Plug-in moves produced an invalid layout")
+	}
+	if score >= tree.CalculateSideLength(trees) {
+		t.Errorf("Squeezing a spread-out row should improve the side, got %.5f", score)
+	}
+}
+
+func TestPerturbMoveUndo(t *testing.T) {
+	trees := []tree.ChristmasTree{{ID: 0, X: 1, Y: 2, Angle: 30}, {ID: 1, X: 5, Y: 5, Angle: 90}}
+	orig := CloneTrees(trees)
+	undo := PerturbMove{Config: DefaultConfig()}.Apply(trees, NewRNG(1))
+
+	changed := 0
+	for i := range trees {
+		if !trees[i].Equal(&orig[i]) {
+			changed++
+		}
+	}
+	if changed != 1 {
+		t.Fatalf("Expected exactly one tree perturbed, got %d", changed)
+	}
+	undo()
+	for i := range trees {
+		if !trees[i].Equal(&orig[i]) {
+			t.Errorf("Tree %d not restored by undo: %+v", i, trees[i])
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package sa
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// startSlots bounds the multi-start solves running at once across all callers, so that
+// calls made from an already parallel loop (one per n) do not multiply into NumCPU² solves
+var startSlots = make(chan struct{}, runtime.NumCPU())
+
+// SolveMultiStart runs starts independent collision-free SA solves from trees and returns
+// the best valid result; see SolveMultiStartContext
+func SolveMultiStart(trees []tree.ChristmasTree, cfg *Config, starts int) (float64, []tree.ChristmasTree) {
+	return SolveMultiStartContext(context.Background(), trees, cfg, starts)
+}
+
+// SolveMultiStartContext runs starts independent SimulatedAnnealing solves from trees. Start
+// k is seeded with cfg.RandomSeed + k*7919, so start 0 matches a single Solve. At most
+// runtime.NumCPU() starts run at once, counting those of concurrent calls. It returns the
+// lowest valid score and its layout. Ties go to the lower start, so the result does not
+// depend on scheduling. Once ctx is done, the running solves return their best so far and
+// no further starts begin.
+func SolveMultiStartContext(ctx context.Context, trees []tree.ChristmasTree, cfg *Config, starts int) (float64, []tree.ChristmasTree) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	starts = max(starts, 1)
+
+	type result struct {
+		score float64
+		trees []tree.ChristmasTree
+	}
+	results := make([]result, starts)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(starts, runtime.NumCPU()) {
+		wg.Go(func() {
+			for k := range jobs {
+				startConfig := *cfg
+				startConfig.RandomSeed = cfg.RandomSeed + int64(k)*7919
+				startSlots <- struct{}{}
+				score, best := NewSimulatedAnnealing(CloneTrees(trees), &startConfig).SolveContext(ctx)
+				<-startSlots
+				results[k] = result{score, best}
+			}
+		})
+	}
+	for k := 0; k < starts; k++ {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Prefer valid layouts; an invalid start can only yield invalid ones
+	bestK, bestValid := -1, false
+	for k, r := range results {
+		if r.trees == nil {
+			continue
+		}
+		valid := !tree.HasCollision(r.trees)
+		if bestK < 0 || (valid && !bestValid) || (valid == bestValid && r.score < results[bestK].score) {
+			bestK, bestValid = k, valid
+		}
+	}
+	if bestK < 0 {
+		return tree.CalculateSideLength(trees), CloneTrees(trees)
+	}
+	cfg.logf(LogSummary, "[n=%3d] Multi-start: best of %d runs is start %d with %.5f\n",
+		len(trees), starts, bestK, results[bestK].score)
+	return results[bestK].score, results[bestK].trees
+}
@@ -0,0 +1,37 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestSolveMultiStart(t *testing.T) {
+	var trees []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.8, Y: 0, Angle: 0})
+	}
+	conf := DefaultConfig()
+	conf.RandomSeed = 9
+	conf.NSteps = 10
+	conf.NStepsPerT = 100
+	conf.LogLevel = LogSilent
+
+	// Start 0 keeps the config's seed, so a single start is a plain Solve
+	single, _ := NewSimulatedAnnealing(trees, conf).Solve()
+	if score, _ := SolveMultiStart(trees, conf, 1); score != single {
+		t.Errorf("One start scored %.6f, plain Solve %.6f", score, single)
+	}
+
+	// More starts than CPUs still cover every start, and can only do better
+	score, best := SolveMultiStart(trees, conf, 12)
+	if score > single {
+		t.Errorf("Best of 12 starts %.6f is worse than start 0 alone %.6f", score, single)
+	}
+	if len(best) != len(trees) || tree.HasCollision(best) {
+		t.Fatalf("Multi-start returned an invalid layout")
+	}
+	if again, _ := SolveMultiStart(trees, conf, 12); again != score {
+		t.Errorf("Multi-start is not reproducible: %.6f then %.6f", score, again)
+	}
+}
@@ -0,0 +1,85 @@
+package sa
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// nRange is the inclusive range of n an override key applies to
+type nRange struct{ lo, hi int }
+
+// parseNRange parses an override key: a single n ("12") or an inclusive range ("10-50")
+func parseNRange(key string) (nRange, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(key), "-")
+	if !isRange {
+		hi = lo
+	}
+	a, errA := strconv.Atoi(strings.TrimSpace(lo))
+	b, errB := strconv.Atoi(strings.TrimSpace(hi))
+	if errA != nil || errB != nil || a < 1 || b < a {
+		return nRange{}, fmt.Errorf("invalid override key %q (want n or lo-hi)", key)
+	}
+	return nRange{a, b}, nil
+}
+
+// validateOverrides checks that every override key parses and its body decodes as a Config
+func validateOverrides(overrides map[string]yaml.Node) error {
+	for key, node := range overrides {
+		if _, err := parseNRange(key); err != nil {
+			return err
+		}
+		var probe Config
+		if err := node.Decode(&probe); err != nil {
+			return fmt.Errorf("override %q: %w", key, err)
+		}
+		if err := validateMoveWeights(probe.MoveWeights); err != nil {
+			return fmt.Errorf("override %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ConfigForN returns a copy of base with the Overrides whose key covers n applied on top.
+// Only the fields an override sets change. When several keys cover n the more specific one
+// wins: wider ranges are applied first and narrower ones (down to a single n) over them;
+// equally wide ranges are applied in ascending order of their start. Invalid keys are
+// skipped (LoadConfig rejects them). The returned copy shares slices with base.
+func ConfigForN(base *Config, n int) *Config {
+	if base == nil {
+		base = DefaultConfig()
+	}
+	c := *base
+	if len(base.Overrides) == 0 {
+		return &c
+	}
+
+	type match struct {
+		r    nRange
+		node yaml.Node
+	}
+	var matches []match
+	for key, node := range base.Overrides {
+		r, err := parseNRange(key)
+		if err != nil || n < r.lo || n > r.hi {
+			continue
+		}
+		matches = append(matches, match{r, node})
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		wa, wb := matches[a].r.hi-matches[a].r.lo, matches[b].r.hi-matches[b].r.lo
+		if wa != wb {
+			return wa > wb
+		}
+		return matches[a].r.lo < matches[b].r.lo
+	})
+	for _, m := range matches {
+		// Decoding onto the copy leaves every field the override does not mention untouched
+		m.node.Decode(&c)
+	}
+	c.Overrides = base.Overrides
+	return &c
+}
@@ -0,0 +1,55 @@
+package sa
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigForN(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `params:
+  Tmax: 10
+  nsteps: 500
+  nsteps_per_T: 100
+  overrides:
+    1-50: { nsteps: 50, Tmax: 2 }
+    20-30: { nsteps: 80 }
+    25: { nsteps_per_T: 7 }
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	for _, tc := range []struct {
+		n                  int
+		nSteps, nStepsPerT int
+		tmax               float64
+	}{
+		{100, 500, 100, 10}, // No override
+		{10, 50, 100, 2},    // Wide range only
+		{22, 80, 100, 2},    // Narrower range over the wide one, Tmax still from the wide one
+		{25, 80, 7, 2},      // A single n over both ranges
+	} {
+		c := ConfigForN(base, tc.n)
+		if c.NSteps != tc.nSteps || c.NStepsPerT != tc.nStepsPerT || c.Tmax != tc.tmax {
+			t.Errorf("n=%d: got nsteps %d, nsteps_per_T %d, Tmax %v; want %d, %d, %v",
+				tc.n, c.NSteps, c.NStepsPerT, c.Tmax, tc.nSteps, tc.nStepsPerT, tc.tmax)
+		}
+	}
+	if base.NSteps != 500 || base.Tmax != 10 {
+		t.Errorf("ConfigForN modified the base config")
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("params:\n  overrides:\n    50-10: { nsteps: 1 }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(bad); err == nil {
+		t.Errorf("Expected an error for an empty n range")
+	}
+}
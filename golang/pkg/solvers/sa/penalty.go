@@ -1,10 +1,12 @@
 package sa
 
 import (
-	"fmt"
+	"context"
 	"math"
 	"time"
 
+	"github.com/tidwall/rtree"
+
 	"tree-packing-challenge/pkg/tree"
 )
 
@@ -21,79 +23,198 @@ func NewSimulatedAnnealingPenalty(trees []tree.ChristmasTree, config *Config) *S
 }
 
 // SolvePenalty runs the penalty-based simulated annealing algorithm
-// All moves are allowed but penalized by overlap area
+// All moves are allowed but penalized by overlap area (and by aspect skew if AspectPenalty > 0)
+// With ReseedProb > 0 a step may instead Reseed the most overlapping tree
 // Uses incremental overlap calculation for efficiency (only recalculates for the perturbed tree)
 func (sa *SimulatedAnnealingPenalty) SolvePenalty() (float64, []tree.ChristmasTree) {
+	return sa.SolvePenaltyContext(context.Background())
+}
+
+// SolvePenaltyContext runs SolvePenalty until ctx is done; ctx is checked at the top of
+// each temperature step and the best valid solution so far is returned on cancellation
+func (sa *SimulatedAnnealingPenalty) SolvePenaltyContext(ctx context.Context) (float64, []tree.ChristmasTree) {
 	startTime := time.Now()
 
 	T := sa.Config.Tmax
-	currentTrees := CloneTrees(sa.Trees)
+	currentTrees := sa.Config.snapStart(sa.Trees)
 
 	// Calculate initial state
-	currentBBox := tree.CalculateSideLength(currentTrees)
+	currentBBox, currentSkew := boxTerms(currentTrees)
 	currentOverlap := tree.CalculateTotalOverlap(currentTrees)
-	currentScore := currentBBox + sa.Config.OverlapPenalty*currentOverlap
+	currentScore := currentBBox + sa.Config.OverlapPenalty*currentOverlap + sa.Config.AspectPenalty*currentSkew
+	sa.Config.logEstimate(len(currentTrees), currentBBox)
 
 	bestBBoxScore := currentBBox
 	bestScore := currentScore
 	bestTrees := CloneTrees(currentTrees)
+	logGate := newLogGate(sa.Config)
 
 	// Initial best valid check
 	if currentOverlap == 0 {
 		bestScore = currentBBox
 	}
 
+	// Bounding boxes of the current trees; only an accepted move changes one
+	index := tree.BuildRTree(currentTrees)
+
+	// Track the best valid (collision-free) solution
+	recordBest := func(step int) {
+		if currentOverlap == 0 && betterBest(currentBBox, bestBBoxScore, currentTrees, bestTrees, sa.Config.PreferSquare) {
+			bestBBoxScore = currentBBox
+			bestScore = currentBBox
+			bestTrees = CloneTrees(currentTrees)
+			sa.Config.logf(LogVerbose, "[n=%3d] NEW BEST SCORE (valid): %8.5f\n", len(currentTrees), bestBBoxScore)
+			sa.notifyImprove(step, bestBBoxScore, bestTrees)
+		}
+	}
+
 	for step := 0; step < sa.Config.NSteps; step++ {
+		if ctx.Err() != nil {
+			sa.Config.logf(LogSummary, "[n=%3d] Cancelled at temperature step %d, best %.5f\n", len(currentTrees), step, bestBBoxScore)
+			break
+		}
 		for step1 := 0; step1 < sa.Config.NStepsPerT; step1++ {
+			// Calculate global step for consistent logging
+			currentStep := step*sa.Config.NStepsPerT + step1
+
+			if sa.Config.ReseedProb > 0 && sa.Rng.Float64() < sa.Config.ReseedProb {
+				next, removed, added, ok := Reseed(currentTrees, index, sa.Rng, reseedSamples, sa.Config)
+				if ok {
+					newBBox, newSkew := boxTerms(next)
+					newOverlap := currentOverlap - removed + added
+					newScore := newBBox + sa.Config.OverlapPenalty*newOverlap + sa.Config.AspectPenalty*newSkew
+					if sa.Accept(newScore-currentScore, T) {
+						currentTrees, currentScore, currentBBox, currentOverlap = next, newScore, newBBox, newOverlap
+						// The reseeded tree moved to the end, so every index after it shifted
+						index = tree.BuildRTree(currentTrees)
+						if sa.Config.Debug {
+							assertOverlapTotal(currentTrees, currentOverlap, currentStep)
+						}
+						recordBest(currentStep)
+					}
+					continue
+				}
+			}
+
 			// Select random tree to perturb
 			i := sa.Rng.Intn(len(currentTrees))
 
 			// Calculate overlap BEFORE perturbation (only for tree i)
-			oldTreeOverlap := tree.CalculateTreeOverlap(currentTrees, i)
+			oldTreeOverlap := tree.CalculateTreeOverlapRTree(currentTrees, i, index)
+			oldMinX, oldMinY, oldMaxX, oldMaxY := currentTrees[i].GetBoundingBox()
 
 			// Perturb the tree
 			oldX, oldY, oldAngle := sa.PerturbTree(&currentTrees[i])
 
 			// Calculate overlap AFTER perturbation (only for tree i)
-			newTreeOverlap := tree.CalculateTreeOverlap(currentTrees, i)
+			newTreeOverlap := tree.CalculateTreeOverlapRTree(currentTrees, i, index)
 
 			// Calculate new bounding box
-			newBBox := tree.CalculateSideLength(currentTrees)
+			newBBox, newSkew := boxTerms(currentTrees)
 
 			// Incremental overlap update: totalOverlap - oldContribution + newContribution
 			newOverlap := currentOverlap - oldTreeOverlap + newTreeOverlap
-			newScore := newBBox + sa.Config.OverlapPenalty*newOverlap
+			newScore := newBBox + sa.Config.OverlapPenalty*newOverlap + sa.Config.AspectPenalty*newSkew
 
 			delta := newScore - currentScore
 
 			// Accept if better or with probability exp(-delta/T)
-			if delta < 0 || sa.Rng.Float64() < math.Exp(-delta/T) {
+			if sa.Accept(delta, T) {
 				currentScore = newScore
 				currentBBox = newBBox
 				currentOverlap = newOverlap
-
-				// Track the best valid (collision-free) solution
-				if newOverlap == 0 && newBBox < bestBBoxScore {
-					bestBBoxScore = newBBox
-					bestScore = newBBox
-					bestTrees = CloneTrees(currentTrees)
-					fmt.Printf("[n=%3d] NEW BEST SCORE (valid): %8.5f\n", len(currentTrees), bestBBoxScore)
+				minX, minY, maxX, maxY := currentTrees[i].GetBoundingBox()
+				index.Replace([2]float64{oldMinX, oldMinY}, [2]float64{oldMaxX, oldMaxY}, i,
+					[2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+				if sa.Config.Debug {
+					assertOverlapTotal(currentTrees, currentOverlap, currentStep)
 				}
+				recordBest(currentStep)
 			} else {
 				sa.RestoreTree(&currentTrees[i], oldX, oldY, oldAngle)
 			}
 
-			// Calculate global step for consistent logging
-			currentStep := step*sa.Config.NStepsPerT + step1
-			if currentStep%sa.Config.LogFreq == 0 {
+			if logGate.Due(currentStep) {
 				elapsed := FormatDuration(time.Since(startTime))
-				fmt.Printf("[n=%3d] T: %.3e  Step: %6d  Score: %8.5f  Overlap: %6.4f  Best: %8.5f  Time: %s\n",
-					len(currentTrees), T, currentStep, currentScore, currentOverlap, bestBBoxScore, elapsed)
+				sa.Config.logf(LogVerbose, "[n=%3d] T: %.3e  Step: %6d  Score: %8.5f  Overlap: %6.4f (%d pairs)  Best: %8.5f  Time: %s\n",
+					len(currentTrees), T, currentStep, currentScore, currentOverlap, tree.CountOverlappingPairs(currentTrees), bestBBoxScore, elapsed)
 			}
 		}
 
 		T = sa.CoolTemperature(T, step)
 	}
 
+	// No valid layout was found: try pushing the overlapping pairs apart, and failing that
+	// point at the pair that is hardest to separate
+	if tree.HasCollision(bestTrees) {
+		separated := CloneTrees(bestTrees)
+		if SeparateOverlaps(separated, 100) {
+			sa.Config.logf(LogSummary, "[n=%3d] No valid layout found, separated overlaps of the best to side %.5f\n",
+				len(separated), tree.Side(separated))
+			return tree.Side(separated), separated
+		}
+	}
+	if i, j, area := tree.DeepestOverlap(bestTrees); i >= 0 {
+		sa.Config.logf(LogSummary, "[n=%3d] Best layout still overlaps; deepest pair: trees %d and %d (IDs %d, %d), area %.6f\n",
+			len(bestTrees), i, j, bestTrees[i].ID, bestTrees[j].ID, area)
+	}
+
 	return bestScore, bestTrees
 }
+
+// reseedSamples is the number of random poses the reseed move of SolvePenalty tries
+const reseedSamples = 64
+
+// Reseed removes the tree with the largest overlap from trees with RemoveTree and re-adds it
+// at the lowest-overlap of samples random poses (angles snapped to AngleSet) inside the
+// bounding box of the others, stopping early at a free one. A tree wedged deep into its
+// neighbours can dominate the penalty while no small perturbation gets it out; this lets it
+// jump. index must hold the current trees' bounding boxes. It returns the new layout, with
+// the tree last and the count unchanged, and the tree's overlap area before and after, so
+// the caller can update its total; ok is false if no tree overlaps. trees is not modified.
+func Reseed(trees []tree.ChristmasTree, index *rtree.RTree, rng RNG, samples int, config *Config) (next []tree.ChristmasTree, removed, added float64, ok bool) {
+	if len(trees) < 2 {
+		return trees, 0, 0, false
+	}
+	worst := -1
+	for i := range trees {
+		if o := tree.CalculateTreeOverlapRTree(trees, i, index); o > removed {
+			worst, removed = i, o
+		}
+	}
+	if worst < 0 {
+		return trees, 0, 0, false
+	}
+
+	rest := tree.RemoveTree(trees, worst)
+	restIndex := tree.BuildRTree(rest)
+	minX, minY, maxX, maxY := tree.GetBounds(rest)
+	next = append(rest, trees[worst])
+	last := len(next) - 1
+
+	// Keep the original pose unless a sample overlaps less
+	t := &next[last]
+	bestX, bestY, bestAngle := t.X, t.Y, t.Angle
+	added = removed
+	for k := 0; k < samples && added > 0; k++ {
+		t.X = minX + rng.Float64()*(maxX-minX)
+		t.Y = minY + rng.Float64()*(maxY-minY)
+		t.Angle = config.SnapAngle(rng.Float64() * 360)
+		if o := tree.CalculateTreeOverlapRTree(next, last, restIndex); o < added {
+			bestX, bestY, bestAngle, added = t.X, t.Y, t.Angle, o
+		}
+	}
+	t.X, t.Y, t.Angle = bestX, bestY, bestAngle
+	return next, removed, added, true
+}
+
+// boxTerms returns the bounding box side length and its aspect skew |width - height|
+func boxTerms(trees []tree.ChristmasTree) (side, skew float64) {
+	if len(trees) == 0 {
+		return 0, 0
+	}
+	minX, minY, maxX, maxY := tree.GetBounds(trees)
+	width := maxX - minX
+	height := maxY - minY
+	return math.Max(width, height), math.Abs(width - height)
+}
@@ -0,0 +1,88 @@
+package sa
+
+import (
+	"math"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestAspectPenalty(t *testing.T) {
+	// A single row of trees is as skewed as a layout gets
+	var trees []tree.ChristmasTree
+	for i := 0; i < 6; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 1.0, Y: 0, Angle: 0})
+	}
+
+	run := func(aspect float64) float64 {
+		conf := DefaultConfig()
+		conf.RandomSeed = 42
+		conf.NSteps = 50
+		conf.NStepsPerT = 100
+		conf.Tmax = 0.5
+		conf.Tmin = 1e-4
+		conf.LogFreq = 1000000
+		conf.AspectPenalty = aspect
+		_, best := NewSimulatedAnnealingPenalty(trees, conf).SolvePenalty()
+		_, skew := boxTerms(best)
+		return skew
+	}
+
+	plain := run(0)
+	regularized := run(2.0)
+	if regularized >= plain {
+		t.Errorf("Aspect penalty did not reduce skew: got %.4f, want < %.4f", regularized, plain)
+	}
+}
+
+func TestReseed(t *testing.T) {
+	// Tree 2 sits right on top of tree 1; the others are spread out around them
+	trees := []tree.ChristmasTree{
+		{ID: 0, X: 0, Y: 0},
+		{ID: 1, X: 3, Y: 0},
+		{ID: 2, X: 3.05, Y: 0.05},
+		{ID: 3, X: 0, Y: 3},
+		{ID: 4, X: 3, Y: 3},
+	}
+	index := tree.BuildRTree(trees)
+	before := tree.CalculateTotalOverlap(trees)
+
+	next, removed, added, ok := Reseed(trees, index, NewRNG(1), 64, DefaultConfig())
+	if !ok {
+		t.Fatalf("Expected the overlapping tree to be reseeded")
+	}
+	if len(next) != len(trees) {
+		t.Fatalf("Expected %d trees after reseeding, got %d", len(trees), len(next))
+	}
+	if id := next[len(next)-1].ID; id != 1 && id != 2 {
+		t.Fatalf("Expected tree 1 or 2 re-added last, got %+v", next)
+	}
+	if added >= removed {
+		t.Errorf("Expected the reseeded tree's overlap to drop from %.4f, got %.4f", removed, added)
+	}
+	if got, want := tree.CalculateTotalOverlap(next), before-removed+added; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Returned overlaps give total %.6f, recomputed %.6f", want, got)
+	}
+	if trees[2].X != 3.05 {
+		t.Errorf("Reseed modified its input")
+	}
+
+	// Nothing to do without an overlap
+	apart := tree.RemoveTree(trees, 2)
+	if _, _, _, ok := Reseed(apart, tree.BuildRTree(apart), NewRNG(1), 64, DefaultConfig()); ok {
+		t.Errorf("Expected no reseed for a layout without overlaps")
+	}
+
+	// The move keeps the incremental overlap total exact inside the SA loop
+	conf := DefaultConfig()
+	conf.RandomSeed = 7
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.ReseedProb = 0.2
+	conf.Debug = true
+	conf.LogLevel = LogSilent
+	_, best := NewSimulatedAnnealingPenalty(trees, conf).SolvePenalty()
+	if len(best) != len(trees) {
+		t.Errorf("Expected %d trees, got %d", len(trees), len(best))
+	}
+}
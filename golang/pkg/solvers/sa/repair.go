@@ -0,0 +1,70 @@
+package sa
+
+import (
+	"math"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// Repair makes an overlapping configuration valid with as little change as possible.
+// Each iteration pushes every overlapping pair apart along the line between their
+// centers by a small step (force-directed separation). Once valid, a short LocalSearch
+// pass recovers side length lost during separation. If maxIter is reached first the
+// partially repaired configuration is returned; check it with tree.HasCollision.
+func Repair(trees []tree.ChristmasTree, maxIter int) []tree.ChristmasTree {
+	c := CloneTrees(trees)
+	step := 0.005
+
+	for it := 0; it < maxIter; it++ {
+		pairs := tree.OverlappingPairs(c)
+		if len(pairs) == 0 {
+			return LocalSearch(c, 2)
+		}
+
+		for _, p := range pairs {
+			i, j := p[0], p[1]
+			dx := c[j].X - c[i].X
+			dy := c[j].Y - c[i].Y
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d < 1e-9 {
+				// Coincident centers: separate along an arbitrary axis
+				dx, dy, d = 1, 0, 1
+			}
+			c[i].X -= dx / d * step / 2
+			c[i].Y -= dy / d * step / 2
+			c[j].X += dx / d * step / 2
+			c[j].Y += dy / d * step / 2
+		}
+	}
+
+	if !tree.HasCollision(c) {
+		return LocalSearch(c, 2)
+	}
+	return c
+}
+
+// SeparateOverlaps pushes overlapping trees apart in place: each of up to iters passes moves
+// both trees of every overlapping pair by half the minimum translation vector
+// (tree.ChristmasTree.MTV) in opposite directions. Returns whether the layout ended up
+// free of overlaps; later pairs in a pass may push trees into new overlaps, which the next
+// pass resolves.
+func SeparateOverlaps(trees []tree.ChristmasTree, iters int) bool {
+	for it := 0; it < iters; it++ {
+		pairs := tree.OverlappingPairs(trees)
+		if len(pairs) == 0 {
+			return true
+		}
+		for _, p := range pairs {
+			i, j := p[0], p[1]
+			dx, dy, ok := trees[i].MTV(&trees[j])
+			if !ok {
+				continue // Already separated by an earlier push in this pass
+			}
+			trees[i].X += dx / 2
+			trees[i].Y += dy / 2
+			trees[j].X -= dx / 2
+			trees[j].Y -= dy / 2
+		}
+	}
+	return !tree.HasCollision(trees)
+}
@@ -0,0 +1,58 @@
+package sa
+
+import (
+	"math"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestRepair(t *testing.T) {
+	// Bottom tiers overlap slightly (base width is 0.7)
+	trees := []tree.ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 0.65, Y: 0, Angle: 0},
+		{ID: 2, X: 0, Y: 1.5, Angle: 0},
+	}
+	if !tree.HasCollision(trees) {
+		t.Fatalf("Test layout should start with an overlap")
+	}
+
+	repaired := Repair(trees, 200)
+
+	if len(repaired) != len(trees) {
+		t.Fatalf("Expected %d trees, got %d", len(trees), len(repaired))
+	}
+	if tree.HasCollision(repaired) {
+		t.Fatalf("Repair left overlaps")
+	}
+
+	displacement := 0.0
+	for i := range trees {
+		displacement += math.Hypot(repaired[i].X-trees[i].X, repaired[i].Y-trees[i].Y)
+	}
+	if displacement > 0.5 {
+		t.Errorf("Repair moved trees too far: total displacement %.4f", displacement)
+	}
+}
+
+func TestSeparateOverlaps(t *testing.T) {
+	// A crowded row: every neighbour pair overlaps
+	var trees []tree.ChristmasTree
+	for i := 0; i < 6; i++ {
+		trees = append(trees, tree.ChristmasTree{ID: i, X: float64(i) * 0.55, Y: 0.05 * float64(i%2)})
+	}
+	if !tree.HasCollision(trees) {
+		t.Fatalf("Test layout should start with overlaps")
+	}
+
+	if !SeparateOverlaps(trees, 100) {
+		t.Fatalf("SeparateOverlaps did not make the layout valid")
+	}
+	if tree.HasCollision(trees) {
+		t.Fatalf("Reported valid but trees still collide")
+	}
+	if side := tree.Side(trees); side > 6*0.7+0.5 {
+		t.Errorf("Separation spread the trees too far: side %.4f", side)
+	}
+}
@@ -0,0 +1,48 @@
+package sa
+
+import (
+	"context"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// RunWarmRestarts runs RunAdvancedSA and then Config.RestartCount warm restarts.
+// Each restart seeds the SA with RestartSeed(best) instead of a cold initialization,
+// so the search explores the neighborhood of the best solution found so far
+// (a basic variable-neighborhood-search scheme). Returns the best valid layout; once ctx
+// is done the current run returns early and no further restarts are started.
+func RunWarmRestarts(ctx context.Context, initialTrees []tree.ChristmasTree, config *Config) []tree.ChristmasTree {
+	best := RunAdvancedSAContext(ctx, initialTrees, config)
+	bestSide := tree.Side(best)
+	rng := NewRNG(config.RandomSeed)
+
+	for r := 1; r <= config.RestartCount && ctx.Err() == nil; r++ {
+		seed := RestartSeed(best, config.RestartStrength, rng)
+		if len(config.AngleSet) > 0 {
+			// The kick rotates freely, so pull the seed back onto the allowed angles
+			for i := range seed {
+				seed[i].Angle = config.SnapAngle(seed[i].Angle)
+			}
+			if tree.AnyOvl(seed) {
+				continue
+			}
+		}
+
+		// Vary the seed per restart so each run explores differently
+		restartConfig := *config
+		restartConfig.RandomSeed = config.RandomSeed + int64(r)*7919
+
+		candidate := RunAdvancedSAContext(ctx, seed, &restartConfig)
+		if side := tree.Side(candidate); side < bestSide-1e-12 && !tree.HasCollision(candidate) {
+			config.logf(LogSummary, "[n=%3d] Restart %d improved: %.5f -> %.5f\n", len(best), r, bestSide, side)
+			best, bestSide = candidate, side
+		}
+	}
+	return best
+}
+
+// RestartSeed returns a perturbed copy of best used as a warm restart seed.
+// It is PerturbAdvanced at the given strength: a few trees are kicked and overlaps repaired.
+func RestartSeed(best []tree.ChristmasTree, strength float64, rng RNG) []tree.ChristmasTree {
+	return PerturbAdvanced(best, strength, rng)
+}
@@ -0,0 +1,58 @@
+package sa
+
+import (
+	"context"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestRestartSeedNeighborhood(t *testing.T) {
+	// Loose 5x4 grid so kicked trees never need overlap repair
+	var best []tree.ChristmasTree
+	for k := 0; k < 20; k++ {
+		best = append(best, tree.ChristmasTree{ID: k, X: float64(k%5) * 1.5, Y: float64(k/5) * 1.5})
+	}
+
+	seed := RestartSeed(best, 0.1, NewRNG(3))
+	if len(seed) != len(best) {
+		t.Fatalf("Seed has %d trees, expected %d", len(seed), len(best))
+	}
+	if tree.HasCollision(seed) {
+		t.Errorf("Restart seed has collisions")
+	}
+
+	same := 0
+	for i := range best {
+		if seed[i].Equal(&best[i]) {
+			same++
+		}
+	}
+	if same == len(best) {
+		t.Errorf("Restart seed is identical to the best")
+	}
+	if same < len(best)*3/4 {
+		t.Errorf("Restart seed shares only %d/%d trees with the best", same, len(best))
+	}
+}
+
+func TestRunWarmRestarts(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 5
+	conf.NSteps = 5
+	conf.NStepsPerT = 50
+	conf.RestartCount = 2
+
+	var initial []tree.ChristmasTree
+	for k := 0; k < 6; k++ {
+		initial = append(initial, tree.ChristmasTree{ID: k, X: float64(k%3) * 1.2, Y: float64(k/3) * 1.2})
+	}
+
+	best := RunWarmRestarts(context.Background(), initial, conf)
+	if tree.HasCollision(best) {
+		t.Errorf("Warm restarts returned colliding trees")
+	}
+	if tree.Side(best) > tree.Side(initial)+1e-12 {
+		t.Errorf("Warm restarts worsened the side: %.5f > %.5f", tree.Side(best), tree.Side(initial))
+	}
+}
@@ -0,0 +1,17 @@
+package sa
+
+import "math/rand"
+
+// RNG is the source of randomness used by the SA solvers.
+// *rand.Rand satisfies it; a faster PRNG or a scripted source for tests can be
+// injected by assigning Base.Rng after NewBase.
+type RNG interface {
+	Float64() float64
+	NormFloat64() float64
+	Intn(n int) int
+}
+
+// NewRNG returns the default math/rand source for the given seed
+func NewRNG(seed int64) RNG {
+	return rand.New(rand.NewSource(seed))
+}
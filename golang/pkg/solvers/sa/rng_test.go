@@ -0,0 +1,57 @@
+package sa
+
+import (
+	"math"
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// stubRNG replays fixed sequences, cycling when exhausted
+type stubRNG struct {
+	floats, norms []float64
+	ints          []int
+	fi, ni, ii    int
+}
+
+func (s *stubRNG) Float64() float64 {
+	v := s.floats[s.fi%len(s.floats)]
+	s.fi++
+	return v
+}
+
+func (s *stubRNG) NormFloat64() float64 {
+	v := s.norms[s.ni%len(s.norms)]
+	s.ni++
+	return v
+}
+
+func (s *stubRNG) Intn(n int) int {
+	v := s.ints[s.ii%len(s.ints)] % n
+	s.ii++
+	return v
+}
+
+func TestStubRNGDrivesMoves(t *testing.T) {
+	conf := DefaultConfig()
+	conf.PositionDelta = 0.1
+	conf.AngleDelta = 10.0
+
+	sa := NewBase(nil, conf)
+	sa.Rng = &stubRNG{floats: []float64{0.75, 0.25}, norms: []float64{1.0}, ints: []int{0}}
+
+	tr := tree.ChristmasTree{X: 1, Y: 1, Angle: 355}
+	sa.PerturbTree(&tr)
+	if math.Abs(tr.X-1.05) > 1e-12 || math.Abs(tr.Y-0.95) > 1e-12 || math.Abs(tr.Angle-5) > 1e-12 {
+		t.Errorf("Unexpected perturbation: %+v", tr)
+	}
+
+	// exp(-1) ~ 0.368: a draw of 0.3 accepts, 0.5 rejects
+	sa.Rng = &stubRNG{floats: []float64{0.3, 0.5}}
+	if !sa.Accept(1.0, 1.0) {
+		t.Errorf("Expected acceptance with draw 0.3")
+	}
+	if sa.Accept(1.0, 1.0) {
+		t.Errorf("Expected rejection with draw 0.5")
+	}
+}
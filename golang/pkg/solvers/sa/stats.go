@@ -0,0 +1,85 @@
+package sa
+
+import "time"
+
+// NumMoveTypes is the number of move types used by the advanced SA solvers
+const NumMoveTypes = 13
+
+// MoveGlobalRotate rotates the whole configuration about its centroid
+const MoveGlobalRotate = NumMoveTypes - 2
+
+// NumPenaltyMoveTypes is the number of move types drawn by the advanced penalty SA: those
+// before MoveGlobalRotate. Its draw ignores the move weights of MoveGlobalRotate and later.
+const NumPenaltyMoveTypes = MoveGlobalRotate
+
+// MoveInteriorJitter is the late-phase move type, only drawn below Config.InteriorJitterT
+const MoveInteriorJitter = NumMoveTypes - 1
+
+// MoveReplant is the remove-and-replant move. It is not part of the weighted draw: the
+// advanced SA tries it with probability Config.ReplantProb per step instead.
+const MoveReplant = NumMoveTypes
+
+// MoveStats collects the outcome of one move type over a run
+type MoveStats struct {
+	Attempts         int     // Times the move type was selected
+	Accepted         int     // Valid moves accepted by the Metropolis rule
+	Improvements     int     // Accepted moves that reduced the side length
+	TotalImprovement float64 // Sum of side length reductions
+	MaxImprovement   float64 // Largest single side length reduction
+}
+
+// MeanImprovement returns the average side length reduction per improving move
+func (m MoveStats) MeanImprovement() float64 {
+	if m.Improvements == 0 {
+		return 0
+	}
+	return m.TotalImprovement / float64(m.Improvements)
+}
+
+// record registers an accepted move that changed the side length by -gain
+func (m *MoveStats) record(gain float64) {
+	m.Accepted++
+	if gain <= 0 {
+		return
+	}
+	m.Improvements++
+	m.TotalImprovement += gain
+	if gain > m.MaxImprovement {
+		m.MaxImprovement = gain
+	}
+}
+
+// SolveStats holds diagnostics of an advanced SA run. A move type that never
+// improves the side is a candidate for disabling or retuning.
+type SolveStats struct {
+	Moves   [NumMoveTypes]MoveStats
+	Replant MoveStats // The MoveReplant move
+}
+
+// move returns the statistics of move type mt, including MoveReplant
+func (s *SolveStats) move(mt int) *MoveStats {
+	if mt == MoveReplant {
+		return &s.Replant
+	}
+	return &s.Moves[mt]
+}
+
+// SAStats holds acceptance diagnostics of a collision-free SA run. Every step counts
+// exactly once as accepted, rejected or rejected for collision.
+type SAStats struct {
+	Accepted          int           // Steps accepted by the Metropolis rule
+	Rejected          int           // Valid steps rejected by the Metropolis rule
+	RejectedCollision int           // Steps where every perturbed tree collided
+	NewBest           int           // Times a new best solution was found
+	Reheats           int           // Times the temperature was reset by Config.ReheatAfter
+	FinalT            float64       // Temperature when the run ended
+	Elapsed           time.Duration // Wall-clock time of the run
+}
+
+// AcceptanceRate returns the fraction of collision-free steps that were accepted
+func (s SAStats) AcceptanceRate() float64 {
+	if s.Accepted+s.Rejected == 0 {
+		return 0
+	}
+	return float64(s.Accepted) / float64(s.Accepted+s.Rejected)
+}
@@ -0,0 +1,102 @@
+package sa
+
+import (
+	"sync"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// replica is one fixed-temperature chain of RunParallelTempering
+type replica struct {
+	solver    *SimulatedAnnealing
+	trees     []tree.ChristmasTree
+	score     float64
+	best      []tree.ChristmasTree
+	bestScore float64
+	T         float64
+}
+
+// run performs steps collision-free Metropolis steps at the replica's temperature
+func (r *replica) run(steps int) {
+	for k := 0; k < steps; k++ {
+		moved, _ := r.solver.perturbSet(r.trees)
+		if len(moved) == 0 {
+			continue
+		}
+		newScore := tree.CalculateScore(r.trees)
+		if !r.solver.Accept(newScore-r.score, r.T) {
+			r.solver.revertSet(r.trees, moved)
+			continue
+		}
+		r.score = newScore
+		if newScore < r.bestScore {
+			r.bestScore = newScore
+			r.best = CloneTrees(r.trees)
+		}
+	}
+}
+
+// RunParallelTempering runs one collision-free chain per config, each at the fixed
+// temperature configs[k].Tmax (which must be positive; order them from cold to hot and give
+// each its own RandomSeed). The chains run in parallel for swapEvery steps at a time, after
+// which states of adjacent temperatures are swapped with the replica-exchange Metropolis
+// rule, letting good states found by hot chains sink to the cold ones. The run lasts
+// NSteps * NStepsPerT steps of configs[0], which also sets the log level. Returns the best
+// valid layout over all replicas.
+func RunParallelTempering(initial []tree.ChristmasTree, configs []*Config, swapEvery int) (float64, []tree.ChristmasTree) {
+	if len(configs) == 0 {
+		return tree.CalculateScore(initial), CloneTrees(initial)
+	}
+	if swapEvery < 1 {
+		swapEvery = 1
+	}
+
+	replicas := make([]*replica, len(configs))
+	for k, config := range configs {
+		trees := config.snapStart(initial)
+		score := tree.CalculateScore(trees)
+		replicas[k] = &replica{
+			solver:    NewSimulatedAnnealing(trees, config),
+			trees:     trees,
+			score:     score,
+			best:      CloneTrees(trees),
+			bestScore: score,
+			T:         config.Tmax,
+		}
+	}
+
+	swapRng := NewRNG(configs[0].RandomSeed)
+	total := configs[0].NSteps * configs[0].NStepsPerT
+	attempted, swapped := 0, 0
+	for done := 0; done < total; done += swapEvery {
+		steps := min(swapEvery, total-done)
+		var wg sync.WaitGroup
+		for _, r := range replicas {
+			wg.Go(func() { r.run(steps) })
+		}
+		wg.Wait()
+
+		// Exchange neighbours with probability min(1, exp((E_a - E_b)(1/T_a - 1/T_b)))
+		for k := 0; k+1 < len(replicas); k++ {
+			a, b := replicas[k], replicas[k+1]
+			attempted++
+			if !Metropolis(-(a.score-b.score)*(1/a.T-1/b.T), 1, false, swapRng) {
+				continue
+			}
+			// Deep copies so no two replicas ever share a backing array
+			a.trees, b.trees = CloneTrees(b.trees), CloneTrees(a.trees)
+			a.score, b.score = b.score, a.score
+			swapped++
+		}
+	}
+
+	best := replicas[0]
+	for _, r := range replicas[1:] {
+		if r.bestScore < best.bestScore {
+			best = r
+		}
+	}
+	configs[0].logf(LogSummary, "[n=%3d] Parallel tempering: %d replicas, %d/%d swaps accepted, best %.5f\n",
+		len(initial), len(replicas), swapped, attempted, best.bestScore)
+	return best.bestScore, best.best
+}
@@ -0,0 +1,36 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+func TestRunParallelTempering(t *testing.T) {
+	var initial []tree.ChristmasTree
+	for i := 0; i < 5; i++ {
+		initial = append(initial, tree.ChristmasTree{ID: i, X: float64(i) * 0.9, Y: 0, Angle: 0})
+	}
+
+	// Geometric ladder from cold to hot
+	var configs []*Config
+	for k, T := range []float64{1e-4, 1e-3, 1e-2, 1e-1} {
+		c := DefaultConfig()
+		c.RandomSeed = int64(k + 1)
+		c.Tmax = T
+		c.NSteps = 10
+		c.NStepsPerT = 50
+		configs = append(configs, c)
+	}
+
+	score, best := RunParallelTempering(initial, configs, 25)
+	if len(best) != len(initial) || tree.HasCollision(best) {
+		t.Fatalf("Parallel tempering returned an invalid layout")
+	}
+	if side := tree.CalculateSideLength(best); side != score {
+		t.Errorf("Returned score %.6f does not match the layout's side %.6f", score, side)
+	}
+	if score >= tree.CalculateSideLength(initial) {
+		t.Errorf("Expected an improvement over the spread-out row, got %.5f", score)
+	}
+}
@@ -0,0 +1,114 @@
+package sa
+
+import (
+	"fmt"
+
+	"tree-packing-challenge/pkg/tree"
+)
+
+// Move types of a TraceStep
+const (
+	MovePerturbSet = 0 // The collision-free solver's perturbSet step, recorded as deltas
+	MovePlugin     = 1 // A Config.Moves step, recorded as the new poses of the trees it changed
+)
+
+// TraceMove is one tree's applied change within a step
+type TraceMove struct {
+	Tree           int
+	DX, DY, DAngle float64
+}
+
+// TracePose is one tree's pose after a plug-in move
+type TracePose struct {
+	Tree        int
+	X, Y, Angle float64
+}
+
+// TraceStep is one solver step: the kept moves (Poses for MovePlugin) and whether the
+// Metropolis test accepted them
+type TraceStep struct {
+	MoveType int
+	Moves    []TraceMove
+	Poses    []TracePose
+	Accepted bool
+}
+
+// Trace is a recorded move sequence of a solve together with its final (current) layout.
+// Set Base.Trace to a new Trace before Solve to record one.
+type Trace struct {
+	Steps []TraceStep
+	Final []tree.ChristmasTree
+}
+
+// record appends a perturbSet step
+func (tr *Trace) record(moved []treeUndo, accepted bool) {
+	step := TraceStep{MoveType: MovePerturbSet, Accepted: accepted}
+	for _, u := range moved {
+		step.Moves = append(step.Moves, TraceMove{Tree: u.idx, DX: u.dx, DY: u.dy, DAngle: u.dAngle})
+	}
+	tr.Steps = append(tr.Steps, step)
+}
+
+// recordPoses appends a MovePlugin step holding the new pose of every tree that differs
+// between before and after. Plug-in moves are opaque, so their deltas are not known and
+// the poses are stored instead; a replay sets them exactly.
+func (tr *Trace) recordPoses(before, after []tree.ChristmasTree, accepted bool) {
+	step := TraceStep{MoveType: MovePlugin, Accepted: accepted}
+	for i := range after {
+		b, a := &before[i], &after[i]
+		if a.X != b.X || a.Y != b.Y || a.Angle != b.Angle {
+			step.Poses = append(step.Poses, TracePose{Tree: i, X: a.X, Y: a.Y, Angle: a.Angle})
+		}
+	}
+	tr.Steps = append(tr.Steps, step)
+}
+
+// ReplayTrace re-applies a recorded trace to the seed layout without any randomness or
+// collision checks, and returns an error unless the result matches trace.Final exactly.
+// config must have the same AngleSet as the recorded run, whose start is snapped the same
+// way. The seed is not modified.
+func ReplayTrace(seed []tree.ChristmasTree, trace *Trace, config *Config) ([]tree.ChristmasTree, error) {
+	sa := NewBase(nil, config)
+	cur := sa.Config.snapStart(seed)
+
+	for k, step := range trace.Steps {
+		var undo []treeUndo
+		switch step.MoveType {
+		case MovePerturbSet:
+			for _, m := range step.Moves {
+				if m.Tree < 0 || m.Tree >= len(cur) {
+					return cur, fmt.Errorf("step %d: tree index %d out of range", k, m.Tree)
+				}
+				t := &cur[m.Tree]
+				undo = append(undo, treeUndo{idx: m.Tree, x: t.X, y: t.Y, angle: t.Angle})
+				sa.ApplyDelta(t, m.DX, m.DY, m.DAngle)
+			}
+		case MovePlugin:
+			for _, p := range step.Poses {
+				if p.Tree < 0 || p.Tree >= len(cur) {
+					return cur, fmt.Errorf("step %d: tree index %d out of range", k, p.Tree)
+				}
+				t := &cur[p.Tree]
+				undo = append(undo, treeUndo{idx: p.Tree, x: t.X, y: t.Y, angle: t.Angle})
+				sa.RestoreTree(t, p.X, p.Y, p.Angle)
+			}
+		default:
+			return cur, fmt.Errorf("step %d: unknown move type %d", k, step.MoveType)
+		}
+		if !step.Accepted {
+			for u := len(undo) - 1; u >= 0; u-- {
+				sa.RestoreTree(&cur[undo[u].idx], undo[u].x, undo[u].y, undo[u].angle)
+			}
+		}
+	}
+
+	if len(cur) != len(trace.Final) {
+		return cur, fmt.Errorf("replay has %d trees, trace recorded %d", len(cur), len(trace.Final))
+	}
+	for i := range cur {
+		if !cur[i].Equal(&trace.Final[i]) {
+			return cur, fmt.Errorf("tree %d diverged: replayed %+v, recorded %+v", i, cur[i], trace.Final[i])
+		}
+	}
+	return cur, nil
+}
@@ -0,0 +1,68 @@
+package sa
+
+import (
+	"testing"
+
+	"tree-packing-challenge/pkg/solvers/greedy"
+)
+
+func TestRecordThenReplay(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 21
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.LogFreq = 1000000
+	conf.TreesPerStep = 2
+
+	seed, _ := greedy.InitializeTrees(6, nil)
+
+	solver := NewSimulatedAnnealing(seed, conf)
+	solver.Trace = &Trace{}
+	solver.Solve()
+
+	if len(solver.Trace.Steps) == 0 {
+		t.Fatalf("No steps were recorded")
+	}
+
+	replayed, err := ReplayTrace(seed, solver.Trace, conf)
+	if err != nil {
+		t.Fatalf("Replay did not reproduce the recorded run: %v", err)
+	}
+	if len(replayed) != len(seed) {
+		t.Errorf("Replay changed the tree count")
+	}
+
+	// Any divergence from the recorded final state is reported
+	solver.Trace.Final[0].X += 1e-12
+	if _, err := ReplayTrace(seed, solver.Trace, conf); err == nil {
+		t.Errorf("Expected a mismatch error for a tampered final state")
+	}
+}
+
+func TestRecordThenReplayPluginMoves(t *testing.T) {
+	conf := DefaultConfig()
+	conf.RandomSeed = 5
+	conf.NSteps = 10
+	conf.NStepsPerT = 50
+	conf.LogFreq = 1000000
+	conf.Moves = []Move{PerturbMove{Config: conf}}
+
+	seed, _ := greedy.InitializeTrees(6, nil)
+
+	solver := NewSimulatedAnnealing(seed, conf)
+	solver.Trace = &Trace{}
+	solver.Solve()
+
+	plugin := 0
+	for _, step := range solver.Trace.Steps {
+		if step.MoveType == MovePlugin && len(step.Poses) > 0 {
+			plugin++
+		}
+	}
+	if plugin == 0 {
+		t.Fatalf("No plug-in moves were recorded")
+	}
+	if _, err := ReplayTrace(seed, solver.Trace, conf); err != nil {
+		t.Fatalf("Replay did not reproduce the recorded run: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package tree
+
+import "math"
+
+// Bounds is a running axis-aligned bounding box that grows as trees are appended,
+// so the side length is available in O(1) after each insertion
+type Bounds struct {
+	MinX, MinY, MaxX, MaxY float64
+	count                  int
+}
+
+// NewBounds returns empty bounds
+func NewBounds() Bounds {
+	return Bounds{
+		MinX: math.MaxFloat64, MinY: math.MaxFloat64,
+		MaxX: -math.MaxFloat64, MaxY: -math.MaxFloat64,
+	}
+}
+
+// Add extends the bounds by the tree's bounding box
+func (b *Bounds) Add(t *ChristmasTree) {
+	b.Extend(t.GetBoundingBox())
+}
+
+// Extend extends the bounds by an already computed box
+func (b *Bounds) Extend(minX, minY, maxX, maxY float64) {
+	b.MinX = math.Min(b.MinX, minX)
+	b.MinY = math.Min(b.MinY, minY)
+	b.MaxX = math.Max(b.MaxX, maxX)
+	b.MaxY = math.Max(b.MaxY, maxY)
+	b.count++
+}
+
+// Side returns the maximum dimension of the bounds (0 when empty)
+func (b *Bounds) Side() float64 {
+	if b.count == 0 {
+		return 0
+	}
+	return math.Max(b.MaxX-b.MinX, b.MaxY-b.MinY)
+}
+
+// MinimalSquare returns the corners (counter-clockwise from bottom-left) and side of the
+// smallest axis-aligned square containing all trees, i.e. the box the score is computed
+// from. The square is centered on the bounding box, so the shorter dimension is padded
+// equally on both sides.
+func MinimalSquare(trees []ChristmasTree) (corners [4][2]float64, side float64) {
+	if len(trees) == 0 {
+		return corners, 0
+	}
+	minX, minY, maxX, maxY := GetBounds(trees)
+	side = math.Max(maxX-minX, maxY-minY)
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+	h := side / 2
+
+	corners = [4][2]float64{
+		{cx - h, cy - h},
+		{cx + h, cy - h},
+		{cx + h, cy + h},
+		{cx - h, cy + h},
+	}
+	return corners, side
+}
+
+// BBoxTracker maintains the bounding box of a layout under single-tree moves. For each of
+// the four sides it keeps the extreme value together with the number of trees attaining
+// it, so a move only rescans a side when the last tree on it moves inward.
+type BBoxTracker struct {
+	boxes [][4]float64 // Per tree: -minX, -minY, maxX, maxY (every side is a maximum)
+	ext   [4]float64
+	count [4]int
+}
+
+// NewBBoxTracker builds a tracker for the trees' current poses
+func NewBBoxTracker(trees []ChristmasTree) *BBoxTracker {
+	b := &BBoxTracker{boxes: make([][4]float64, len(trees))}
+	for i := range trees {
+		b.boxes[i] = trackerBox(&trees[i])
+	}
+	for k := range b.ext {
+		b.rescan(k)
+	}
+	return b
+}
+
+// trackerBox returns the tree's bounding box in the tracker's all-maximum form
+func trackerBox(t *ChristmasTree) [4]float64 {
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	return [4]float64{-minX, -minY, maxX, maxY}
+}
+
+// rescan recomputes side k from all boxes
+func (b *BBoxTracker) rescan(k int) {
+	b.ext[k], b.count[k] = -math.MaxFloat64, 0
+	for i := range b.boxes {
+		switch v := b.boxes[i][k]; {
+		case v > b.ext[k]:
+			b.ext[k], b.count[k] = v, 1
+		case v == b.ext[k]:
+			b.count[k]++
+		}
+	}
+}
+
+// Update records that tree i now has the pose of t
+func (b *BBoxTracker) Update(i int, t *ChristmasTree) {
+	old, box := b.boxes[i], trackerBox(t)
+	b.boxes[i] = box
+	for k := range b.ext {
+		if old[k] == b.ext[k] {
+			b.count[k]--
+		}
+		switch {
+		case box[k] > b.ext[k]:
+			b.ext[k], b.count[k] = box[k], 1
+		case box[k] == b.ext[k]:
+			b.count[k]++
+		}
+		if b.count[k] == 0 {
+			b.rescan(k)
+		}
+	}
+}
+
+// Bounds returns the current bounding box of the layout
+func (b *BBoxTracker) Bounds() (minX, minY, maxX, maxY float64) {
+	return -b.ext[0], -b.ext[1], b.ext[2], b.ext[3]
+}
+
+// Side returns the maximum dimension of the bounding box, like CalculateSideLength (0 when empty)
+func (b *BBoxTracker) Side() float64 {
+	if len(b.boxes) == 0 {
+		return 0
+	}
+	minX, minY, maxX, maxY := b.Bounds()
+	return math.Max(maxX-minX, maxY-minY)
+}
@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBoundsIncremental(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b := NewBounds()
+	if b.Side() != 0 {
+		t.Fatalf("Empty bounds should have side 0, got %f", b.Side())
+	}
+
+	var trees []ChristmasTree
+	for i := 0; i < 50; i++ {
+		tr := ChristmasTree{ID: i, X: rng.Float64()*10 - 5, Y: rng.Float64()*10 - 5, Angle: rng.Float64() * 360}
+		trees = append(trees, tr)
+		b.Add(&tr)
+
+		minX, minY, maxX, maxY := GetBounds(trees)
+		if b.MinX != minX || b.MinY != minY || b.MaxX != maxX || b.MaxY != maxY {
+			t.Fatalf("After %d trees: incremental bounds (%f,%f,%f,%f) != GetBounds (%f,%f,%f,%f)",
+				i+1, b.MinX, b.MinY, b.MaxX, b.MaxY, minX, minY, maxX, maxY)
+		}
+		if b.Side() != Side(trees) {
+			t.Fatalf("After %d trees: side %f != %f", i+1, b.Side(), Side(trees))
+		}
+	}
+}
+
+func TestMinimalSquareContainsAllVertices(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	var trees []ChristmasTree
+	for k := 0; k < 10; k++ {
+		trees = append(trees, ChristmasTree{ID: k, X: rng.Float64() * 4, Y: rng.Float64() * 2, Angle: rng.Float64() * 360})
+	}
+
+	corners, side := MinimalSquare(trees)
+	if side != CalculateSideLength(trees) {
+		t.Errorf("Square side %v differs from the score side %v", side, CalculateSideLength(trees))
+	}
+	if math.Abs(corners[2][0]-corners[0][0]-side) > 1e-12 || math.Abs(corners[2][1]-corners[0][1]-side) > 1e-12 {
+		t.Errorf("Corners %v do not span the side %v", corners, side)
+	}
+
+	const tol = 1e-12
+	for _, tr := range trees {
+		for _, pt := range tr.GetOrbPolygon()[0] {
+			if pt[0] < corners[0][0]-tol || pt[0] > corners[2][0]+tol || pt[1] < corners[0][1]-tol || pt[1] > corners[2][1]+tol {
+				t.Fatalf("Vertex %v lies outside the square %v", pt, corners)
+			}
+		}
+	}
+}
+
+func TestBBoxTrackerRandomWalk(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	trees := make([]ChristmasTree, 20)
+	for i := range trees {
+		trees[i] = ChristmasTree{ID: i, X: rng.Float64() * 3, Y: rng.Float64() * 3, Angle: rng.Float64() * 360}
+	}
+	b := NewBBoxTracker(trees)
+
+	for step := 0; step < 20000; step++ {
+		i := rng.Intn(len(trees))
+		// Mix small moves with occasional exact repeats of a pose, so ties on a side occur
+		if rng.Intn(10) == 0 {
+			trees[i].X, trees[i].Y, trees[i].Angle = trees[0].X, trees[0].Y, trees[0].Angle
+		} else {
+			trees[i].X += rng.NormFloat64() * 0.1
+			trees[i].Y += rng.NormFloat64() * 0.1
+			trees[i].Angle = math.Mod(trees[i].Angle+rng.NormFloat64()*20+360, 360)
+		}
+		b.Update(i, &trees[i])
+
+		if got, want := b.Side(), CalculateSideLength(trees); got != want {
+			t.Fatalf("step %d: tracker side %.15f, CalculateSideLength %.15f", step, got, want)
+		}
+	}
+
+	if NewBBoxTracker(nil).Side() != 0 {
+		t.Errorf("Empty tracker should have side 0")
+	}
+}
+
+func TestGetBoundaryEps(t *testing.T) {
+	// A 3x3 grid: the middle tree is 1.0 from the nearest box side
+	var trees []ChristmasTree
+	for k := 0; k < 9; k++ {
+		trees = append(trees, ChristmasTree{ID: k, X: float64(k%3) * 1.0, Y: float64(k/3) * 1.3})
+	}
+	if got := GetBoundaryEps(trees, 0.01); len(got) != 8 {
+		t.Errorf("Expected the 8 outer trees with a tight eps, got %v", got)
+	}
+	if got := GetBoundaryEps(trees, 1.1); len(got) != 9 {
+		t.Errorf("Expected every tree with a wide eps, got %v", got)
+	}
+	if got, want := GetBoundary(trees), GetBoundaryEps(trees, 0.01); len(got) != len(want) {
+		t.Errorf("GetBoundary should use eps 0.01: %v vs %v", got, want)
+	}
+}
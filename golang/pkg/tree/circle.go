@@ -0,0 +1,89 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+)
+
+// MinEnclosingCircle returns the center and radius of the smallest circle containing every
+// polygon vertex of the trees, using Welzl's algorithm (iterative form) on the vertices in
+// a fixed pseudo-random order, so the result is deterministic. Expected time is linear.
+func MinEnclosingCircle(trees []ChristmasTree) (cx, cy, r float64) {
+	var pts [][2]float64
+	for i := range trees {
+		ring := trees[i].GetOrbPolygon()[0]
+		for _, p := range ring[:len(ring)-1] { // The last point closes the ring
+			pts = append(pts, [2]float64{p[0], p[1]})
+		}
+	}
+	if len(pts) == 0 {
+		return 0, 0, 0
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(pts), func(i, j int) { pts[i], pts[j] = pts[j], pts[i] })
+
+	c := circle{pts[0][0], pts[0][1], 0}
+	for i := 1; i < len(pts); i++ {
+		if c.contains(pts[i]) {
+			continue
+		}
+		// pts[i] lies on the boundary of the circle of pts[:i+1]
+		c = circle{pts[i][0], pts[i][1], 0}
+		for j := 0; j < i; j++ {
+			if c.contains(pts[j]) {
+				continue
+			}
+			c = circleFrom2(pts[i], pts[j])
+			for k := 0; k < j; k++ {
+				if !c.contains(pts[k]) {
+					c = circleFrom3(pts[i], pts[j], pts[k])
+				}
+			}
+		}
+	}
+	return c.x, c.y, c.r
+}
+
+// CircleScore returns the diameter of the minimum enclosing circle, an alternative
+// objective to the square side
+func CircleScore(trees []ChristmasTree) float64 {
+	_, _, r := MinEnclosingCircle(trees)
+	return 2 * r
+}
+
+// circle is a candidate enclosing circle of MinEnclosingCircle
+type circle struct {
+	x, y, r float64
+}
+
+// contains reports whether p is inside the circle, with a relative tolerance for rounding
+func (c circle) contains(p [2]float64) bool {
+	return math.Hypot(p[0]-c.x, p[1]-c.y) <= c.r*(1+1e-12)+1e-12
+}
+
+// circleFrom2 returns the circle with diameter ab
+func circleFrom2(a, b [2]float64) circle {
+	return circle{(a[0] + b[0]) / 2, (a[1] + b[1]) / 2, math.Hypot(a[0]-b[0], a[1]-b[1]) / 2}
+}
+
+// circleFrom3 returns the circumcircle of abc, or the circle over the farthest pair if the
+// points are collinear
+func circleFrom3(a, b, c [2]float64) circle {
+	bx, by := b[0]-a[0], b[1]-a[1]
+	cx, cy := c[0]-a[0], c[1]-a[1]
+	d := 2 * (bx*cy - by*cx)
+	if math.Abs(d) < 1e-18 {
+		best := circleFrom2(a, b)
+		for _, cand := range []circle{circleFrom2(a, c), circleFrom2(b, c)} {
+			if cand.r > best.r {
+				best = cand
+			}
+		}
+		return best
+	}
+	b2, c2 := bx*bx+by*by, cx*cx+cy*cy
+	ux := (cy*b2 - by*c2) / d
+	uy := (bx*c2 - cx*b2) / d
+	return circle{a[0] + ux, a[1] + uy, math.Hypot(ux, uy)}
+}
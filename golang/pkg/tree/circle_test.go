@@ -0,0 +1,61 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestMinEnclosingCircle(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+	for trial := 0; trial < 20; trial++ {
+		trees := make([]ChristmasTree, 1+rng.Intn(4))
+		for i := range trees {
+			trees[i] = ChristmasTree{ID: i, X: rng.Float64() * 3, Y: rng.Float64() * 3, Angle: rng.Float64() * 360}
+		}
+		cx, cy, r := MinEnclosingCircle(trees)
+
+		// Every vertex is inside, and no circle through 2 or 3 vertices that also contains
+		// all of them is smaller (the optimum is always such a circle)
+		var pts [][2]float64
+		for i := range trees {
+			for _, p := range trees[i].GetOrbPolygon()[0] {
+				pts = append(pts, [2]float64{p[0], p[1]})
+				if d := math.Hypot(p[0]-cx, p[1]-cy); d > r+1e-9 {
+					t.Fatalf("trial %d: vertex outside circle by %g", trial, d-r)
+				}
+			}
+		}
+		best := math.Inf(1)
+		containsAll := func(c circle) bool {
+			for _, p := range pts {
+				if math.Hypot(p[0]-c.x, p[1]-c.y) > c.r+1e-9 {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range pts {
+			for j := i + 1; j < len(pts); j++ {
+				if c := circleFrom2(pts[i], pts[j]); c.r < best && containsAll(c) {
+					best = c.r
+				}
+				for k := j + 1; k < len(pts); k++ {
+					if c := circleFrom3(pts[i], pts[j], pts[k]); c.r < best && containsAll(c) {
+						best = c.r
+					}
+				}
+			}
+		}
+		if r > best+1e-9 {
+			t.Errorf("trial %d: radius %.9f larger than a brute-force candidate %.9f", trial, r, best)
+		}
+		if got := CircleScore(trees); got != 2*r {
+			t.Errorf("CircleScore = %v, want %v", got, 2*r)
+		}
+	}
+
+	if _, _, r := MinEnclosingCircle(nil); r != 0 {
+		t.Errorf("Empty layout should have radius 0, got %v", r)
+	}
+}
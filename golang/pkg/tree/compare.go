@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GroupComparison compares group n of two submissions A and B
+type GroupComparison struct {
+	N              int
+	HasA, HasB     bool    // The group is present in the file
+	SideA, SideB   float64 // Side length (0 when the group is missing)
+	ValidA, ValidB bool    // Exactly n trees and no overlaps
+	Winner         string  // "a", "b", "tie", or "" when neither group is valid
+}
+
+// CompareReport is the per-n and aggregate comparison of two submissions
+type CompareReport struct {
+	Groups             []GroupComparison // Every n present in either file, sorted
+	OnlyA, OnlyB       []int             // n present in one file only
+	WinsA, WinsB, Ties int
+	SideSumA, SideSumB float64 // Sum of side lengths over each file's groups
+	ScoreA, ScoreB     float64 // Competition score (ScoreSubmission) of each file
+}
+
+// CompareSubmissions loads two submission CSVs and compares them group by group. A valid
+// group beats an invalid or missing one; between two valid groups the smaller side wins.
+func CompareSubmissions(a, b string) (*CompareReport, error) {
+	groupsA, err := LoadSubmission(a)
+	if err != nil {
+		return nil, fmt.Errorf("compare %s: %w", a, err)
+	}
+	groupsB, err := LoadSubmission(b)
+	if err != nil {
+		return nil, fmt.Errorf("compare %s: %w", b, err)
+	}
+
+	seen := make(map[int]bool)
+	var ns []int
+	for _, groups := range []map[int][]ChristmasTree{groupsA, groupsB} {
+		for n := range groups {
+			if !seen[n] {
+				seen[n] = true
+				ns = append(ns, n)
+			}
+		}
+	}
+	sort.Ints(ns)
+
+	report := &CompareReport{
+		ScoreA: ScoreSubmission(groupsA),
+		ScoreB: ScoreSubmission(groupsB),
+	}
+	for _, n := range ns {
+		g := GroupComparison{N: n}
+		var treesA, treesB []ChristmasTree
+		treesA, g.HasA = groupsA[n]
+		treesB, g.HasB = groupsB[n]
+		if g.HasA {
+			g.SideA = CalculateSideLength(treesA)
+			g.ValidA = len(treesA) == n && !AnyOvl(treesA)
+			report.SideSumA += g.SideA
+		}
+		if g.HasB {
+			g.SideB = CalculateSideLength(treesB)
+			g.ValidB = len(treesB) == n && !AnyOvl(treesB)
+			report.SideSumB += g.SideB
+		}
+
+		switch {
+		case g.HasA && !g.HasB:
+			report.OnlyA = append(report.OnlyA, n)
+		case g.HasB && !g.HasA:
+			report.OnlyB = append(report.OnlyB, n)
+		}
+
+		switch {
+		case g.ValidA && g.ValidB && g.SideA < g.SideB-1e-12:
+			g.Winner = "a"
+		case g.ValidA && g.ValidB && g.SideB < g.SideA-1e-12:
+			g.Winner = "b"
+		case g.ValidA && g.ValidB:
+			g.Winner = "tie"
+		case g.ValidA:
+			g.Winner = "a"
+		case g.ValidB:
+			g.Winner = "b"
+		}
+		switch g.Winner {
+		case "a":
+			report.WinsA++
+		case "b":
+			report.WinsB++
+		case "tie":
+			report.Ties++
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	return report, nil
+}
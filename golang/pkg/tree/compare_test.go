@@ -0,0 +1,43 @@
+package tree
+
+import "testing"
+
+func TestCompareSubmissions(t *testing.T) {
+	// n=1 ties, n=2 is tighter in b, n=3 is tighter in a but collides, n=3 missing from b
+	a := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 1.5), 3: {{ID: 1}, {ID: 2, X: 0.3}, {ID: 3, X: 2}}}
+	b := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 1.0)}
+	report, err := CompareSubmissions(writeSubmission(t, "a.csv", a), writeSubmission(t, "b.csv", b))
+	if err != nil {
+		t.Fatalf("CompareSubmissions: %v", err)
+	}
+
+	if len(report.Groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(report.Groups))
+	}
+	for k, want := range []string{"tie", "b", ""} {
+		if g := report.Groups[k]; g.Winner != want {
+			t.Errorf("n=%d: winner %q, want %q (%+v)", g.N, g.Winner, want, g)
+		}
+	}
+	if g := report.Groups[2]; g.ValidA || g.HasB || g.SideB != 0 {
+		t.Errorf("n=3 should be invalid in a and missing from b: %+v", g)
+	}
+	if len(report.OnlyA) != 1 || report.OnlyA[0] != 3 || len(report.OnlyB) != 0 {
+		t.Errorf("OnlyA = %v, OnlyB = %v, want [3] and []", report.OnlyA, report.OnlyB)
+	}
+	if report.WinsA != 0 || report.WinsB != 1 || report.Ties != 1 {
+		t.Errorf("Wins a=%d b=%d ties=%d, want 0, 1, 1", report.WinsA, report.WinsB, report.Ties)
+	}
+
+	wantSum := CalculateSideLength(b[1]) + CalculateSideLength(b[2])
+	if diff := report.SideSumB - wantSum; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("SideSumB = %v, want %v", report.SideSumB, wantSum)
+	}
+	if report.ScoreA <= report.ScoreB {
+		t.Errorf("a has an extra group and a wider n=2, so its score %v should exceed b's %v", report.ScoreA, report.ScoreB)
+	}
+
+	if _, err := CompareSubmissions("missing.csv", "missing.csv"); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
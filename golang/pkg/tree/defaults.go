@@ -1,6 +1,12 @@
 package tree
 
-// Tree dimensions
+import (
+	"errors"
+
+	"github.com/paulmach/orb"
+)
+
+// Tree dimensions of the challenge shape (DefaultShape)
 const (
 	TrunkW       = 0.15
 	TrunkH       = 0.2
@@ -13,3 +19,103 @@ const (
 	BaseY        = 0.0
 	TrunkBottomY = -TrunkH
 )
+
+// TreeShape holds the dimensions of a tiered tree: three stacked tiers (top triangle, then
+// two trapezoid-shouldered tiers) on a rectangular trunk. The bottom tier sits on y = 0
+// (BaseY) and the trunk hangs below it; the outline always has the same 15 vertices, so any
+// shape keeps the convex decomposition used by IntersectSAT.
+type TreeShape struct {
+	TrunkW, TrunkH    float64 // Trunk width and height
+	BaseW, MidW, TopW float64 // Widths of the bottom, middle and top tiers
+	TipY              float64 // Height of the tip
+	Tier1Y, Tier2Y    float64 // Heights of the top and middle tier bases
+}
+
+// DefaultShape is the tree of the Kaggle challenge
+var DefaultShape = TreeShape{
+	TrunkW: TrunkW, TrunkH: TrunkH,
+	BaseW: BaseW, MidW: MidW, TopW: TopW,
+	TipY: TipY, Tier1Y: Tier1Y, Tier2Y: Tier2Y,
+}
+
+// shape is the outline every tree is built from; shapeVersion changes with it so cached
+// polygons built for an earlier shape are rebuilt
+var (
+	shape        = DefaultShape
+	shapeVersion = 0
+)
+
+// SetShape makes every tree use the given outline. Like BaseAngleOffset it is global: set it
+// once at startup, before any solver runs. Shapes whose tiers are not stacked bottom to
+// top, or with a non-positive size, are rejected.
+func SetShape(s TreeShape) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+	shape = s
+	shapeVersion++
+	return nil
+}
+
+// validate checks the shape against the rules of SetShape
+func (s TreeShape) validate() error {
+	if s.TrunkW <= 0 || s.TrunkH <= 0 || s.BaseW <= 0 || s.MidW <= 0 || s.TopW <= 0 {
+		return errors.New("tree shape: widths and trunk height must be positive")
+	}
+	if !(BaseY < s.Tier2Y && s.Tier2Y < s.Tier1Y && s.Tier1Y < s.TipY) {
+		return errors.New("tree shape: need 0 < tier2_y < tier1_y < tip_y")
+	}
+	if s.TrunkW > s.BaseW {
+		return errors.New("tree shape: trunk is wider than the bottom tier")
+	}
+	return nil
+}
+
+// CurrentShape returns the shape set with SetShape (DefaultShape unless changed)
+func CurrentShape() TreeShape {
+	return shape
+}
+
+// Outline returns the closed, counterclockwise outline of an upright tree of this shape
+// with its trunk top at the origin: tip, left side down, trunk, right side up, tip
+func (s TreeShape) Outline() orb.Ring {
+	return orb.Ring{
+		// Start at Tip
+		orb.Point{0.0, s.TipY},
+		// Left side - Top Tier (going down left = CCW)
+		orb.Point{-s.TopW / 2, s.Tier1Y},
+		orb.Point{-s.TopW / 4, s.Tier1Y},
+		// Left side - Middle Tier
+		orb.Point{-s.MidW / 2, s.Tier2Y},
+		orb.Point{-s.MidW / 4, s.Tier2Y},
+		// Left side - Bottom Tier
+		orb.Point{-s.BaseW / 2, BaseY},
+		// Left Trunk
+		orb.Point{-s.TrunkW / 2, BaseY},
+		orb.Point{-s.TrunkW / 2, -s.TrunkH},
+		// Right Trunk
+		orb.Point{s.TrunkW / 2, -s.TrunkH},
+		orb.Point{s.TrunkW / 2, BaseY},
+		// Right side - Bottom Tier
+		orb.Point{s.BaseW / 2, BaseY},
+		// Right side - Middle Tier
+		orb.Point{s.MidW / 4, s.Tier2Y},
+		orb.Point{s.MidW / 2, s.Tier2Y},
+		// Right side - Top Tier
+		orb.Point{s.TopW / 4, s.Tier1Y},
+		orb.Point{s.TopW / 2, s.Tier1Y},
+		// Close the ring back to the tip
+		orb.Point{0.0, s.TipY},
+	}
+}
+
+// BaseAngleOffset (degrees) is added to every tree's Angle when generating its outline,
+// letting experiments reframe the angle coordinate (e.g. 90 makes Angle 0 mean trunk-left)
+// without touching positions. Kaggle expects the true rotation, so submissions must be
+// written with Angle+BaseAngleOffset; set it once at startup, before any solver runs.
+var BaseAngleOffset = 0.0
+
+// CollisionEpsilon is the intersection area below which two trees count as touching rather
+// than colliding. The default is far below any real overlap (moving a tree 1e-9 into
+// another already overlaps ~1e-10) while absorbing numerical slivers along shared edges.
+var CollisionEpsilon = 1e-12
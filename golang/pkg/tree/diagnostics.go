@@ -0,0 +1,96 @@
+package tree
+
+import (
+	"math"
+	"sort"
+)
+
+// TrivialExtensions returns every n whose layout is just layout n-1 (up to a translation)
+// with one extra tree appended. Such groups usually come from progressive building and
+// were never optimized on their own. Positions and angles are compared within tol.
+func TrivialExtensions(groups map[int][]ChristmasTree, tol float64) []int {
+	var flagged []int
+	for n, trees := range groups {
+		prev, ok := groups[n-1]
+		if !ok || len(prev) == 0 || len(trees) != len(prev)+1 {
+			continue
+		}
+		if isTranslatedPrefix(prev, trees, tol) {
+			flagged = append(flagged, n)
+		}
+	}
+	sort.Ints(flagged)
+	return flagged
+}
+
+// isTranslatedPrefix reports whether the first len(prev) trees of trees equal prev
+// shifted by a common offset
+func isTranslatedPrefix(prev, trees []ChristmasTree, tol float64) bool {
+	offX := trees[0].X - prev[0].X
+	offY := trees[0].Y - prev[0].Y
+
+	for i := range prev {
+		if math.Abs(trees[i].X-prev[i].X-offX) > tol || math.Abs(trees[i].Y-prev[i].Y-offY) > tol {
+			return false
+		}
+		dAngle := math.Mod(math.Abs(trees[i].Angle-prev[i].Angle), 360)
+		if math.Min(dAngle, 360-dAngle) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyMonotoneGrowth returns every n whose recorded side is smaller than the side for n-1.
+// Adding a tree to a progressive packing can never shrink the box, so a decrease points
+// to a bug or a full re-layout.
+func VerifyMonotoneGrowth(perN map[int]float64) []int {
+	var violations []int
+	for n, side := range perN {
+		if prev, ok := perN[n-1]; ok && side < prev {
+			violations = append(violations, n)
+		}
+	}
+	sort.Ints(violations)
+	return violations
+}
+
+// RadialDistribution histograms each tree's distance from the layout centroid (mean of the
+// tree positions) into buckets of equal width spanning [0, max distance]. A low count in the
+// first buckets means a hollow center, a candidate pocket for relocating boundary trees.
+func RadialDistribution(trees []ChristmasTree, buckets int) []int {
+	if buckets <= 0 {
+		return nil
+	}
+	hist := make([]int, buckets)
+	if len(trees) == 0 {
+		return hist
+	}
+
+	cx, cy := 0.0, 0.0
+	for i := range trees {
+		cx += trees[i].X
+		cy += trees[i].Y
+	}
+	cx /= float64(len(trees))
+	cy /= float64(len(trees))
+
+	dists := make([]float64, len(trees))
+	maxDist := 0.0
+	for i := range trees {
+		dists[i] = math.Hypot(trees[i].X-cx, trees[i].Y-cy)
+		maxDist = math.Max(maxDist, dists[i])
+	}
+
+	for _, d := range dists {
+		b := 0
+		if maxDist > 0 {
+			b = int(d / maxDist * float64(buckets))
+		}
+		if b >= buckets {
+			b = buckets - 1 // The farthest tree lands in the last bucket
+		}
+		hist[b]++
+	}
+	return hist
+}
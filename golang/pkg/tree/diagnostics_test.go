@@ -0,0 +1,68 @@
+package tree
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTrivialExtensions(t *testing.T) {
+	groups := map[int][]ChristmasTree{
+		1: {{ID: 0, X: 0, Y: 0, Angle: 45}},
+		// Re-optimized: first tree moved relative to n=1 layout
+		2: {{ID: 0, X: 0, Y: 0, Angle: 30}, {ID: 1, X: 0.8, Y: 0, Angle: 180}},
+		// Trivial extension of n=2, translated by (1, 1)
+		3: {
+			{ID: 0, X: 1, Y: 1, Angle: 30},
+			{ID: 1, X: 1.8, Y: 1, Angle: 180},
+			{ID: 2, X: 1.4, Y: 2, Angle: 0},
+		},
+		// Re-optimized: relative positions changed
+		4: {
+			{ID: 0, X: 0, Y: 0, Angle: 30},
+			{ID: 1, X: 0.7, Y: 0.1, Angle: 180},
+			{ID: 2, X: 0.4, Y: 1, Angle: 0},
+			{ID: 3, X: 1.2, Y: 1, Angle: 90},
+		},
+	}
+
+	got := TrivialExtensions(groups, 1e-6)
+	if want := []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TrivialExtensions = %v, want %v", got, want)
+	}
+}
+
+func TestVerifyMonotoneGrowth(t *testing.T) {
+	perN := map[int]float64{
+		1: 1.0,
+		2: 1.5,
+		3: 1.4, // decreased
+		4: 1.8,
+		6: 1.7, // n=5 missing, nothing to compare against
+		7: 1.7, // equal is fine
+		8: 1.6, // decreased
+	}
+
+	got := VerifyMonotoneGrowth(perN)
+	if want := []int{3, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("VerifyMonotoneGrowth = %v, want %v", got, want)
+	}
+}
+
+func TestRadialDistribution(t *testing.T) {
+	// One central tree surrounded by a ring of 12
+	trees := []ChristmasTree{{ID: 0}}
+	for k := 0; k < 12; k++ {
+		a := float64(k) * math.Pi / 6
+		trees = append(trees, ChristmasTree{ID: k + 1, X: 3 * math.Cos(a), Y: 3 * math.Sin(a)})
+	}
+
+	hist := RadialDistribution(trees, 4)
+	if want := []int{1, 0, 0, 12}; !reflect.DeepEqual(hist, want) {
+		t.Errorf("Expected %v, got %v", want, hist)
+	}
+
+	if got := RadialDistribution(nil, 3); !reflect.DeepEqual(got, []int{0, 0, 0}) {
+		t.Errorf("Expected empty histogram for no trees, got %v", got)
+	}
+}
@@ -29,7 +29,7 @@ func HasCollision(trees []ChristmasTree) bool {
 			[2]float64{maxX, maxY},
 			func(min, max [2]float64, data interface{}) bool {
 				j := data.(int)
-				if i != j && trees[i].Intersect(&trees[j]) {
+				if i != j && trees[i].IntersectSAT(&trees[j]) {
 					collision = true
 					return false // Stop searching
 				}
@@ -116,6 +116,110 @@ func CalculateTotalOverlap(trees []ChristmasTree) float64 {
 	return totalOverlap
 }
 
+// OverlappingPairs returns every pair (i, j) with i < j whose polygons intersect
+func OverlappingPairs(trees []ChristmasTree) [][2]int {
+	if len(trees) < 2 {
+		return nil
+	}
+
+	// Build spatial index for broad-phase collision detection
+	tr := rtree.RTree{}
+	for i := range trees {
+		minX, minY, maxX, maxY := trees[i].GetBoundingBox()
+		tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+	}
+
+	var pairs [][2]int
+	for i := range trees {
+		minX, minY, maxX, maxY := trees[i].GetBoundingBox()
+
+		tr.Search(
+			[2]float64{minX, minY},
+			[2]float64{maxX, maxY},
+			func(min, max [2]float64, data interface{}) bool {
+				j := data.(int)
+				if j > i && trees[i].Intersect(&trees[j]) { // Only count each pair once
+					pairs = append(pairs, [2]int{i, j})
+				}
+				return true
+			},
+		)
+	}
+
+	return pairs
+}
+
+// DeepestOverlap returns the pair (i, j), i < j, with the largest IntersectionArea, using
+// the R-tree as broad phase. It returns -1, -1, 0 when no two trees overlap.
+func DeepestOverlap(trees []ChristmasTree) (i, j int, area float64) {
+	i, j = -1, -1
+	if len(trees) < 2 {
+		return i, j, 0
+	}
+	tr := BuildRTree(trees)
+
+	for a := range trees {
+		minX, minY, maxX, maxY := trees[a].GetBoundingBox()
+		tr.Search(
+			[2]float64{minX, minY},
+			[2]float64{maxX, maxY},
+			func(min, max [2]float64, data interface{}) bool {
+				b := data.(int)
+				if b > a { // Only check each pair once
+					if overlap := trees[a].IntersectionArea(&trees[b]); overlap > area {
+						i, j, area = a, b, overlap
+					}
+				}
+				return true
+			},
+		)
+	}
+	return i, j, area
+}
+
+// BuildRTree indexes every tree's bounding box, with its index in trees as the item data
+func BuildRTree(trees []ChristmasTree) *rtree.RTree {
+	tr := &rtree.RTree{}
+	for i := range trees {
+		minX, minY, maxX, maxY := trees[i].GetBoundingBox()
+		tr.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+	}
+	return tr
+}
+
+// CalculateTreeOverlapRTree computes the same overlap area as CalculateTreeOverlap, using
+// an R-tree of the trees' bounding boxes (see BuildRTree) that the caller keeps in sync.
+// Only the entries of the other trees must be current; the entry of treeIndex is ignored,
+// so the tree may be moved before its entry is updated.
+func CalculateTreeOverlapRTree(trees []ChristmasTree, treeIndex int, tr *rtree.RTree) float64 {
+	if len(trees) < 2 || treeIndex < 0 || treeIndex >= len(trees) {
+		return 0
+	}
+
+	t := &trees[treeIndex]
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+
+	totalOverlap := 0.0
+	tr.Search(
+		[2]float64{minX, minY},
+		[2]float64{maxX, maxY},
+		func(min, max [2]float64, data interface{}) bool {
+			// Clearly separated pairs have zero area; skip the costly exact computation
+			if j := data.(int); j != treeIndex && !t.separatedSAT(&trees[j]) {
+				totalOverlap += t.IntersectionArea(&trees[j])
+			}
+			return true
+		},
+	)
+	return totalOverlap
+}
+
+// CountOverlappingPairs returns how many tree pairs intersect, which tells one deep
+// overlap apart from many shallow ones with the same CalculateTotalOverlap
+func CountOverlappingPairs(trees []ChristmasTree) int {
+	return len(OverlappingPairs(trees))
+}
+
 // CalculateTreeOverlap computes the total overlap area for a single tree with all others
 // This is more efficient when only one tree has moved
 func CalculateTreeOverlap(trees []ChristmasTree, treeIndex int) float64 {
@@ -144,6 +248,51 @@ func CalculateTreeOverlap(trees []ChristmasTree, treeIndex int) float64 {
 	return totalOverlap
 }
 
+// overlapGradientStep is the displacement OverlapGradient samples on each side of a tree
+const overlapGradientStep = 0.005
+
+// OverlapGradient estimates the gradient of tree i's overlap area (CalculateTreeOverlap)
+// with respect to its position by central differences, shifting the tree by
+// overlapGradientStep along each axis. Moving along (-gx, -gy) reduces the overlap fastest;
+// (0, 0) means no overlap or no direction that helps at this scale. The tree is moved
+// during sampling and restored before returning.
+func OverlapGradient(trees []ChristmasTree, i int) (gx, gy float64) {
+	if len(trees) < 2 || i < 0 || i >= len(trees) {
+		return 0, 0
+	}
+	t := &trees[i]
+	x, y := t.X, t.Y
+	const h = overlapGradientStep
+
+	// Only trees whose boxes come within h of tree i can contribute to any sample
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	var near []int
+	for j := range trees {
+		oMinX, oMinY, oMaxX, oMaxY := trees[j].GetBoundingBox()
+		if j != i && minX-h <= oMaxX && maxX+h >= oMinX && minY-h <= oMaxY && maxY+h >= oMinY {
+			near = append(near, j)
+		}
+	}
+	if len(near) == 0 {
+		return 0, 0
+	}
+
+	// The area is summed over convex pieces, which is much cheaper than IntersectionArea
+	sample := func(dx, dy float64) float64 {
+		t.X, t.Y = x+dx, y+dy
+		area := 0.0
+		for _, j := range near {
+			area += t.pieceOverlapArea(&trees[j])
+		}
+		return area
+	}
+
+	gx = (sample(h, 0) - sample(-h, 0)) / (2 * h)
+	gy = (sample(0, h) - sample(0, -h)) / (2 * h)
+	t.X, t.Y = x, y
+	return gx, gy
+}
+
 // CalculatePenalizedScore returns BoundingBox + λ × TotalOverlap
 func CalculatePenalizedScore(trees []ChristmasTree, overlapPenalty float64) float64 {
 	bboxScore := CalculateSideLength(trees)
@@ -0,0 +1,80 @@
+package tree
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/paulmach/orb"
+)
+
+// geoJSONFeature is a GeoJSON Feature with a Polygon geometry
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	ID         int               `json:"id"`
+	Geometry   geoJSONPolygon    `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+// geoJSONPolygon is a GeoJSON Polygon geometry with a single exterior ring
+type geoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+// geoJSONProperties holds the pose of a tree; angle is the true rotation like the CSV
+type geoJSONProperties struct {
+	ID    int     `json:"id"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Angle float64 `json:"angle"`
+}
+
+// ExportGeoJSON writes the configuration to w as a GeoJSON FeatureCollection with one
+// Polygon feature per tree outline and the tree's id, x, y and angle as properties. Rings
+// are closed and counterclockwise, following the GeoJSON right-hand rule. Coordinates are
+// plane units, not degrees, so GIS tools should treat them as a projected CRS.
+func ExportGeoJSON(trees []ChristmasTree, w io.Writer) error {
+	features := make([]geoJSONFeature, len(trees))
+	for i := range trees {
+		t := &trees[i]
+		features[i] = geoJSONFeature{
+			Type: "Feature",
+			ID:   t.ID,
+			Geometry: geoJSONPolygon{
+				Type:        "Polygon",
+				Coordinates: [][][2]float64{rightHandRing(t.GetOrbPolygon()[0])},
+			},
+			Properties: geoJSONProperties{ID: t.ID, X: t.X, Y: t.Y, Angle: t.Angle + BaseAngleOffset},
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Type     string           `json:"type"`
+		Features []geoJSONFeature `json:"features"`
+	}{Type: "FeatureCollection", Features: features})
+}
+
+// rightHandRing returns the ring's coordinates closed and in counterclockwise order
+func rightHandRing(ring orb.Ring) [][2]float64 {
+	coords := make([][2]float64, 0, len(ring)+1)
+	for _, p := range ring {
+		coords = append(coords, [2]float64{p[0], p[1]})
+	}
+	if len(coords) > 0 && coords[0] != coords[len(coords)-1] {
+		coords = append(coords, coords[0])
+	}
+
+	// Shoelace sum: negative for a clockwise ring
+	area := 0.0
+	for k := 0; k+1 < len(coords); k++ {
+		area += coords[k][0]*coords[k+1][1] - coords[k+1][0]*coords[k][1]
+	}
+	if area < 0 {
+		for a, b := 0, len(coords)-1; a < b; a, b = a+1, b-1 {
+			coords[a], coords[b] = coords[b], coords[a]
+		}
+	}
+	return coords
+}
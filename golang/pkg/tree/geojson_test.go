@@ -0,0 +1,67 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestExportGeoJSON(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 3, X: 0.5, Y: -1, Angle: 30},
+		{ID: 7, X: 2, Y: 1, Angle: 250},
+	}
+	var buf bytes.Buffer
+	if err := ExportGeoJSON(trees, &buf); err != nil {
+		t.Fatalf("ExportGeoJSON: %v", err)
+	}
+
+	var doc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Type     string `json:"type"`
+			Geometry struct {
+				Type        string         `json:"type"`
+				Coordinates [][][2]float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties struct {
+				ID    int     `json:"id"`
+				X     float64 `json:"x"`
+				Y     float64 `json:"y"`
+				Angle float64 `json:"angle"`
+			} `json:"properties"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if doc.Type != "FeatureCollection" || len(doc.Features) != len(trees) {
+		t.Fatalf("Expected a FeatureCollection of %d features, got %q with %d", len(trees), doc.Type, len(doc.Features))
+	}
+
+	for i, f := range doc.Features {
+		if f.Type != "Feature" || f.Geometry.Type != "Polygon" || len(f.Geometry.Coordinates) != 1 {
+			t.Fatalf("Feature %d is not a single-ring polygon feature", i)
+		}
+		p := f.Properties
+		if p.ID != trees[i].ID || p.X != trees[i].X || p.Y != trees[i].Y || p.Angle != trees[i].Angle+BaseAngleOffset {
+			t.Errorf("Feature %d has properties %+v for tree %+v", i, p, trees[i])
+		}
+
+		ring := f.Geometry.Coordinates[0]
+		if ring[0] != ring[len(ring)-1] {
+			t.Errorf("Feature %d ring is not closed", i)
+		}
+		area := 0.0
+		for k := 0; k+1 < len(ring); k++ {
+			area += ring[k][0]*ring[k+1][1] - ring[k+1][0]*ring[k][1]
+		}
+		if area <= 0 {
+			t.Errorf("Feature %d ring is not counterclockwise", i)
+		}
+		if math.Abs(area/2-TreeArea()) > 1e-9 {
+			t.Errorf("Feature %d ring encloses %.6f, want the tree area %.6f", i, area/2, TreeArea())
+		}
+	}
+}
@@ -12,6 +12,19 @@ func deg2rad(deg float64) float64 {
 	return deg * math.Pi / 180.0
 }
 
+// NormalizeAngle maps an angle in degrees to [0, 360), so equivalent rotations such as
+// -15 and 345, or 360 and 0, are stored (and rotated) identically
+func NormalizeAngle(a float64) float64 {
+	a = math.Mod(a, 360)
+	if a < 0 {
+		a += 360
+	}
+	if a >= 360 { // A tiny negative value rounds up to 360
+		a = 0
+	}
+	return a
+}
+
 // GetBoundingBox returns the axis-aligned bounding box of the rotated tree
 func (t *ChristmasTree) GetBoundingBox() (float64, float64, float64, float64) {
 	// Calculate bounding box from the actual orb polygon (same as used for intersection)
@@ -39,38 +52,29 @@ func (t *ChristmasTree) GetBoundingBox() (float64, float64, float64, float64) {
 	return minX, minY, maxX, maxY
 }
 
-// GetOrbPolygon returns an orb.Polygon representing the tree outline
+// TreeArea returns the exact area of a single tree, computed with the shoelace formula
+// over the outline of the current shape
+func TreeArea() float64 {
+	canonical := ChristmasTree{}
+	return calculateRingArea(orbPolygonToGeom(canonical.buildOrbPolygon())[0][0])
+}
+
+// GetOrbPolygon returns an orb.Polygon representing the tree outline in the current shape
+// (see SetShape). The polygon is cached on the tree and rebuilt only when the pose,
+// BaseAngleOffset or the shape changes, so the result is shared and must not be modified.
 func (t *ChristmasTree) GetOrbPolygon() orb.Polygon {
-	// Create the outer ring of the polygon (COUNTER-CLOCKWISE for polygol)
-	// CCW order: tip -> left side down -> trunk -> right side up -> tip
-	ring := orb.Ring{
-		// Start at Tip
-		orb.Point{0.0, TipY},
-		// Left side - Top Tier (going down left = CCW)
-		orb.Point{-TopW / 2, Tier1Y},
-		orb.Point{-TopW / 4, Tier1Y},
-		// Left side - Middle Tier
-		orb.Point{-MidW / 2, Tier2Y},
-		orb.Point{-MidW / 4, Tier2Y},
-		// Left side - Bottom Tier
-		orb.Point{-BaseW / 2, BaseY},
-		// Left Trunk
-		orb.Point{-TrunkW / 2, BaseY},
-		orb.Point{-TrunkW / 2, TrunkBottomY},
-		// Right Trunk
-		orb.Point{TrunkW / 2, TrunkBottomY},
-		orb.Point{TrunkW / 2, BaseY},
-		// Right side - Bottom Tier
-		orb.Point{BaseW / 2, BaseY},
-		// Right side - Middle Tier
-		orb.Point{MidW / 4, Tier2Y},
-		orb.Point{MidW / 2, Tier2Y},
-		// Right side - Top Tier
-		orb.Point{TopW / 4, Tier1Y},
-		orb.Point{TopW / 2, Tier1Y},
-		// Close the ring back to the tip
-		orb.Point{0.0, TipY},
+	if c := t.poly; c != nil && c.x == t.X && c.y == t.Y && c.angle == t.Angle && c.offset == BaseAngleOffset && c.shape == shapeVersion {
+		return c.poly
 	}
+	poly := t.buildOrbPolygon()
+	t.poly = &polygonCache{x: t.X, y: t.Y, angle: t.Angle, offset: BaseAngleOffset, shape: shapeVersion, poly: poly}
+	return poly
+}
+
+// buildOrbPolygon computes the tree outline for the current pose
+func (t *ChristmasTree) buildOrbPolygon() orb.Polygon {
+	// The outer ring is COUNTER-CLOCKWISE, as polygol expects
+	ring := shape.Outline()
 
 	// Apply translation to tree position
 	for i := range ring {
@@ -79,8 +83,8 @@ func (t *ChristmasTree) GetOrbPolygon() orb.Polygon {
 	}
 
 	// Apply rotation if needed
-	if t.Angle != 0 {
-		angleRad := deg2rad(t.Angle)
+	if angle := NormalizeAngle(t.Angle + BaseAngleOffset); angle != 0 {
+		angleRad := deg2rad(angle)
 		cosAngle := math.Cos(angleRad)
 		sinAngle := math.Sin(angleRad)
 
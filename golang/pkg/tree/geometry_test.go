@@ -0,0 +1,324 @@
+package tree
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestGetOrbPolygonGolden pins the exact competition geometry and rotation convention.
+// Run `go test ./pkg/tree -run Golden -update` only after an intentional shape change.
+func TestGetOrbPolygonGolden(t *testing.T) {
+	path := filepath.Join("testdata", "tree_vertices.golden.json")
+	angles := []float64{0, 90, 137.5}
+
+	got := make(map[string][][2]float64)
+	for _, angle := range angles {
+		tr := ChristmasTree{X: 0, Y: 0, Angle: angle}
+		var pts [][2]float64
+		for _, pt := range tr.GetOrbPolygon()[0] {
+			pts = append(pts, [2]float64{pt[0], pt[1]})
+		}
+		got[strconv.FormatFloat(angle, 'f', -1, 64)] = pts
+	}
+
+	if *updateGolden {
+		// One vertex per line keeps diffs of the golden file readable
+		var sb strings.Builder
+		sb.WriteString("{\n")
+		for a, angle := range angles {
+			key := strconv.FormatFloat(angle, 'f', -1, 64)
+			fmt.Fprintf(&sb, "  %q: [\n", key)
+			for k, pt := range got[key] {
+				sep := ","
+				if k == len(got[key])-1 {
+					sep = ""
+				}
+				fmt.Fprintf(&sb, "    [%s, %s]%s\n",
+					strconv.FormatFloat(pt[0], 'g', -1, 64), strconv.FormatFloat(pt[1], 'g', -1, 64), sep)
+			}
+			sep := ","
+			if a == len(angles)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(&sb, "  ]%s\n", sep)
+		}
+		sb.WriteString("}\n")
+		if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	var want map[string][][2]float64
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatalf("Failed to parse golden file: %v", err)
+	}
+
+	for key, wantPts := range want {
+		gotPts, ok := got[key]
+		if !ok {
+			t.Errorf("Angle %s missing from output", key)
+			continue
+		}
+		if len(gotPts) != len(wantPts) {
+			t.Errorf("Angle %s: got %d vertices, want %d", key, len(gotPts), len(wantPts))
+			continue
+		}
+		for k := range wantPts {
+			if math.Abs(gotPts[k][0]-wantPts[k][0]) > 1e-12 || math.Abs(gotPts[k][1]-wantPts[k][1]) > 1e-12 {
+				t.Errorf("Angle %s vertex %d: got %v, want %v", key, k, gotPts[k], wantPts[k])
+			}
+		}
+	}
+	if len(want) != len(angles) {
+		t.Errorf("Golden file has %d angles, want %d", len(want), len(angles))
+	}
+}
+
+func TestBaseAngleOffset(t *testing.T) {
+	defer func(old float64) { BaseAngleOffset = old }(BaseAngleOffset)
+
+	reference := ChristmasTree{X: 1.5, Y: -0.5, Angle: 40}
+	want := reference.GetOrbPolygon()[0]
+
+	BaseAngleOffset = 30
+	shifted := ChristmasTree{X: 1.5, Y: -0.5, Angle: 10}
+	got := shifted.GetOrbPolygon()[0]
+
+	for k := range want {
+		if math.Abs(got[k][0]-want[k][0]) > 1e-12 || math.Abs(got[k][1]-want[k][1]) > 1e-12 {
+			t.Errorf("Vertex %d: got %v, want %v", k, got[k], want[k])
+		}
+	}
+}
+
+func TestGetOrbPolygonCache(t *testing.T) {
+	tr := ChristmasTree{X: 1, Y: 2, Angle: 30}
+	first := tr.GetOrbPolygon()
+	if again := tr.GetOrbPolygon(); &again[0][0] != &first[0][0] {
+		t.Errorf("Expected the cached polygon for an unchanged pose")
+	}
+
+	// A copy that moves must rebuild its own outline without touching the original's
+	moved := tr
+	moved.X += 0.5
+	movedPoly := moved.GetOrbPolygon()
+	if math.Abs(movedPoly[0][0][0]-first[0][0][0]-0.5) > 1e-12 {
+		t.Errorf("Moved copy returned a stale outline")
+	}
+	if got := tr.GetOrbPolygon(); got[0][0] != first[0][0] {
+		t.Errorf("Original outline changed after moving a copy")
+	}
+
+	// The global angle offset is part of the cache key
+	BaseAngleOffset = 90
+	defer func() { BaseAngleOffset = 0 }()
+	fresh := ChristmasTree{X: 1, Y: 2, Angle: 30}
+	if got, want := tr.GetOrbPolygon()[0][0], fresh.buildOrbPolygon()[0][0]; got != want {
+		t.Errorf("Outline not rebuilt after BaseAngleOffset changed: got %v, want %v", got, want)
+	}
+}
+
+// benchTrees lays out 200 trees on a tight, slightly overlapping grid
+func benchTrees() []ChristmasTree {
+	trees := make([]ChristmasTree, 200)
+	for i := range trees {
+		trees[i] = ChristmasTree{ID: i, X: float64(i%15) * 0.6, Y: float64(i/15) * 0.9, Angle: float64(i * 37 % 360)}
+	}
+	return trees
+}
+
+func BenchmarkCalculateTotalOverlap(b *testing.B) {
+	b.Run("cached", func(b *testing.B) {
+		trees := benchTrees()
+		for b.Loop() {
+			CalculateTotalOverlap(trees)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		trees := benchTrees()
+		for b.Loop() {
+			for i := range trees {
+				trees[i].poly = nil
+			}
+			CalculateTotalOverlap(trees)
+		}
+	})
+}
+
+func TestTreeAreaAndPackingDensity(t *testing.T) {
+	// Top triangle + two tier trapezoids + trunk rectangle
+	want := TopW*(TipY-Tier1Y)/2 +
+		(TopW/2+MidW)/2*(Tier1Y-Tier2Y) +
+		(MidW/2+BaseW)/2*(Tier2Y-BaseY) +
+		TrunkW*TrunkH
+	if got := TreeArea(); math.Abs(got-want) > 1e-12 {
+		t.Errorf("TreeArea() = %.12f, want %.12f", got, want)
+	}
+
+	single := []ChristmasTree{{}}
+	s := Side(single)
+	if got := PackingDensity(single); math.Abs(got-want/(s*s)) > 1e-12 {
+		t.Errorf("PackingDensity of one tree = %v, want %v", got, want/(s*s))
+	}
+	if got := PackingDensity(nil); got != 0 {
+		t.Errorf("PackingDensity(nil) = %v, want 0", got)
+	}
+}
+
+func TestNormalizeAngle(t *testing.T) {
+	for _, a := range []float64{0, -15, 375, 360, -360, 720.5, -1e-15, 359.999999, 1e9 + 0.25} {
+		got := NormalizeAngle(a)
+		if got < 0 || got >= 360 {
+			t.Errorf("NormalizeAngle(%v) = %v, outside [0, 360)", a, got)
+		}
+		if again := NormalizeAngle(got); again != got {
+			t.Errorf("NormalizeAngle is not idempotent at %v: %v then %v", a, got, again)
+		}
+	}
+	if got := NormalizeAngle(-15); math.Abs(got-345) > 1e-12 {
+		t.Errorf("NormalizeAngle(-15) = %v, want 345", got)
+	}
+
+	full := ChristmasTree{X: 0.3, Y: -0.2, Angle: 360}
+	zero := ChristmasTree{X: 0.3, Y: -0.2, Angle: 0}
+	a, b := full.GetOrbPolygon()[0], zero.GetOrbPolygon()[0]
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Vertex %d at angle 360 is %v, at angle 0 is %v", i, a[i], b[i])
+		}
+	}
+	if c := full.Clone(); c.Angle != 0 {
+		t.Errorf("Clone of a tree at 360 has angle %v, want 0", c.Angle)
+	}
+}
+
+func TestCalculateTreeOverlapRTree(t *testing.T) {
+	trees := benchTrees()
+	index := BuildRTree(trees)
+	for k := 0; k < 200; k++ {
+		i := k * 7 % len(trees)
+		if got, want := CalculateTreeOverlapRTree(trees, i, index), CalculateTreeOverlap(trees, i); math.Abs(got-want) > 1e-12 {
+			t.Fatalf("tree %d: R-tree overlap %v, scan overlap %v", i, got, want)
+		}
+
+		// Move the tree, query before and after updating its entry
+		minX, minY, maxX, maxY := trees[i].GetBoundingBox()
+		trees[i].X += 0.3
+		trees[i].Angle += 20
+		if got, want := CalculateTreeOverlapRTree(trees, i, index), CalculateTreeOverlap(trees, i); math.Abs(got-want) > 1e-12 {
+			t.Fatalf("moved tree %d: R-tree overlap %v, scan overlap %v", i, got, want)
+		}
+		nMinX, nMinY, nMaxX, nMaxY := trees[i].GetBoundingBox()
+		index.Replace([2]float64{minX, minY}, [2]float64{maxX, maxY}, i, [2]float64{nMinX, nMinY}, [2]float64{nMaxX, nMaxY}, i)
+	}
+}
+
+func TestOverlapGradient(t *testing.T) {
+	// Tree 1 overlaps the right side of tree 0: moving it further right reduces the overlap
+	trees := []ChristmasTree{{ID: 0}, {ID: 1, X: 0.3, Y: 0.1}, {ID: 2, X: 5}}
+	gx, gy := OverlapGradient(trees, 1)
+	if gx >= 0 {
+		t.Errorf("Expected the overlap to fall towards +x, got gradient (%.4f, %.4f)", gx, gy)
+	}
+	if trees[1].X != 0.3 || trees[1].Y != 0.1 {
+		t.Errorf("OverlapGradient did not restore the tree: %+v", trees[1])
+	}
+
+	// It agrees with a finite difference of CalculateTreeOverlap
+	const h = 1e-4
+	trees[1].X += h
+	plus := CalculateTreeOverlap(trees, 1)
+	trees[1].X -= 2 * h
+	minus := CalculateTreeOverlap(trees, 1)
+	trees[1].X += h
+	if want := (plus - minus) / (2 * h); math.Abs(gx-want) > 0.05*math.Abs(want) {
+		t.Errorf("gx = %.5f, finite difference of CalculateTreeOverlap %.5f", gx, want)
+	}
+
+	// A tree with nothing nearby has no gradient
+	if gx, gy := OverlapGradient(trees, 2); gx != 0 || gy != 0 {
+		t.Errorf("Expected no gradient for an isolated tree, got (%v, %v)", gx, gy)
+	}
+}
+
+func BenchmarkCalculateTreeOverlap(b *testing.B) {
+	// Dense: many neighbours overlap, so IntersectionArea dominates both variants.
+	// Sparse: a collision-free layout at n=200, typical late in a penalty SA run.
+	sparse := make([]ChristmasTree, 200)
+	for i := range sparse {
+		sparse[i] = ChristmasTree{ID: i, X: float64(i%15) * 1.05, Y: float64(i/15) * 1.1, Angle: float64(i * 37 % 360)}
+	}
+	for _, layout := range []struct {
+		name  string
+		trees []ChristmasTree
+	}{{"dense", benchTrees()}, {"sparse", sparse}} {
+		trees := layout.trees
+		b.Run(layout.name+"/scan", func(b *testing.B) {
+			k := 0
+			for b.Loop() {
+				CalculateTreeOverlap(trees, k%len(trees))
+				k++
+			}
+		})
+		b.Run(layout.name+"/rtree", func(b *testing.B) {
+			index := BuildRTree(trees)
+			k := 0
+			for b.Loop() {
+				CalculateTreeOverlapRTree(trees, k%len(trees), index)
+				k++
+			}
+		})
+	}
+}
+
+func TestSetShape(t *testing.T) {
+	t.Cleanup(func() { SetShape(DefaultShape) })
+
+	tr := ChristmasTree{ID: 1, X: 1, Y: 2}
+	area := TreeArea()
+	_, _, _, maxY := tr.GetBoundingBox() // Cached for the default shape
+
+	// Doubling every dimension quadruples the area and is picked up by cached trees
+	s := DefaultShape
+	s.TrunkW, s.TrunkH, s.BaseW, s.MidW, s.TopW = 2*s.TrunkW, 2*s.TrunkH, 2*s.BaseW, 2*s.MidW, 2*s.TopW
+	s.TipY, s.Tier1Y, s.Tier2Y = 2*s.TipY, 2*s.Tier1Y, 2*s.Tier2Y
+	if err := SetShape(s); err != nil {
+		t.Fatalf("SetShape: %v", err)
+	}
+	if got := TreeArea(); math.Abs(got-4*area) > 1e-12 {
+		t.Errorf("Expected area %.6f for the doubled shape, got %.6f", 4*area, got)
+	}
+	if _, _, _, got := tr.GetBoundingBox(); math.Abs(got-(2+2*TipY)) > 1e-12 || got == maxY {
+		t.Errorf("Cached outline not rebuilt: tip at %.6f, want %.6f", got, 2+2*TipY)
+	}
+
+	// The default shape gives back the original outline
+	if err := SetShape(DefaultShape); err != nil {
+		t.Fatalf("SetShape: %v", err)
+	}
+	if got := TreeArea(); got != area {
+		t.Errorf("Default shape area %.9f, want %.9f", got, area)
+	}
+
+	bad := DefaultShape
+	bad.Tier1Y = bad.TipY + 0.1
+	if err := SetShape(bad); err == nil {
+		t.Errorf("Expected an error for tiers above the tip")
+	}
+	if CurrentShape() != DefaultShape {
+		t.Errorf("A rejected shape must not be applied")
+	}
+}
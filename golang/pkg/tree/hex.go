@@ -0,0 +1,176 @@
+package tree
+
+import "math"
+
+// GridConfig holds configuration for row-based tree placement
+type GridConfig struct {
+	HorizontalSpacing float64 // Spacing between trees in a row (default: 0.7)
+	EvenRowY          float64 // Y spacing for even rows (default: 1.0)
+	OddRowOffsetY     float64 // Y offset for odd rows (default: 0.8)
+	OddRowOffsetX     float64 // X offset for odd rows (default: 0.35, which is 0.7/2)
+}
+
+// DefaultGridConfig returns the default row placement configuration
+func DefaultGridConfig() *GridConfig {
+	return &GridConfig{
+		HorizontalSpacing: 0.7,
+		EvenRowY:          1.0,
+		OddRowOffsetY:     0.8,
+		OddRowOffsetX:     0.35, // 0.7/2 for staggered placement
+	}
+}
+
+// Lattice parameters swept by InitializeTreesHex: horizontal spacings from
+// GridConfig.HorizontalSpacing up to hexSpacingMax, odd-row x offsets across the spacing,
+// and row pitches in steps of hexPitchStep
+const (
+	hexSpacingMax  = 1.2
+	hexSpacingStep = 0.01
+	hexOffsetStep  = 0.05
+	hexPitchStep   = 0.01
+)
+
+// InitializeTreesHex places trees on a hexagonal lattice: odd rows are rotated by 180°
+// and shifted along the row, so their tips nest between the trees of the rows around
+// them. A wider spacing lets the rows nest deeper, so for each spacing from
+// config.HorizontalSpacing up to hexSpacingMax and each odd-row offset across it (half
+// the spacing is the symmetric lattice), the row pitches are swept up to the smallest
+// collision-free ones, then the number of trees per row is swept. Returns the layout
+// with the smallest squared side, the same score as the grid package's.
+func InitializeTreesHex(numTrees int, config *GridConfig) ([]ChristmasTree, float64) {
+	if config == nil {
+		config = DefaultGridConfig()
+	}
+
+	if numTrees == 0 {
+		return []ChristmasTree{}, 0
+	}
+
+	var bestTrees []ChristmasTree
+	bestScore := math.MaxFloat64
+
+	for k := 0; ; k++ {
+		spacing := config.HorizontalSpacing + float64(k)*hexSpacingStep
+		if spacing > hexSpacingMax+1e-9 {
+			break
+		}
+		for j := 0; float64(j)*hexOffsetStep < spacing; j++ {
+			offset := float64(j) * hexOffsetStep
+			oddY, period := hexPitches(spacing, offset)
+
+			for nEven := 1; nEven <= numTrees; nEven++ {
+				// Rows only get wider from here: stop once a full even row alone cannot
+				// beat the best
+				if width := float64(nEven-1) * spacing; width*width >= bestScore {
+					break
+				}
+				for nOdd := nEven; nOdd >= nEven-1 && nOdd >= 0; nOdd-- {
+					// The side is known before placing; only place layouts that would win
+					side := hexLayoutSide(numTrees, nEven, nOdd, spacing, offset, oddY, period)
+					if side*side >= bestScore {
+						continue
+					}
+					trees := tryHexPlacement(numTrees, nEven, nOdd, spacing, offset, oddY, period)
+					if len(trees) != numTrees {
+						continue
+					}
+					bestScore = side * side
+					bestTrees = trees
+				}
+			}
+		}
+	}
+
+	return bestTrees, bestScore
+}
+
+// hexPitches returns the row pitches of the lattice with the given spacing and odd-row
+// offset: the smallest y offset at which an inverted row clears the upright row below it,
+// and the smallest period after which the next upright row clears both rows below it
+func hexPitches(spacing, offset float64) (oddY, period float64) {
+	var upright []ChristmasTree
+	for k := -1; k <= 2; k++ {
+		upright = append(upright, ChristmasTree{X: float64(k) * spacing})
+	}
+	oddY = hexClearance(upright, 0, offset, 180)
+
+	below := append([]ChristmasTree{}, upright...)
+	for k := -2; k <= 1; k++ {
+		below = append(below, ChristmasTree{X: offset + float64(k)*spacing, Y: oddY, Angle: 180})
+	}
+	period = hexClearance(below, oddY, 0, 0)
+	return oddY, period
+}
+
+// hexClearance returns the smallest y above from, in steps of hexPitchStep, at which a
+// tree at x with the given angle does not collide with the probe trees
+func hexClearance(probe []ChristmasTree, from, x, angle float64) float64 {
+	trees := append(append([]ChristmasTree{}, probe...), ChristmasTree{})
+	i := len(probe)
+	index := BuildRTree(probe)
+	for step := 1; ; step++ {
+		trees[i] = ChristmasTree{X: x, Y: from + float64(step)*hexPitchStep, Angle: angle}
+		if !HasOvlIndexed(trees, i, index) {
+			return trees[i].Y
+		}
+	}
+}
+
+// hexRows calls visit with the tree count, angle, x offset and y of each lattice row
+// needed for numTrees trees with nEven trees per even row and nOdd per odd row
+func hexRows(numTrees, nEven, nOdd int, spacing, offset, oddY, period float64, visit func(count int, angle, x, y float64)) {
+	remaining := numTrees
+	for row := 0; remaining > 0; row++ {
+		treesInRow := min(remaining, nEven)
+		angle, xOffset, y := 0.0, 0.0, float64(row/2)*period
+		if row%2 == 1 {
+			treesInRow = min(remaining, nOdd)
+			angle, xOffset, y = 180, offset, y+oddY
+		}
+		remaining -= treesInRow
+		if treesInRow > 0 {
+			visit(treesInRow, angle, xOffset, y)
+		}
+	}
+}
+
+// hexLayoutSide returns the side of the bounding square of the lattice layout, assuming
+// every tree fits
+func hexLayoutSide(numTrees, nEven, nOdd int, spacing, offset, oddY, period float64) float64 {
+	bounds := NewBounds()
+	hexRows(numTrees, nEven, nOdd, spacing, offset, oddY, period, func(count int, angle, x, y float64) {
+		first := ChristmasTree{X: x, Y: y, Angle: angle}
+		last := ChristmasTree{X: x + float64(count-1)*spacing, Y: y, Angle: angle}
+		bounds.Add(&first)
+		bounds.Add(&last)
+	})
+	return bounds.Side()
+}
+
+// tryHexPlacement attempts to place numTrees on the lattice with nEven trees per even row
+// and nOdd per odd row, skipping trees that collide
+func tryHexPlacement(numTrees, nEven, nOdd int, spacing, offset, oddY, period float64) []ChristmasTree {
+	allTrees := make([]ChristmasTree, 0, numTrees)
+	index := BuildRTree(nil)
+
+	hexRows(numTrees, nEven, nOdd, spacing, offset, oddY, period, func(count int, angle, x, y float64) {
+		for k := 0; k < count; k++ {
+			i := len(allTrees)
+			allTrees = append(allTrees, ChristmasTree{
+				ID:    i,
+				X:     x + float64(k)*spacing,
+				Y:     y,
+				Angle: angle,
+			})
+			if HasOvlIndexed(allTrees, i, index) {
+				// Skip this tree if it collides
+				allTrees = allTrees[:i]
+				continue
+			}
+			minX, minY, maxX, maxY := allTrees[i].GetBoundingBox()
+			index.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+		}
+	})
+
+	return allTrees
+}
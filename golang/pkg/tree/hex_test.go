@@ -0,0 +1,36 @@
+package tree
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHexPitches(t *testing.T) {
+	// At the default spacing and half offset the lattice is the grid's 0.8/1.0 stagger
+	oddY, period := hexPitches(0.7, 0.35)
+	if math.Abs(oddY-0.8) > 1e-9 || math.Abs(period-1.0) > 1e-9 {
+		t.Errorf("Expected pitches 0.8/1.0 at spacing 0.7, got %.3f/%.3f", oddY, period)
+	}
+
+	// A wider spacing lets the inverted row sink deeper
+	if wider, _ := hexPitches(0.9, 0.45); wider >= oddY {
+		t.Errorf("Odd row offset %.3f at spacing 0.9 is not below %.3f", wider, oddY)
+	}
+}
+
+func TestInitializeTreesHex(t *testing.T) {
+	for _, n := range []int{1, 7, 12} {
+		trees, score := InitializeTreesHex(n, nil)
+		if len(trees) != n || HasCollision(trees) {
+			t.Fatalf("n=%d: got %d trees (collision: %v)", n, len(trees), HasCollision(trees))
+		}
+		if side := CalculateSideLength(trees); math.Abs(side*side-score) > 1e-12 {
+			t.Errorf("n=%d: returned score %.5f does not match the layout's %.5f", n, score, side*side)
+		}
+		for _, tr := range trees {
+			if tr.Angle != 0 && tr.Angle != 180 {
+				t.Errorf("n=%d: unexpected angle %v", n, tr.Angle)
+			}
+		}
+	}
+}
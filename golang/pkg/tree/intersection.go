@@ -6,25 +6,11 @@ import (
 	"github.com/engelsjk/polygol"
 )
 
-// Intersect checks if this tree intersects with another tree
+// Intersect checks if this tree intersects with another tree.
+// Intersections with area up to CollisionEpsilon (slivers from merely touching
+// outlines) are not counted as collisions.
 func (t *ChristmasTree) Intersect(other *ChristmasTree) bool {
-	poly1 := t.GetOrbPolygon()
-	poly2 := other.GetOrbPolygon()
-
-	// Convert orb.Polygon to polygol.Geom format
-	geom1 := orbPolygonToGeom(poly1)
-	geom2 := orbPolygonToGeom(poly2)
-
-	// Use polygol to compute intersection
-	intersection, err := polygol.Intersection(geom1, geom2)
-	if err != nil {
-		// If there's an error, assume no intersection
-		return false
-	}
-
-	// Check if intersection is not empty
-	// An empty intersection means no overlap
-	return len(intersection) > 0 && len(intersection[0]) > 0
+	return t.IntersectionArea(other) > CollisionEpsilon
 }
 
 // IntersectionArea returns the area of overlap between two trees (0 if none)
@@ -65,3 +51,33 @@ func calculateRingArea(ring [][]float64) float64 {
 	}
 	return math.Abs(area) / 2.0
 }
+
+// AreaOutsideRect returns the area of the tree lying outside the given rectangle
+// (0 if the tree is fully inside). Used for continuous boundary penalties.
+func (t *ChristmasTree) AreaOutsideRect(minX, minY, maxX, maxY float64) float64 {
+	tMinX, tMinY, tMaxX, tMaxY := t.GetBoundingBox()
+	if tMinX >= minX && tMinY >= minY && tMaxX <= maxX && tMaxY <= maxY {
+		return 0
+	}
+
+	rect := polygol.Geom{{{
+		{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}, {minX, minY},
+	}}}
+	diff, err := polygol.Difference(orbPolygonToGeom(t.GetOrbPolygon()), rect)
+	if err != nil {
+		return 0
+	}
+
+	// The first ring of each polygon is its outer boundary, any further rings are holes
+	totalArea := 0.0
+	for _, poly := range diff {
+		for k, ring := range poly {
+			if k == 0 {
+				totalArea += calculateRingArea(ring)
+			} else {
+				totalArea -= calculateRingArea(ring)
+			}
+		}
+	}
+	return totalArea
+}
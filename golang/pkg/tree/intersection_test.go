@@ -0,0 +1,93 @@
+package tree
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAreaOutsideRect(t *testing.T) {
+	tr := ChristmasTree{X: 0, Y: 0, Angle: 0}
+	minX, minY, maxX, maxY := tr.GetBoundingBox()
+
+	if a := tr.AreaOutsideRect(minX, minY, maxX, maxY); a != 0 {
+		t.Errorf("Tree inside its own bounding box has outside area %v", a)
+	}
+
+	// Cutting at the base leaves exactly the trunk outside
+	trunk := TrunkW * TrunkH
+	if a := tr.AreaOutsideRect(minX, BaseY, maxX, maxY); math.Abs(a-trunk) > 1e-9 {
+		t.Errorf("Expected trunk area %v outside, got %v", trunk, a)
+	}
+
+	// A rectangle far away leaves the whole tree outside
+	full := tr.AreaOutsideRect(10, 10, 11, 11)
+	if full <= trunk {
+		t.Errorf("Expected the whole tree area outside, got %v", full)
+	}
+}
+
+func TestTouchingTreesDoNotCollide(t *testing.T) {
+	for _, deg := range []float64{0, 37, 123} {
+		r := deg * math.Pi / 180
+		// b is a rotated by 180 degrees with its trunk resting on a's trunk
+		a := ChristmasTree{X: 0, Y: 0, Angle: deg}
+		b := ChristmasTree{X: 0.4 * math.Sin(r), Y: -0.4 * math.Cos(r), Angle: deg + 180}
+		if a.Intersect(&b) || HasCollision([]ChristmasTree{a, b}) || HasOvl([]ChristmasTree{a, b}, 0) {
+			t.Errorf("angle %v: touching trees reported as colliding", deg)
+		}
+
+		// Pushing b slightly into a is a real overlap
+		b.X -= 1e-6 * math.Sin(r)
+		b.Y += 1e-6 * math.Cos(r)
+		if !a.Intersect(&b) {
+			t.Errorf("angle %v: overlapping trees not detected", deg)
+		}
+	}
+}
+
+func TestCountOverlappingPairs(t *testing.T) {
+	// One deep overlap
+	deep := []ChristmasTree{{ID: 0}, {ID: 1, X: 0.05}, {ID: 2, X: 3}}
+	if got := CountOverlappingPairs(deep); got != 1 {
+		t.Errorf("Deep overlap: %d pairs, want 1", got)
+	}
+
+	// A row where each neighbour slightly overlaps the next
+	var row []ChristmasTree
+	for i := 0; i < 4; i++ {
+		row = append(row, ChristmasTree{ID: i, X: float64(i) * 0.68})
+	}
+	if got := CountOverlappingPairs(row); got != 3 {
+		t.Errorf("Shallow overlaps: %d pairs, want 3", got)
+	}
+	if CalculateTotalOverlap(row) >= CalculateTotalOverlap(deep) {
+		t.Errorf("Expected the shallow row to have less total overlap than the deep pair")
+	}
+
+	if got := CountOverlappingPairs(nil); got != 0 {
+		t.Errorf("Empty layout: %d pairs, want 0", got)
+	}
+}
+
+func TestDeepestOverlap(t *testing.T) {
+	// A shallow overlap between trees 0 and 1, a deeper one between trees 2 and 3
+	trees := []ChristmasTree{
+		{ID: 10, X: 0}, {ID: 11, X: 0.68},
+		{ID: 12, X: 3}, {ID: 13, X: 3.2},
+		{ID: 14, X: 6},
+	}
+	i, j, area := DeepestOverlap(trees)
+	if i != 2 || j != 3 {
+		t.Errorf("Expected the deepest pair (2, 3), got (%d, %d)", i, j)
+	}
+	if want := trees[2].IntersectionArea(&trees[3]); area != want {
+		t.Errorf("Expected area %.6f, got %.6f", want, area)
+	}
+
+	if i, j, area := DeepestOverlap(trees[4:]); i != -1 || j != -1 || area != 0 {
+		t.Errorf("Expected no pair for a single tree, got (%d, %d, %v)", i, j, area)
+	}
+	if i, _, _ := DeepestOverlap([]ChristmasTree{{ID: 0}, {ID: 1, X: 2}}); i != -1 {
+		t.Errorf("Expected no pair for separated trees")
+	}
+}
@@ -1,19 +1,41 @@
 // Package tree defines the core data structures for the Christmas tree packing challenge.
 package tree
 
+import "github.com/paulmach/orb"
+
 // ChristmasTree represents a single tree with position and rotation
 type ChristmasTree struct {
 	ID    int
 	X, Y  float64
 	Angle float64 // Angle in DEGREES (Kaggle submission format)
+
+	// Outline from the last GetOrbPolygon call, keyed by the pose it was built for.
+	// The cache is per value: a copy starts out sharing it, but rebuilding replaces the
+	// copy's pointer and never writes to the shared polygonCache, so copies cannot see
+	// each other's pose. Compare trees with Equal, not ==, since == also compares this pointer.
+	poly *polygonCache
+}
+
+// polygonCache is an immutable tree outline together with the pose it belongs to
+type polygonCache struct {
+	x, y, angle, offset float64
+	shape               int // shapeVersion the outline was built with
+	poly                orb.Polygon
 }
 
-// Clone creates a deep copy of a ChristmasTree
+// Clone creates a deep copy of a ChristmasTree with its angle normalized to [0, 360)
+// (the immutable outline cache is shared)
 func (t *ChristmasTree) Clone() ChristmasTree {
 	return ChristmasTree{
 		ID:    t.ID,
 		X:     t.X,
 		Y:     t.Y,
-		Angle: t.Angle,
+		Angle: NormalizeAngle(t.Angle),
+		poly:  t.poly,
 	}
 }
+
+// Equal reports whether both trees have the same ID and pose, ignoring cached geometry
+func (t *ChristmasTree) Equal(other *ChristmasTree) bool {
+	return t.ID == other.ID && t.X == other.X && t.Y == other.Y && t.Angle == other.Angle
+}
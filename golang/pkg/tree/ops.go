@@ -2,6 +2,9 @@ package tree
 
 import (
 	"math"
+	"sort"
+
+	"github.com/tidwall/rtree"
 )
 
 // HasOvl checks if the tree at index i overlaps with any other tree
@@ -14,7 +17,7 @@ func HasOvl(trees []ChristmasTree, i int) bool {
 		if i == j {
 			continue
 		}
-		if target.Intersect(&trees[j]) {
+		if target.IntersectSAT(&trees[j]) {
 			return true
 		}
 	}
@@ -25,7 +28,7 @@ func HasOvl(trees []ChristmasTree, i int) bool {
 func AnyOvl(trees []ChristmasTree) bool {
 	for i := range trees {
 		for j := i + 1; j < len(trees); j++ {
-			if trees[i].Intersect(&trees[j]) {
+			if trees[i].IntersectSAT(&trees[j]) {
 				return true
 			}
 		}
@@ -33,6 +36,36 @@ func AnyOvl(trees []ChristmasTree) bool {
 	return false
 }
 
+// HasOvlIndexed reports the same as HasOvl, with an R-tree of the trees' bounding boxes (see
+// BuildRTree) as broad phase so only trees whose boxes meet tree i's are tested. A nil index
+// is built here; a caller checking many trees keeps one in sync instead. As with
+// CalculateTreeOverlapRTree, only the entries of the other trees must be current.
+func HasOvlIndexed(trees []ChristmasTree, i int, index *rtree.RTree) bool {
+	if i < 0 || i >= len(trees) {
+		return false
+	}
+	if index == nil {
+		index = BuildRTree(trees)
+	}
+	target := &trees[i]
+	minX, minY, maxX, maxY := target.GetBoundingBox()
+	overlap := false
+	index.Search([2]float64{minX, minY}, [2]float64{maxX, maxY}, func(_, _ [2]float64, data interface{}) bool {
+		if j := data.(int); j != i && target.IntersectSAT(&trees[j]) {
+			overlap = true
+			return false
+		}
+		return true
+	})
+	return overlap
+}
+
+// AnyOvlIndexed reports the same as AnyOvl in about O(n log n) rather than O(n^2): it is
+// the R-tree broad phase of HasCollision under the name of its naive counterpart
+func AnyOvlIndexed(trees []ChristmasTree) bool {
+	return HasCollision(trees)
+}
+
 // GetBounds calculates the bounding box of the entire configuration
 func GetBounds(trees []ChristmasTree) (minX, minY, maxX, maxY float64) {
 	if len(trees) == 0 {
@@ -76,15 +109,201 @@ func Score(trees []ChristmasTree) float64 {
 	return (s * s) / float64(len(trees))
 }
 
-// GetBoundary returns indices of trees that are close to the bounding box boundary
+// RotateAll returns a copy of the trees rotated rigidly by deg degrees counterclockwise
+// about the centroid of their positions: every position is rotated and every angle grows
+// by deg, so relative placements (and overlaps) are unchanged while the bounding square,
+// which is axis-aligned, can shrink.
+func RotateAll(trees []ChristmasTree, deg float64) []ChristmasTree {
+	rotated := make([]ChristmasTree, len(trees))
+	if len(trees) == 0 {
+		return rotated
+	}
+	cx, cy := 0.0, 0.0
+	for i := range trees {
+		cx += trees[i].X
+		cy += trees[i].Y
+	}
+	cx /= float64(len(trees))
+	cy /= float64(len(trees))
+
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	for i, t := range trees {
+		dx, dy := t.X-cx, t.Y-cy
+		rotated[i] = ChristmasTree{
+			ID:    t.ID,
+			X:     cx + dx*cos - dy*sin,
+			Y:     cy + dx*sin + dy*cos,
+			Angle: NormalizeAngle(t.Angle + deg),
+		}
+	}
+	return rotated
+}
+
+// SideAtRotation returns the side of the smallest square, with its axes turned by -deg,
+// that holds every tree: all polygon vertices are projected onto the rotated axes and the
+// larger extent is returned. It equals Side(RotateAll(trees, deg)) without building the
+// rotated copy, and it repeats every 90 degrees.
+func SideAtRotation(trees []ChristmasTree, deg float64) float64 {
+	if len(trees) == 0 {
+		return 0
+	}
+	sin, cos := math.Sincos(deg * math.Pi / 180)
+	minU, minV := math.MaxFloat64, math.MaxFloat64
+	maxU, maxV := -math.MaxFloat64, -math.MaxFloat64
+	for i := range trees {
+		for _, pt := range trees[i].GetOrbPolygon()[0] {
+			u := pt[0]*cos - pt[1]*sin
+			v := pt[0]*sin + pt[1]*cos
+			minU, maxU = math.Min(minU, u), math.Max(maxU, u)
+			minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+		}
+	}
+	return math.Max(maxU-minU, maxV-minV)
+}
+
+// BestRotationSide sweeps SideAtRotation over [0, 90) in steps of stepDeg (1 if stepDeg
+// is not positive) and returns the rotation with the smallest side and that side. The
+// difference to Side(trees) is what a final RotateAll(trees, deg) would gain.
+func BestRotationSide(trees []ChristmasTree, stepDeg float64) (deg, side float64) {
+	if stepDeg <= 0 {
+		stepDeg = 1
+	}
+	side = SideAtRotation(trees, 0)
+	for k := 1; float64(k)*stepDeg < 90; k++ {
+		a := float64(k) * stepDeg
+		if s := SideAtRotation(trees, a); s < side {
+			deg, side = a, s
+		}
+	}
+	return deg, side
+}
+
+// MirrorX returns a copy of the trees reflected left-right about the vertical center line
+// of their bounding box. Each tree's true rotation (Angle + BaseAngleOffset) becomes its
+// negative, 360 - angle, which is the mirror image of the symmetric outline, so a valid
+// layout stays valid and keeps its bounding box.
+func MirrorX(trees []ChristmasTree) []ChristmasTree {
+	minX, _, maxX, _ := GetBounds(trees)
+	mirrored := make([]ChristmasTree, len(trees))
+	for i, t := range trees {
+		mirrored[i] = ChristmasTree{
+			ID:    t.ID,
+			X:     minX + maxX - t.X,
+			Y:     t.Y,
+			Angle: NormalizeAngle(-t.Angle - 2*BaseAngleOffset),
+		}
+	}
+	return mirrored
+}
+
+// MirrorY returns a copy of the trees reflected top-bottom about the horizontal center line
+// of their bounding box; each true rotation becomes 180 - angle. Like MirrorX it keeps a
+// valid layout valid.
+func MirrorY(trees []ChristmasTree) []ChristmasTree {
+	_, minY, _, maxY := GetBounds(trees)
+	mirrored := make([]ChristmasTree, len(trees))
+	for i, t := range trees {
+		mirrored[i] = ChristmasTree{
+			ID:    t.ID,
+			X:     t.X,
+			Y:     minY + maxY - t.Y,
+			Angle: NormalizeAngle(180 - t.Angle - 2*BaseAngleOffset),
+		}
+	}
+	return mirrored
+}
+
+// BestReflection returns whichever of the trees, MirrorX, MirrorY and both has the smallest
+// Side, preferring the earlier one on ties. The axis-aligned square is the same for all four
+// in exact arithmetic, so a reflection only wins by rounding; the choice matters for what
+// later moves do with the layout, not for its current score.
+func BestReflection(trees []ChristmasTree) []ChristmasTree {
+	mx := MirrorX(trees)
+	best := make([]ChristmasTree, len(trees))
+	for i := range trees {
+		best[i] = trees[i].Clone()
+	}
+	bestSide := Side(best)
+	for _, r := range [][]ChristmasTree{mx, MirrorY(trees), MirrorY(mx)} {
+		if s := Side(r); s < bestSide {
+			best, bestSide = r, s
+		}
+	}
+	return best
+}
+
+// AggregateScore returns the leaderboard metric of a full submission: the sum over n of
+// Side(group_n)^2 / n, i.e. Score per group for complete groups. Groups are summed in
+// ascending n so the total is reproducible to the last bit; n <= 0 is ignored.
+func AggregateScore(groups map[int][]ChristmasTree) float64 {
+	ns := make([]int, 0, len(groups))
+	for n := range groups {
+		if n > 0 {
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+
+	total := 0.0
+	for _, n := range ns {
+		s := Side(groups[n])
+		total += s * s / float64(n)
+	}
+	return total
+}
+
+// PackingDensity returns the fraction of the bounding square covered by trees,
+// n*TreeArea / Side^2, a quality measure comparable across n (0 for an empty layout)
+func PackingDensity(trees []ChristmasTree) float64 {
+	s := Side(trees)
+	if s == 0 {
+		return 0
+	}
+	return float64(len(trees)) * TreeArea() / (s * s)
+}
+
+// EstimateDensity is the packing density EstimateSide assumes. The default is about what
+// the best known large-n layouts reach (side^2/n near 0.35).
+var EstimateDensity = 0.7
+
+// EstimateSide returns a rough side for n trees without any geometry:
+// sqrt(n * TreeArea / EstimateDensity), i.e. the square a layout of that density fills
+func EstimateSide(n int) float64 {
+	if n <= 0 || EstimateDensity <= 0 {
+		return 0
+	}
+	return math.Sqrt(float64(n) * TreeArea() / EstimateDensity)
+}
+
+// EstimateGrid returns the rows and columns of trees implied by EstimateSide: the square is
+// cut into n cells with the aspect of one tree's bounding box, so cols = side / cell width
+func EstimateGrid(n int) (rows, cols int) {
+	side := EstimateSide(n)
+	if side == 0 {
+		return 0, 0
+	}
+	w, h := shape.BaseW, shape.TipY+shape.TrunkH
+	cellW := math.Sqrt(side * side / float64(n) * w / h)
+	cols = min(max(int(math.Round(side/cellW)), 1), n)
+	rows = (n + cols - 1) / cols
+	return rows, cols
+}
+
+// GetBoundary returns indices of trees that are close to the bounding box boundary,
+// using GetBoundaryEps with the default tolerance of 0.01
 func GetBoundary(trees []ChristmasTree) []int {
+	return GetBoundaryEps(trees, 0.01)
+}
+
+// GetBoundaryEps returns indices of trees whose bounding box is within eps of a side of
+// the global bounding box
+func GetBoundaryEps(trees []ChristmasTree, eps float64) []int {
 	var boundary []int
 	if len(trees) == 0 {
 		return boundary
 	}
 
 	gx0, gy0, gx1, gy1 := GetBounds(trees)
-	eps := 0.01
 
 	for i := range trees {
 		// Calculate individual tree bounds to check distance to global bounds
@@ -131,3 +350,36 @@ func SwapTrees(trees []ChristmasTree, i, j int) bool {
 	}
 	return true
 }
+
+// SnapAngles returns a copy of the trees where each rotation (including BaseAngleOffset)
+// is rounded to the nearest multiple of increment, tree by tree, keeping a snap only if
+// the tree does not overlap another and Side does not grow
+func SnapAngles(trees []ChristmasTree, increment float64) []ChristmasTree {
+	out := make([]ChristmasTree, len(trees))
+	for i := range trees {
+		out[i] = trees[i].Clone()
+	}
+	if increment <= 0 {
+		return out
+	}
+
+	side := Side(out)
+	for i := range out {
+		old := out[i].Angle
+		snapped := NormalizeAngle(math.Round((old+BaseAngleOffset)/increment)*increment - BaseAngleOffset)
+		if snapped == NormalizeAngle(old) {
+			continue
+		}
+		out[i].Angle = snapped
+		if HasOvl(out, i) {
+			out[i].Angle = old
+			continue
+		}
+		if s := Side(out); s > side+1e-12 {
+			out[i].Angle = old
+		} else {
+			side = s
+		}
+	}
+	return out
+}
@@ -0,0 +1,219 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestSnapAngles(t *testing.T) {
+	// Tree 0 snaps freely inside the box; tree 2 sits so close to tree 1 that snapping tree 1 upright collides
+	trees := []ChristmasTree{
+		{ID: 0, X: 1.5, Y: 0, Angle: 44.2},
+		{ID: 1, X: 3, Y: 0, Angle: 7},
+		{ID: 2, X: 3.3, Y: 0.75, Angle: 0},
+		{ID: 3, X: 0, Y: 0, Angle: 0}, // Keeps tree 0 off the bounding box edge
+	}
+	if HasCollision(trees) {
+		t.Fatalf("Test layout must be collision-free")
+	}
+
+	snapped := SnapAngles(trees, 15)
+	if snapped[0].Angle != 45 {
+		t.Errorf("Tree 0 angle %v, want 45", snapped[0].Angle)
+	}
+	if HasCollision(snapped) {
+		t.Errorf("Snapping introduced a collision")
+	}
+	if Side(snapped) > Side(trees)+1e-12 {
+		t.Errorf("Snapping grew the side from %v to %v", Side(trees), Side(snapped))
+	}
+	if trees[0].Angle != 44.2 {
+		t.Errorf("Input was modified")
+	}
+
+	// Snapping tree 1 to 0 would overlap tree 2, so it keeps its angle
+	check := []ChristmasTree{trees[1], trees[2]}
+	check[0].Angle = 0
+	if !HasCollision(check) {
+		t.Fatalf("Test layout must collide once tree 1 is upright")
+	}
+	if snapped[1].Angle != 7 {
+		t.Errorf("Tree 1 snapped to %v despite the collision", snapped[1].Angle)
+	}
+}
+
+func TestAggregateScore(t *testing.T) {
+	// An upright tree is 0.7 wide and 1.0 tall: n=1 has side 1.0, and two trees side by
+	// side have side 1.4, so the score is 1.0^2/1 + 1.4^2/2 = 1.98
+	groups := map[int][]ChristmasTree{
+		1: {{ID: 1}},
+		2: {{ID: 1}, {ID: 2, X: 0.7}},
+	}
+	if got := AggregateScore(groups); math.Abs(got-1.98) > 1e-9 {
+		t.Errorf("Expected aggregate score 1.98, got %.9f", got)
+	}
+	if got := AggregateScore(nil); got != 0 {
+		t.Errorf("Expected 0 for no groups, got %v", got)
+	}
+}
+
+func TestRotateAll(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 1, X: 0, Y: 0, Angle: 10},
+		{ID: 2, X: 1.2, Y: 0.3, Angle: 200},
+		{ID: 3, X: 0.4, Y: 1.5, Angle: 95},
+	}
+	side := Side(trees)
+
+	full := RotateAll(trees, 360)
+	if math.Abs(Side(full)-side) > 1e-9 {
+		t.Errorf("Rotating by 360 changed the side from %.9f to %.9f", side, Side(full))
+	}
+
+	// A rigid rotation moves each outline with its position: vertex k of tree i after
+	// rotating is vertex k before, rotated about the centroid
+	rotated := RotateAll(trees, 30)
+	cx, cy := (0+1.2+0.4)/3, (0+0.3+1.5)/3
+	sin, cos := math.Sincos(30 * math.Pi / 180)
+	for i := range trees {
+		before := trees[i].GetOrbPolygon()[0]
+		after := rotated[i].GetOrbPolygon()[0]
+		for k := range before {
+			dx, dy := before[k][0]-cx, before[k][1]-cy
+			wx, wy := cx+dx*cos-dy*sin, cy+dx*sin+dy*cos
+			if math.Hypot(after[k][0]-wx, after[k][1]-wy) > 1e-9 {
+				t.Fatalf("Tree %d vertex %d at %v, want (%.6f, %.6f)", i, k, after[k], wx, wy)
+			}
+		}
+	}
+	if AnyOvl(rotated) != AnyOvl(trees) {
+		t.Errorf("Rotation changed whether the trees overlap")
+	}
+	if trees[1].X != 1.2 {
+		t.Errorf("RotateAll modified its input")
+	}
+}
+
+func TestSideAtRotation(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 1, X: 0, Y: 0, Angle: 10},
+		{ID: 2, X: 1.2, Y: 0.3, Angle: 200},
+		{ID: 3, X: 0.4, Y: 1.5, Angle: 95},
+	}
+	if got, want := SideAtRotation(trees, 0), Side(trees); math.Abs(got-want) > 1e-9 {
+		t.Errorf("SideAtRotation at 0 is %.9f, want Side %.9f", got, want)
+	}
+	for _, deg := range []float64{17, 45, 90, 133} {
+		if got, want := SideAtRotation(trees, deg), Side(RotateAll(trees, deg)); math.Abs(got-want) > 1e-9 {
+			t.Errorf("SideAtRotation at %g is %.9f, want %.9f from RotateAll", deg, got, want)
+		}
+	}
+
+	// Four trees on a diagonal: the box is smallest with the line turned onto an axis
+	var line []ChristmasTree
+	for i := 0; i < 4; i++ {
+		line = append(line, ChristmasTree{ID: i, X: float64(i), Y: float64(i)})
+	}
+	deg, side := BestRotationSide(line, 1)
+	if side >= Side(line)-1e-9 || side > SideAtRotation(line, deg)+1e-12 {
+		t.Errorf("BestRotationSide found %.6f at %g, no better than the side %.6f", side, deg, Side(line))
+	}
+	if got := Side(RotateAll(line, deg)); math.Abs(got-side) > 1e-9 {
+		t.Errorf("RotateAll by the best rotation gives %.9f, want %.9f", got, side)
+	}
+}
+
+func TestEstimateSide(t *testing.T) {
+	base := EstimateSide(10)
+	for _, k := range []int{4, 9, 25} {
+		if got, want := EstimateSide(10*k), base*math.Sqrt(float64(k)); math.Abs(got-want) > 1e-12 {
+			t.Errorf("EstimateSide(%d) = %v, want sqrt(%d) * EstimateSide(10) = %v", 10*k, got, k, want)
+		}
+	}
+	if got := EstimateSide(1) * EstimateSide(1) * EstimateDensity; math.Abs(got-TreeArea()) > 1e-12 {
+		t.Errorf("One tree's estimated square at density %v holds area %v, want %v", EstimateDensity, got, TreeArea())
+	}
+	if EstimateSide(0) != 0 {
+		t.Errorf("Expected no side for n=0")
+	}
+
+	for _, n := range []int{1, 7, 50, 200} {
+		rows, cols := EstimateGrid(n)
+		if rows*cols < n || (rows-1)*cols >= n {
+			t.Errorf("EstimateGrid(%d) = %d x %d does not tightly hold n trees", n, rows, cols)
+		}
+	}
+}
+
+func TestMirror(t *testing.T) {
+	// A crowded but valid layout at arbitrary angles
+	rng := rand.New(rand.NewSource(4))
+	var trees []ChristmasTree
+	for len(trees) < 12 {
+		c := ChristmasTree{ID: len(trees), X: rng.Float64() * 2.5, Y: rng.Float64() * 2.5, Angle: rng.Float64() * 360}
+		if !AnyOvl(append(trees, c)) {
+			trees = append(trees, c)
+		}
+	}
+
+	for name, mirror := range map[string]func([]ChristmasTree) []ChristmasTree{"MirrorX": MirrorX, "MirrorY": MirrorY} {
+		mirrored := mirror(trees)
+		if AnyOvl(mirrored) {
+			t.Errorf("%s of a valid layout overlaps", name)
+		}
+		if math.Abs(Side(mirrored)-Side(trees)) > 1e-9 {
+			t.Errorf("%s changed the side from %.9f to %.9f", name, Side(trees), Side(mirrored))
+		}
+		back := mirror(mirrored)
+		for i := range trees {
+			if math.Abs(back[i].X-trees[i].X) > 1e-9 || math.Abs(back[i].Y-trees[i].Y) > 1e-9 ||
+				math.Abs(NormalizeAngle(back[i].Angle-trees[i].Angle+180)-180) > 1e-9 {
+				t.Fatalf("%s twice moved tree %d from %+v to %+v", name, i, trees[i], back[i])
+			}
+		}
+	}
+
+	// The outline itself is reflected: every vertex of the mirrored tree is a reflected vertex
+	minX, _, maxX, _ := GetBounds(trees)
+	mirrored := MirrorX(trees)
+	for i := range trees {
+		for _, v := range mirrored[i].GetOrbPolygon()[0] {
+			found := false
+			for _, w := range trees[i].GetOrbPolygon()[0] {
+				if math.Abs(v[0]-(minX+maxX-w[0])) < 1e-9 && math.Abs(v[1]-w[1]) < 1e-9 {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("Tree %d: mirrored vertex %v is not a reflected vertex", i, v)
+			}
+		}
+	}
+
+	if best := BestReflection(trees); len(best) != len(trees) || Side(best) > Side(trees) || AnyOvl(best) {
+		t.Errorf("BestReflection returned a worse or invalid layout")
+	}
+}
+
+func TestOvlIndexedMatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	for k := 0; k < 200; k++ {
+		trees := make([]ChristmasTree, 2+rng.Intn(15))
+		for i := range trees {
+			trees[i] = ChristmasTree{ID: i, X: rng.Float64() * 3, Y: rng.Float64() * 3, Angle: rng.Float64() * 360}
+		}
+		if got, want := AnyOvlIndexed(trees), AnyOvl(trees); got != want {
+			t.Fatalf("layout %d: AnyOvlIndexed = %v, AnyOvl = %v", k, got, want)
+		}
+		index := BuildRTree(trees)
+		for i := range trees {
+			if got, want := HasOvlIndexed(trees, i, index), HasOvl(trees, i); got != want {
+				t.Fatalf("layout %d tree %d: HasOvlIndexed = %v, HasOvl = %v", k, i, got, want)
+			}
+			if got := HasOvlIndexed(trees, i, nil); got != HasOvl(trees, i) {
+				t.Fatalf("layout %d tree %d: HasOvlIndexed without an index disagrees", k, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tree
+
+import (
+	"sort"
+
+	"github.com/engelsjk/polygol"
+	"github.com/paulmach/orb"
+)
+
+// UnionOutline returns the silhouette of the layout: the union of all tree polygons.
+// The union is a single sweep over all ~15n edges, so it costs noticeably more than
+// the side length (tens of milliseconds at n=200); call it for reporting and analysis,
+// not inside SA loops. If the union fails, the convex hull from OutlineHull is returned.
+func UnionOutline(trees []ChristmasTree) orb.MultiPolygon {
+	if len(trees) == 0 {
+		return nil
+	}
+
+	geom := orbPolygonToGeom(trees[0].GetOrbPolygon())
+	more := make([]polygol.Geom, 0, len(trees)-1)
+	for i := 1; i < len(trees); i++ {
+		more = append(more, orbPolygonToGeom(trees[i].GetOrbPolygon()))
+	}
+
+	union, err := polygol.Union(geom, more...)
+	if err != nil {
+		return orb.MultiPolygon{OutlineHull(trees)}
+	}
+
+	mp := make(orb.MultiPolygon, 0, len(union))
+	for _, poly := range union {
+		p := make(orb.Polygon, 0, len(poly))
+		for _, ring := range poly {
+			r := make(orb.Ring, len(ring))
+			for k, pt := range ring {
+				r[k] = orb.Point{pt[0], pt[1]}
+			}
+			p = append(p, r)
+		}
+		mp = append(mp, p)
+	}
+	return mp
+}
+
+// OutlineHull returns the convex hull of all tree vertices as a closed CCW ring.
+// It is the cheap, simplified alternative to UnionOutline (it fills every pocket).
+func OutlineHull(trees []ChristmasTree) orb.Polygon {
+	hull := ConvexHull(trees)
+	if hull == nil {
+		return nil
+	}
+	// The last point repeats the first, closing the ring
+	return orb.Polygon{append(orb.Ring(hull), hull[0])}
+}
+
+// ConvexHull returns the convex hull of every tree's polygon vertices in CCW order, without
+// repeating the first point (nil for fewer than three vertices)
+func ConvexHull(trees []ChristmasTree) []orb.Point {
+	var pts []orb.Point
+	for i := range trees {
+		pts = append(pts, trees[i].GetOrbPolygon()[0]...)
+	}
+	if len(pts) < 3 {
+		return nil
+	}
+
+	sort.Slice(pts, func(a, b int) bool {
+		if pts[a][0] != pts[b][0] {
+			return pts[a][0] < pts[b][0]
+		}
+		return pts[a][1] < pts[b][1]
+	})
+
+	cross := func(o, a, b orb.Point) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+
+	// Andrew's monotone chain: lower hull, then upper hull
+	hull := make([]orb.Point, 0, 2*len(pts))
+	for _, p := range pts {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	lower := len(hull) + 1
+	for k := len(pts) - 2; k >= 0; k-- {
+		p := pts[k]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// The chain ends where it started
+	return hull[:len(hull)-1]
+}
+
+// HullArea returns the area of ConvexHull(trees), which is counterclockwise so its
+// shoelace area is positive
+func HullArea(trees []ChristmasTree) float64 {
+	return ringArea(orb.Ring(ConvexHull(trees)))
+}
+
+// EmptyFraction returns the share of the convex hull not covered by trees,
+// 1 - n*TreeArea/HullArea; high values suggest Compaction can still pull trees inward
+// (0 for an empty layout)
+func EmptyFraction(trees []ChristmasTree) float64 {
+	hullArea := HullArea(trees)
+	if hullArea == 0 {
+		return 0
+	}
+	return 1 - float64(len(trees))*TreeArea()/hullArea
+}
@@ -0,0 +1,63 @@
+package tree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb/planar"
+)
+
+func TestUnionOutlineArea(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 1.0, Y: 0, Angle: 180},
+		{ID: 2, X: 0, Y: 1.2, Angle: 90},
+	}
+	if HasCollision(trees) {
+		t.Fatalf("Test layout must be collision-free")
+	}
+
+	single := planar.Area(trees[0].GetOrbPolygon())
+	union := planar.Area(UnionOutline(trees))
+	if math.Abs(union-3*single) > 1e-9 {
+		t.Errorf("Union area %v, expected total tree area %v", union, 3*single)
+	}
+
+	hull := planar.Area(OutlineHull(trees))
+	if hull < union {
+		t.Errorf("Convex hull area %v smaller than union area %v", hull, union)
+	}
+}
+
+func TestConvexHullAndEmptyFraction(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 1.0, Y: 0, Angle: 180},
+		{ID: 2, X: 0, Y: 1.2, Angle: 90},
+	}
+
+	hull := ConvexHull(trees)
+	if len(hull) < 3 || hull[0] == hull[len(hull)-1] {
+		t.Fatalf("Expected an open hull of at least 3 points, got %v", hull)
+	}
+	// Strictly CCW: every turn is to the left
+	for i := range hull {
+		o, a, b := hull[i], hull[(i+1)%len(hull)], hull[(i+2)%len(hull)]
+		if (a[0]-o[0])*(b[1]-o[1])-(a[1]-o[1])*(b[0]-o[0]) <= 0 {
+			t.Fatalf("Hull is not strictly convex CCW at %d", i)
+		}
+	}
+
+	area := HullArea(trees)
+	if want := planar.Area(OutlineHull(trees)); math.Abs(area-want) > 1e-9 {
+		t.Errorf("HullArea = %v, planar area of OutlineHull = %v", area, want)
+	}
+
+	want := 1 - 3*TreeArea()/area
+	if got := EmptyFraction(trees); math.Abs(got-want) > 1e-12 || got <= 0 || got >= 1 {
+		t.Errorf("EmptyFraction = %v, want %v in (0, 1)", got, want)
+	}
+	if got := EmptyFraction(nil); got != 0 {
+		t.Errorf("EmptyFraction of no trees = %v, want 0", got)
+	}
+}
@@ -0,0 +1,85 @@
+package tree
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ProgressReporter tracks completed n across a run over many n and prints a status line
+// with the elapsed time and an ETA. The ETA scales the observed time per tree (larger n
+// take longer) to the n still to go, divided by the parallelism seen so far.
+type ProgressReporter struct {
+	w      io.Writer
+	inline bool // Redraw one line with \r instead of printing a line per update
+	now    func() time.Time
+
+	start     time.Time
+	remaining map[int]bool
+	total     int
+	done      int
+	busy      time.Duration // Sum of per-n durations
+	doneTrees int           // Sum of completed n
+}
+
+// NewProgressReporter creates a reporter for the given n values, starting the clock now.
+// With inline set every update overwrites the previous line, which suits a quiet terminal.
+func NewProgressReporter(w io.Writer, ns []int, inline bool) *ProgressReporter {
+	remaining := make(map[int]bool, len(ns))
+	for _, n := range ns {
+		remaining[n] = true
+	}
+	p := &ProgressReporter{w: w, inline: inline, now: time.Now, remaining: remaining, total: len(ns)}
+	p.start = p.now()
+	return p
+}
+
+// Done records that n finished after running for d and prints the status line
+func (p *ProgressReporter) Done(n int, d time.Duration) {
+	if p.remaining[n] {
+		delete(p.remaining, n)
+		p.done++
+		p.busy += d
+		p.doneTrees += n
+	}
+
+	line := fmt.Sprintf("[progress] %d/%d n done (%.1f%%)  elapsed %s  ETA %s",
+		p.done, p.total, 100*float64(p.done)/float64(max(p.total, 1)),
+		p.Elapsed().Round(time.Second), p.ETA().Round(time.Second))
+	if p.inline {
+		fmt.Fprintf(p.w, "\r%s\x1b[K", line)
+	} else {
+		fmt.Fprintln(p.w, line)
+	}
+}
+
+// Finish ends the inline status line so later output starts on a fresh line
+func (p *ProgressReporter) Finish() {
+	if p.inline {
+		fmt.Fprintln(p.w)
+	}
+}
+
+// Elapsed returns the wall-clock time since the reporter was created
+func (p *ProgressReporter) Elapsed() time.Duration {
+	return p.now().Sub(p.start)
+}
+
+// ETA estimates the time until all remaining n are done (0 before the first completion)
+func (p *ProgressReporter) ETA() time.Duration {
+	if p.doneTrees == 0 || len(p.remaining) == 0 {
+		return 0
+	}
+	left := 0
+	for n := range p.remaining {
+		left += n
+	}
+	perTree := float64(p.busy) / float64(p.doneTrees)
+
+	// Workers run in parallel: busy time accumulates faster than wall-clock time
+	parallelism := 1.0
+	if elapsed := p.Elapsed(); elapsed > 0 {
+		parallelism = max(float64(p.busy)/float64(elapsed), 1)
+	}
+	return time.Duration(perTree * float64(left) / parallelism)
+}
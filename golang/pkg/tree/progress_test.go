@@ -0,0 +1,42 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporter(t *testing.T) {
+	var sb strings.Builder
+	clock := time.Unix(0, 0)
+	p := NewProgressReporter(&sb, []int{1, 2, 3, 4}, false)
+	p.now = func() time.Time { return clock }
+	p.start = clock
+
+	if p.ETA() != 0 {
+		t.Errorf("Expected no ETA before the first completion, got %s", p.ETA())
+	}
+
+	// Two workers: n=1 and n=2 finish after 1s and 2s of work, 2s of wall time
+	clock = clock.Add(2 * time.Second)
+	p.Done(1, time.Second)
+	p.Done(2, 2*time.Second)
+
+	// 1s per tree, 7 trees to go, parallelism 1.5
+	want := 7 * time.Second * 2 / 3
+	if got := p.ETA(); (got - want).Abs() > time.Millisecond {
+		t.Errorf("Expected ETA %s, got %s", want, got)
+	}
+	if !strings.Contains(sb.String(), "2/4 n done (50.0%)") {
+		t.Errorf("Missing status line, got %q", sb.String())
+	}
+
+	// Reporting an n twice does not count it again
+	p.Done(2, 2*time.Second)
+	clock = clock.Add(5 * time.Second)
+	p.Done(3, 3*time.Second)
+	p.Done(4, 4*time.Second)
+	if p.done != 4 || p.ETA() != 0 {
+		t.Errorf("Expected 4 done and no ETA left, got %d and %s", p.done, p.ETA())
+	}
+}
@@ -0,0 +1,55 @@
+package tree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// ExportSVG writes an SVG drawing of the configuration to w: every tree's rotated outline
+// as a polygon colored by index, the global bounding box as a rectangle and its side
+// length as a label. The viewBox is scaled to the bounding box and the y axis is flipped
+// so the drawing matches the plane. An empty slice gives a valid, empty SVG.
+func ExportSVG(trees []ChristmasTree, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	minX, minY, maxX, maxY := GetBounds(trees)
+	width, height := maxX-minX, maxY-minY
+	side := math.Max(width, height)
+
+	pad := 0.05*side + 0.1
+	fontSize := 0.04*side + 0.05
+	stroke := 0.002*side + 0.002
+	// Map plane coordinates into the viewBox, flipping y
+	px := func(x float64) float64 { return x - minX + pad }
+	py := func(y float64) float64 { return maxY - y + pad }
+
+	viewW := width + 2*pad
+	viewH := height + 2*pad + 1.5*fontSize
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"0 0 %.6f %.6f\">\n", viewW, viewH)
+
+	if len(trees) > 0 {
+		fmt.Fprintf(bw, "  <rect x=\"%.6f\" y=\"%.6f\" width=\"%.6f\" height=\"%.6f\" fill=\"none\" stroke=\"black\" stroke-width=\"%.6f\"/>\n",
+			px(minX), py(maxY), width, height, stroke)
+
+		for i := range trees {
+			ring := trees[i].GetOrbPolygon()[0]
+			points := make([]string, 0, len(ring))
+			for _, pt := range ring {
+				points = append(points, fmt.Sprintf("%.6f,%.6f", px(pt[0]), py(pt[1])))
+			}
+			// Golden-angle hue steps keep neighbouring indices visually distinct
+			hue := math.Mod(float64(i)*137.508, 360)
+			fmt.Fprintf(bw, "  <polygon points=\"%s\" fill=\"hsl(%.1f,70%%,45%%)\" fill-opacity=\"0.8\" stroke=\"black\" stroke-width=\"%.6f\"/>\n",
+				strings.Join(points, " "), hue, stroke)
+		}
+
+		fmt.Fprintf(bw, "  <text x=\"%.6f\" y=\"%.6f\" font-size=\"%.6f\" font-family=\"sans-serif\">n=%d side=%.6f</text>\n",
+			px(minX), py(minY)+pad+fontSize, fontSize, len(trees), side)
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
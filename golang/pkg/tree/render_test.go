@@ -0,0 +1,79 @@
+package tree
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type svgDoc struct {
+	XMLName  xml.Name   `xml:"svg"`
+	ViewBox  string     `xml:"viewBox,attr"`
+	Rects    []struct{} `xml:"rect"`
+	Polygons []struct {
+		Points string `xml:"points,attr"`
+	} `xml:"polygon"`
+	Texts []string `xml:"text"`
+}
+
+func renderSVG(t *testing.T, trees []ChristmasTree) svgDoc {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := ExportSVG(trees, &buf); err != nil {
+		t.Fatalf("ExportSVG: %v", err)
+	}
+	var doc svgDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Output is not valid XML: %v\n%s", err, buf.String())
+	}
+	return doc
+}
+
+func TestExportSVGEmpty(t *testing.T) {
+	doc := renderSVG(t, nil)
+	if doc.ViewBox == "" {
+		t.Errorf("Expected a viewBox on the empty SVG")
+	}
+	if len(doc.Polygons) != 0 || len(doc.Rects) != 0 {
+		t.Errorf("Expected no shapes, got %d polygons and %d rects", len(doc.Polygons), len(doc.Rects))
+	}
+}
+
+func TestExportSVGRotatedTree(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 1, Y: 0.3, Angle: 90},
+	}
+	doc := renderSVG(t, trees)
+	if len(doc.Polygons) != 2 || len(doc.Rects) != 1 || len(doc.Texts) != 1 {
+		t.Fatalf("Expected 2 polygons, 1 rect and 1 label, got %d, %d, %d", len(doc.Polygons), len(doc.Rects), len(doc.Texts))
+	}
+	if !strings.Contains(doc.Texts[0], strconv.FormatFloat(Side(trees), 'f', 6, 64)) {
+		t.Errorf("Label %q does not contain the side length", doc.Texts[0])
+	}
+
+	// The drawn rotated polygon must be the true outline, shifted by the same offset
+	// and with y flipped, for every vertex
+	ring := trees[1].GetOrbPolygon()[0]
+	pts := strings.Fields(doc.Polygons[1].Points)
+	if len(pts) != len(ring) {
+		t.Fatalf("Expected %d points, got %d", len(ring), len(pts))
+	}
+	parse := func(s string) (float64, float64) {
+		xy := strings.Split(s, ",")
+		x, _ := strconv.ParseFloat(xy[0], 64)
+		y, _ := strconv.ParseFloat(xy[1], 64)
+		return x, y
+	}
+	x0, y0 := parse(pts[0])
+	for k, p := range pts {
+		x, y := parse(p)
+		wantDX, wantDY := ring[k][0]-ring[0][0], -(ring[k][1] - ring[0][1])
+		if math.Abs((x-x0)-wantDX) > 1e-5 || math.Abs((y-y0)-wantDY) > 1e-5 {
+			t.Fatalf("Vertex %d drawn at offset (%.6f, %.6f), want (%.6f, %.6f)", k, x-x0, y-y0, wantDX, wantDY)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package tree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomPairs returns tree pairs at random poses, close enough that many of them overlap
+func randomPairs(count int) [][2]ChristmasTree {
+	rng := rand.New(rand.NewSource(1))
+	pairs := make([][2]ChristmasTree, count)
+	for k := range pairs {
+		pairs[k] = [2]ChristmasTree{
+			{ID: 0, X: 0, Y: 0, Angle: rng.Float64() * 360},
+			{ID: 1, X: rng.Float64()*2.4 - 1.2, Y: rng.Float64()*2.4 - 1.2, Angle: rng.Float64() * 360},
+		}
+	}
+	return pairs
+}
+
+func TestIntersectSATMatchesIntersect(t *testing.T) {
+	hits := 0
+	for k, p := range randomPairs(3000) {
+		want := p[0].Intersect(&p[1])
+		if got := p[0].IntersectSAT(&p[1]); got != want {
+			t.Fatalf("pair %d %+v / %+v: IntersectSAT = %v, Intersect = %v", k, p[0], p[1], got, want)
+		}
+		if want {
+			hits++
+		}
+	}
+	if hits == 0 || hits == 3000 {
+		t.Fatalf("Degenerate test data: %d/3000 pairs collide", hits)
+	}
+
+	// Poses on a regular grid, including axis-aligned contacts
+	for dx := -0.8; dx <= 0.8; dx += 0.1 {
+		for dy := -1.0; dy <= 1.0; dy += 0.1 {
+			for _, deg := range []float64{0, 90, 180, 270} {
+				a := ChristmasTree{}
+				b := ChristmasTree{X: dx, Y: dy, Angle: deg}
+				if got, want := a.IntersectSAT(&b), a.Intersect(&b); got != want {
+					t.Fatalf("offset (%.1f, %.1f) angle %v: IntersectSAT = %v, Intersect = %v", dx, dy, deg, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestPieceOverlapArea(t *testing.T) {
+	for k, p := range randomPairs(3000) {
+		want := p[0].IntersectionArea(&p[1])
+		if got := p[0].pieceOverlapArea(&p[1]); math.Abs(got-want) > 1e-9 {
+			t.Fatalf("pair %d %+v / %+v: pieceOverlapArea = %.12f, IntersectionArea = %.12f", k, p[0], p[1], got, want)
+		}
+	}
+}
+
+func TestMTV(t *testing.T) {
+	hits := 0
+	for k, p := range randomPairs(500) {
+		a, b := p[0], p[1]
+		dx, dy, overlapping := a.MTV(&b)
+		if overlapping != a.IntersectSAT(&b) {
+			t.Fatalf("pair %d: MTV reports overlapping = %v, IntersectSAT disagrees", k, overlapping)
+		}
+		if !overlapping {
+			if dx != 0 || dy != 0 {
+				t.Fatalf("pair %d: non-zero MTV (%v, %v) for separate trees", k, dx, dy)
+			}
+			continue
+		}
+		hits++
+		moved := ChristmasTree{X: a.X + dx, Y: a.Y + dy, Angle: a.Angle}
+		if moved.IntersectSAT(&b) {
+			t.Fatalf("pair %d: moving by the MTV (%v, %v) leaves the trees overlapping", k, dx, dy)
+		}
+	}
+	if hits == 0 {
+		t.Fatalf("Degenerate test data: no pair overlaps")
+	}
+
+	// Side by side with the bottom tiers 0.2 deep into each other: a short sideways push
+	a := ChristmasTree{}
+	b := ChristmasTree{X: 0.5}
+	dx, dy, _ := a.MTV(&b)
+	if math.Hypot(dx, dy) > 0.2+1e-6 || dx >= 0 {
+		t.Errorf("Expected a push of at most 0.2 to the left, got (%v, %v)", dx, dy)
+	}
+}
+
+func BenchmarkIntersect(b *testing.B) {
+	pairs := randomPairs(1000)
+	b.Run("polygol", func(b *testing.B) {
+		k := 0
+		for b.Loop() {
+			p := &pairs[k%len(pairs)]
+			p[0].Intersect(&p[1])
+			k++
+		}
+	})
+	b.Run("sat", func(b *testing.B) {
+		k := 0
+		for b.Loop() {
+			p := &pairs[k%len(pairs)]
+			p[0].IntersectSAT(&p[1])
+			k++
+		}
+	})
+}
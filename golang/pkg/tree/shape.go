@@ -0,0 +1,209 @@
+package tree
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+)
+
+// shapeTolerance is how far a loaded vertex may be from the fitted TreeShape outline
+const shapeTolerance = 1e-9
+
+// LoadShape reads a tree outline from path and returns it as a TreeShape for SetShape.
+// A .json file holds the vertices as [[x, y], ...] or {"vertices": [[x, y], ...]}; any other
+// file is CSV with one x,y vertex per row (an optional header line is skipped). The ring may
+// be closed or open, start at any vertex and run either way: it is checked to be a simple
+// polygon with positive area, turned counterclockwise as polygol expects, and must then
+// be a tiered tree with its trunk top at the origin, i.e. the Outline of a TreeShape that
+// SetShape accepts.
+func LoadShape(path string) (*TreeShape, error) {
+	var (
+		ring orb.Ring
+		err  error
+	)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		ring, err = readShapeJSON(path)
+	} else {
+		ring, err = readShapeCSV(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	if len(ring) < 3 {
+		return nil, fmt.Errorf("shape %s: need at least 3 vertices, got %d", path, len(ring))
+	}
+	if !simpleRing(ring) {
+		return nil, fmt.Errorf("shape %s: outline intersects itself", path)
+	}
+	area := ringArea(ring)
+	if math.Abs(area) < shapeTolerance {
+		return nil, fmt.Errorf("shape %s: outline has no area", path)
+	}
+	if area < 0 {
+		for a, b := 0, len(ring)-1; a < b; a, b = a+1, b-1 {
+			ring[a], ring[b] = ring[b], ring[a]
+		}
+	}
+
+	s, err := fitShape(ring)
+	if err != nil {
+		return nil, fmt.Errorf("shape %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Area returns the area enclosed by the outline of this shape
+func (s TreeShape) Area() float64 {
+	return ringArea(s.Outline())
+}
+
+// readShapeJSON reads vertices from either a bare [[x, y], ...] array or {"vertices": ...}
+func readShapeJSON(path string) (orb.Ring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shape file: %w", err)
+	}
+	var points [][2]float64
+	if err := json.Unmarshal(data, &points); err != nil {
+		var doc struct {
+			Vertices [][2]float64 `json:"vertices"`
+		}
+		if err2 := json.Unmarshal(data, &doc); err2 != nil {
+			return nil, fmt.Errorf("failed to parse shape file: %w", err)
+		}
+		points = doc.Vertices
+	}
+	ring := make(orb.Ring, len(points))
+	for i, p := range points {
+		ring[i] = orb.Point{p[0], p[1]}
+	}
+	return ring, nil
+}
+
+// readShapeCSV reads one x,y vertex per row, skipping a header line
+func readShapeCSV(path string) (orb.Ring, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shape file: %w", err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse shape file: %w", err)
+	}
+	var ring orb.Ring
+	for k, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("shape line %d: expected x,y", k+1)
+		}
+		x, errX := strconv.ParseFloat(strings.TrimSpace(record[0]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if errX != nil || errY != nil {
+			if k == 0 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("shape line %d: invalid vertex %q", k+1, strings.Join(record, ","))
+		}
+		ring = append(ring, orb.Point{x, y})
+	}
+	return ring, nil
+}
+
+// ringArea is the signed shoelace area of a ring, open or closed: positive when counterclockwise
+func ringArea(ring orb.Ring) float64 {
+	area := 0.0
+	for k := range ring {
+		p, q := ring[k], ring[(k+1)%len(ring)]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	return area / 2
+}
+
+// simpleRing reports whether no two non-adjacent edges of the open ring touch or cross
+func simpleRing(ring orb.Ring) bool {
+	n := len(ring)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			if b == a+1 || (a == 0 && b == n-1) {
+				continue // Adjacent edges share a vertex
+			}
+			if segmentsTouch(ring[a], ring[(a+1)%n], ring[b], ring[(b+1)%n]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// segmentsTouch reports whether segments pq and rs have a point in common
+func segmentsTouch(p, q, r, s orb.Point) bool {
+	cross := func(o, a, b orb.Point) float64 {
+		return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+	}
+	onSegment := func(a, b, c orb.Point) bool {
+		return math.Min(a[0], b[0]) <= c[0] && c[0] <= math.Max(a[0], b[0]) &&
+			math.Min(a[1], b[1]) <= c[1] && c[1] <= math.Max(a[1], b[1])
+	}
+	d1, d2 := cross(r, s, p), cross(r, s, q)
+	d3, d4 := cross(p, q, r), cross(p, q, s)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(r, s, p)) || (d2 == 0 && onSegment(r, s, q)) ||
+		(d3 == 0 && onSegment(p, q, r)) || (d4 == 0 && onSegment(p, q, s))
+}
+
+// fitShape reads the TreeShape parameters off a counterclockwise ring of the 15 outline
+// vertices and checks that the ring is exactly that shape's Outline
+func fitShape(ring orb.Ring) (*TreeShape, error) {
+	want := len(DefaultShape.Outline()) - 1
+	if len(ring) != want {
+		return nil, fmt.Errorf("expected the %d vertices of a tiered tree, got %d", want, len(ring))
+	}
+
+	// Start at the tip, the highest vertex, like Outline
+	tip := 0
+	for k := range ring {
+		if ring[k][1] > ring[tip][1] {
+			tip = k
+		}
+	}
+	pts := make(orb.Ring, len(ring))
+	for k := range ring {
+		pts[k] = ring[(tip+k)%len(ring)]
+	}
+
+	s := TreeShape{
+		TipY:   pts[0][1],
+		TopW:   -2 * pts[1][0],
+		Tier1Y: pts[1][1],
+		MidW:   -2 * pts[3][0],
+		Tier2Y: pts[3][1],
+		BaseW:  -2 * pts[5][0],
+		TrunkW: -2 * pts[6][0],
+		TrunkH: -pts[7][1],
+	}
+	outline := s.Outline()
+	for k := range pts {
+		if math.Abs(pts[k][0]-outline[k][0]) > shapeTolerance || math.Abs(pts[k][1]-outline[k][1]) > shapeTolerance {
+			return nil, errors.New("outline is not a tiered tree with its trunk top at the origin")
+		}
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
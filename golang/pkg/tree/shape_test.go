@@ -0,0 +1,76 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+// writeShapeFile writes content to name in a temporary directory and returns its path
+func writeShapeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadShape(t *testing.T) {
+	outline := DefaultShape.Outline()
+	open := outline[:len(outline)-1]
+
+	// Kaggle's listing: open ring, clockwise, starting at the trunk
+	var cw []string
+	for k := len(open) - 1; k >= 0; k-- {
+		p := open[(k+8)%len(open)]
+		cw = append(cw, fmt.Sprintf("[%v, %v]", p[0], p[1]))
+	}
+	jsonPath := writeShapeFile(t, "shape.json", `{"vertices": [`+strings.Join(cw, ", ")+`]}`)
+
+	// Closed and counterclockwise, as CSV with a header
+	rows := []string{"x,y"}
+	for _, p := range outline {
+		rows = append(rows, fmt.Sprintf("%v,%v", p[0], p[1]))
+	}
+	csvPath := writeShapeFile(t, "shape.csv", strings.Join(rows, "\n")+"\n")
+
+	for _, path := range []string{jsonPath, csvPath} {
+		s, err := LoadShape(path)
+		if err != nil {
+			t.Fatalf("LoadShape(%s): %v", filepath.Base(path), err)
+		}
+		if *s != DefaultShape {
+			t.Errorf("%s: loaded %+v, want the default shape %+v", filepath.Base(path), *s, DefaultShape)
+		}
+		if math.Abs(s.Area()-TreeArea()) > 1e-12 {
+			t.Errorf("%s: area %.9f, want %.9f", filepath.Base(path), s.Area(), TreeArea())
+		}
+	}
+
+	for name, content := range map[string]string{
+		"bowtie.json":   `[[0, 0], [1, 1], [1, 0], [0, 1]]`,
+		"square.json":   `[[0, 0], [1, 0], [1, 1], [0, 1]]`,
+		"flat.csv":      "0,0\n1,0\n2,0\n",
+		"shifted.json":  `[` + shiftedVertices(open, 0.1) + `]`,
+		"malformed.csv": "x,y\n0,0\nfoo,1\n",
+	} {
+		if _, err := LoadShape(writeShapeFile(t, name, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// shiftedVertices lists the vertices moved up by dy as JSON pairs
+func shiftedVertices(ring orb.Ring, dy float64) string {
+	var parts []string
+	for _, p := range ring {
+		parts = append(parts, fmt.Sprintf("[%v, %v]", p[0], p[1]+dy))
+	}
+	return strings.Join(parts, ", ")
+}
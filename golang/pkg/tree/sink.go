@@ -0,0 +1,117 @@
+package tree
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// SubmissionRow formats one tree as a submission CSV row ("005_2", "s0.123456", ...).
+// BaseAngleOffset is added back so the row holds the true rotation Kaggle expects.
+func SubmissionRow(n, idx int, t ChristmasTree) []string {
+	return []string{
+		fmt.Sprintf("%03d_%d", n, idx),
+		fmt.Sprintf("s%.6f", t.X),
+		fmt.Sprintf("s%.6f", t.Y),
+		fmt.Sprintf("s%.6f", t.Angle+BaseAngleOffset),
+	}
+}
+
+// SortedGroups returns the n of every group in ascending order, the order groups are
+// serialized in so output does not depend on map iteration
+func SortedGroups(groups map[int][]ChristmasTree) []int {
+	ns := make([]int, 0, len(groups))
+	for n := range groups {
+		ns = append(ns, n)
+	}
+	sort.Ints(ns)
+	return ns
+}
+
+// SortByID returns a copy of the trees ordered by ID; trees with equal IDs keep their order
+func SortByID(trees []ChristmasTree) []ChristmasTree {
+	sorted := make([]ChristmasTree, len(trees))
+	for i := range trees {
+		sorted[i] = trees[i].Clone()
+	}
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].ID < sorted[b].ID })
+	return sorted
+}
+
+// SubmissionRows formats a group as submission CSV rows, trees ordered by ID (SortByID)
+// and numbered by their position in that order
+func SubmissionRows(n int, trees []ChristmasTree) [][]string {
+	rows := make([][]string, 0, len(trees))
+	for idx, t := range SortByID(trees) {
+		rows = append(rows, SubmissionRow(n, idx, t))
+	}
+	return rows
+}
+
+// WriteSubmission writes the groups to a submission CSV at path in ascending n, each
+// group's trees ordered by ID, so the same groups always give the same bytes
+func WriteSubmission(path string, groups map[int][]ChristmasTree) error {
+	sink, err := NewCSVSink(path)
+	if err != nil {
+		return err
+	}
+	for _, n := range SortedGroups(groups) {
+		if err := sink.WriteGroup(n, groups[n]); err != nil {
+			sink.Close()
+			return err
+		}
+	}
+	return sink.Close()
+}
+
+// CSVSink streams a submission CSV to disk one group at a time, so a long run that dies
+// keeps every group written so far. Groups are written in call order; LoadSubmission does
+// not depend on row order.
+type CSVSink struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewCSVSink creates the file (and its directory) at path and writes the header
+func NewCSVSink(path string) (*CSVSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &CSVSink{file: f, writer: csv.NewWriter(f)}
+	if err := s.writer.Write(submissionHeader); err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// WriteGroup writes the rows of one n (see SubmissionRows) and flushes them to the file
+func (s *CSVSink) WriteGroup(n int, trees []ChristmasTree) error {
+	if err := s.writer.WriteAll(SubmissionRows(n, trees)); err != nil {
+		return err
+	}
+	s.writer.Flush()
+	return s.writer.Error()
+}
+
+// Close flushes any buffered rows and closes the file
+func (s *CSVSink) Close() error {
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,98 @@
+package tree
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCSVSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out", "sub.csv")
+	sink, err := NewCSVSink(path)
+	if err != nil {
+		t.Fatalf("NewCSVSink: %v", err)
+	}
+
+	// Groups written so far are readable before the sink is closed
+	if err := sink.WriteGroup(2, row(2, 1.5)); err != nil {
+		t.Fatalf("WriteGroup: %v", err)
+	}
+	groups, err := LoadSubmission(path)
+	if err != nil {
+		t.Fatalf("LoadSubmission: %v", err)
+	}
+	if len(groups) != 1 || len(groups[2]) != 2 || groups[2][1].X != 1.5 {
+		t.Errorf("Expected the n=2 group after one write, got %+v", groups)
+	}
+
+	if err := sink.WriteGroup(1, []ChristmasTree{{ID: 1, X: 0.25, Angle: 30}}); err != nil {
+		t.Fatalf("WriteGroup: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	groups, err = LoadSubmission(path)
+	if err != nil {
+		t.Fatalf("LoadSubmission: %v", err)
+	}
+	if len(groups[1]) != 1 || groups[1][0].X != 0.25 || groups[1][0].Angle != 30 {
+		t.Errorf("Wrong n=1 group: %+v", groups[1])
+	}
+	if len(groups[2]) != 2 {
+		t.Errorf("Expected 2 trees for n=2, got %d", len(groups[2]))
+	}
+}
+
+func TestWriteSubmissionDeterministic(t *testing.T) {
+	// The same groups built in a different order, with trees listed in a different order
+	makeGroups := func(ns []int, reverse bool) map[int][]ChristmasTree {
+		groups := make(map[int][]ChristmasTree)
+		for _, n := range ns {
+			trees := make([]ChristmasTree, n)
+			for i := range trees {
+				id := i + 1
+				if reverse {
+					id = n - i
+				}
+				trees[i] = ChristmasTree{ID: id, X: float64(id) * 0.8, Y: float64(n), Angle: float64(10 * id)}
+			}
+			groups[n] = trees
+		}
+		return groups
+	}
+
+	dir := t.TempDir()
+	pathA, pathB := filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv")
+	if err := WriteSubmission(pathA, makeGroups([]int{1, 2, 3, 4, 5, 6, 7, 8}, false)); err != nil {
+		t.Fatalf("WriteSubmission: %v", err)
+	}
+	if err := WriteSubmission(pathB, makeGroups([]int{8, 3, 6, 1, 7, 2, 5, 4}, true)); err != nil {
+		t.Fatalf("WriteSubmission: %v", err)
+	}
+
+	a, errA := os.ReadFile(pathA)
+	b, errB := os.ReadFile(pathB)
+	if errA != nil || errB != nil {
+		t.Fatalf("Reading the outputs: %v, %v", errA, errB)
+	}
+	if !bytes.Equal(a, b) {
+		t.Fatalf("Outputs differ:\n%s\nvs\n%s", a, b)
+	}
+
+	// Groups ascend by n and rows follow tree IDs
+	lines := strings.Split(strings.TrimSpace(string(a)), "\n")
+	if lines[1] != "001_0,s0.800000,s1.000000,s10.000000" || lines[2] != "002_0,s0.800000,s2.000000,s10.000000" {
+		t.Errorf("Unexpected leading rows %q", lines[1:3])
+	}
+}
+
+func TestSortedGroups(t *testing.T) {
+	groups := map[int][]ChristmasTree{12: nil, 3: nil, 200: nil, 1: nil}
+	if got := SortedGroups(groups); !slices.Equal(got, []int{1, 3, 12, 200}) {
+		t.Errorf("SortedGroups = %v", got)
+	}
+}
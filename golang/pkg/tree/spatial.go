@@ -0,0 +1,158 @@
+package tree
+
+import (
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// PointDistance returns the distance from (px, py) to the tree outline (0 if the point is inside)
+func (t *ChristmasTree) PointDistance(px, py float64) float64 {
+	ring := t.GetOrbPolygon()[0]
+	if pointInRing(ring, px, py) {
+		return 0
+	}
+
+	best := math.MaxFloat64
+	for k := 0; k+1 < len(ring); k++ {
+		best = math.Min(best, segmentDistance(px, py, ring[k], ring[k+1]))
+	}
+	return best
+}
+
+// Contains reports whether the point (px, py) lies inside the tree outline, by ray casting
+// against the rotated polygon ring
+func (t *ChristmasTree) Contains(px, py float64) bool {
+	minX, minY, maxX, maxY := t.GetBoundingBox()
+	if px < minX || px > maxX || py < minY || py > maxY {
+		return false
+	}
+	return pointInRing(t.GetOrbPolygon()[0], px, py)
+}
+
+// NearestGap returns the empty point closest to from among candidates sampled on a grid
+// over the bounding box of the trees; a point is empty if no tree contains it. It reports
+// false if there are no trees or every sample is covered.
+func NearestGap(trees []ChristmasTree, from orb.Point) (orb.Point, bool) {
+	if len(trees) == 0 {
+		return orb.Point{}, false
+	}
+	gx0, gy0, gx1, gy1 := GetBounds(trees)
+
+	const samples = 40
+	stepX := (gx1 - gx0) / samples
+	stepY := (gy1 - gy0) / samples
+	var best orb.Point
+	bestDist := math.Inf(1)
+	for a := 0; a <= samples; a++ {
+		for b := 0; b <= samples; b++ {
+			x, y := gx0+float64(a)*stepX, gy0+float64(b)*stepY
+			d := math.Hypot(x-from[0], y-from[1])
+			if d >= bestDist {
+				continue
+			}
+			covered := false
+			for i := range trees {
+				if trees[i].Contains(x, y) {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				best, bestDist = orb.Point{x, y}, d
+			}
+		}
+	}
+	return best, !math.IsInf(bestDist, 1)
+}
+
+// pointInRing tests whether a point lies inside a closed ring using ray casting
+func pointInRing(ring orb.Ring, px, py float64) bool {
+	inside := false
+	for k, l := 0, len(ring)-1; k < len(ring); l, k = k, k+1 {
+		xk, yk := ring[k][0], ring[k][1]
+		xl, yl := ring[l][0], ring[l][1]
+		if (yk > py) != (yl > py) && px < (xl-xk)*(py-yk)/(yl-yk)+xk {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// segmentDistance returns the distance from (px, py) to the segment a-b
+func segmentDistance(px, py float64, a, b orb.Point) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	lenSq := dx*dx + dy*dy
+	u := 0.0
+	if lenSq > 0 {
+		u = math.Max(0, math.Min(1, ((px-a[0])*dx+(py-a[1])*dy)/lenSq))
+	}
+	return math.Hypot(px-(a[0]+u*dx), py-(a[1]+u*dy))
+}
+
+// SeparationDistance returns the minimum distance between the outlines of two trees
+// (0 if they intersect). For disjoint polygons the minimum is always attained between
+// a vertex of one and an edge of the other, so checking both directions is exact.
+func SeparationDistance(a, b *ChristmasTree) float64 {
+	if a.Intersect(b) {
+		return 0
+	}
+
+	ra := a.GetOrbPolygon()[0]
+	rb := b.GetOrbPolygon()[0]
+	best := math.MaxFloat64
+	for _, pair := range [2][2]orb.Ring{{ra, rb}, {rb, ra}} {
+		pts, edges := pair[0], pair[1]
+		for _, p := range pts {
+			for k := 0; k+1 < len(edges); k++ {
+				best = math.Min(best, segmentDistance(p[0], p[1], edges[k], edges[k+1]))
+			}
+		}
+	}
+	return best
+}
+
+// LargestEmptyCircle approximates the biggest circle inside the bounding box that does not
+// intersect any tree. Candidate centers are sampled on a grid, the clearance of each is the
+// distance to the nearest tree (capped by the box edges), and the best sample is refined
+// with a shrinking local search. Relocation moves can drop a boundary tree into this pocket.
+func LargestEmptyCircle(trees []ChristmasTree) (cx, cy, r float64) {
+	if len(trees) == 0 {
+		return 0, 0, 0
+	}
+	gx0, gy0, gx1, gy1 := GetBounds(trees)
+
+	clearance := func(x, y float64) float64 {
+		d := math.Min(math.Min(x-gx0, gx1-x), math.Min(y-gy0, gy1-y))
+		for i := range trees {
+			if d <= 0 {
+				return 0
+			}
+			d = math.Min(d, trees[i].PointDistance(x, y))
+		}
+		return math.Max(d, 0)
+	}
+
+	const samples = 40
+	stepX := (gx1 - gx0) / samples
+	stepY := (gy1 - gy0) / samples
+	for a := 0; a <= samples; a++ {
+		for b := 0; b <= samples; b++ {
+			x, y := gx0+float64(a)*stepX, gy0+float64(b)*stepY
+			if d := clearance(x, y); d > r {
+				cx, cy, r = x, y, d
+			}
+		}
+	}
+
+	// Refine around the best sample
+	for h := math.Max(stepX, stepY) / 2; h > 1e-4; h /= 2 {
+		for _, dir := range [][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+			x, y := cx+dir[0]*h, cy+dir[1]*h
+			if d := clearance(x, y); d > r {
+				cx, cy, r = x, y, d
+			}
+		}
+	}
+	return cx, cy, r
+}
@@ -0,0 +1,111 @@
+package tree
+
+import (
+	"math"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestPointDistance(t *testing.T) {
+	tr := ChristmasTree{X: 0, Y: 0, Angle: 0}
+	if d := tr.PointDistance(0, 0.3); d != 0 {
+		t.Errorf("Point inside the tree should have distance 0, got %f", d)
+	}
+	// Directly above the tip
+	if d := tr.PointDistance(0, TipY+0.5); math.Abs(d-0.5) > 1e-9 {
+		t.Errorf("Distance above tip = %f, want 0.5", d)
+	}
+}
+
+func TestLargestEmptyCircle(t *testing.T) {
+	// Four trees in the corners of a 4x4 area leave a big hole in the middle
+	trees := []ChristmasTree{
+		{ID: 0, X: 0, Y: 0, Angle: 0},
+		{ID: 1, X: 4, Y: 0, Angle: 0},
+		{ID: 2, X: 0, Y: 4, Angle: 0},
+		{ID: 3, X: 4, Y: 4, Angle: 0},
+	}
+
+	cx, cy, r := LargestEmptyCircle(trees)
+	gx0, gy0, gx1, gy1 := GetBounds(trees)
+	midX, midY := (gx0+gx1)/2, (gy0+gy1)/2
+
+	if math.Hypot(cx-midX, cy-midY) > 0.5 {
+		t.Errorf("Circle center (%.3f, %.3f) is far from the gap at (%.3f, %.3f)", cx, cy, midX, midY)
+	}
+	if r < 1.5 {
+		t.Errorf("Expected a large radius, got %.3f", r)
+	}
+	for i := range trees {
+		if d := trees[i].PointDistance(cx, cy); d < r-1e-9 {
+			t.Errorf("Circle of radius %.3f intersects tree %d (distance %.3f)", r, i, d)
+		}
+	}
+}
+
+func TestSeparationDistance(t *testing.T) {
+	a := ChristmasTree{X: 0, Y: 0, Angle: 0}
+	b := ChristmasTree{X: 1.0, Y: 0, Angle: 0}
+
+	// The trees' bottom tiers (width BaseW) face each other at y = 0
+	want := 1.0 - BaseW
+	if d := SeparationDistance(&a, &b); math.Abs(d-want) > 1e-9 {
+		t.Errorf("Expected separation %v, got %v", want, d)
+	}
+	if d := SeparationDistance(&a, &a); d != 0 {
+		t.Errorf("Expected 0 for intersecting trees, got %v", d)
+	}
+}
+
+func TestContains(t *testing.T) {
+	tr := ChristmasTree{X: 1, Y: 2, Angle: 0}
+	cases := []struct {
+		name   string
+		px, py float64
+		want   bool
+	}{
+		{"trunk", 1, 2 - 0.1, true},
+		{"bottom tier", 1 + 0.3, 2 + 0.05, true},
+		{"top tier", 1, 2 + 0.7, true},
+		{"just above the tip", 1, 2 + 0.81, false},
+		{"beside the trunk", 1 + 0.1, 2 - 0.1, false},
+	}
+	for _, c := range cases {
+		if got := tr.Contains(c.px, c.py); got != c.want {
+			t.Errorf("%s (%v, %v): Contains = %v, want %v", c.name, c.px, c.py, got, c.want)
+		}
+	}
+
+	// Rotating by 180 puts the tip below the center
+	down := ChristmasTree{Angle: 180}
+	if !down.Contains(0, -0.7) || down.Contains(0, 0.7) {
+		t.Errorf("Rotated tree containment is wrong")
+	}
+}
+
+func TestNearestGap(t *testing.T) {
+	trees := []ChristmasTree{{ID: 0}, {ID: 1, X: 1.5}}
+	p, ok := NearestGap(trees, orb.Point{0.75, 0.3})
+	if !ok {
+		t.Fatalf("Expected a gap between two separated trees")
+	}
+	for i := range trees {
+		if trees[i].Contains(p[0], p[1]) {
+			t.Errorf("Gap %v lies inside tree %d", p, i)
+		}
+	}
+	if d := math.Hypot(p[0]-0.75, p[1]-0.3); d > 0.05 {
+		t.Errorf("Gap %v is %v away from an already empty point", p, d)
+	}
+
+	// Starting inside a tree finds a point outside it
+	p, ok = NearestGap(trees, orb.Point{0, 0.3})
+	if !ok || trees[0].Contains(p[0], p[1]) {
+		t.Errorf("Gap %v (ok %v) should lie outside the tree", p, ok)
+	}
+
+	if _, ok := NearestGap(nil, orb.Point{}); ok {
+		t.Errorf("Expected no gap without trees")
+	}
+}
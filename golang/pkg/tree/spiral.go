@@ -0,0 +1,53 @@
+package tree
+
+import "math"
+
+// Spiral placement parameters: turns of the Archimedean spiral r = spiralPitch*theta/2pi are
+// spiralPitch apart, and consecutive candidate points about spiralStep apart along it
+const (
+	spiralPitch = 0.1
+	spiralStep  = 0.02
+)
+
+// spiralAngles are the orientations tried at each spiral point, in order: alternating
+// upright and inverted trees interlock their tiers
+var spiralAngles = []float64{0, 180}
+
+// InitializeTreesSpiral places n trees one by one along an Archimedean spiral out from the
+// origin: each tree walks on from where the previous one was placed until one of
+// spiralAngles is collision-free there (HasOvlIndexed against the placed trees). There
+// is no randomness, so the same n always gives the same layout. Returns the trees and the
+// side of their bounding square.
+func InitializeTreesSpiral(n int) ([]ChristmasTree, float64) {
+	trees := make([]ChristmasTree, 0, n)
+	index := BuildRTree(nil)
+	bounds := NewBounds()
+
+	theta := 0.0
+	for len(trees) < n {
+		// The candidate is the last tree; the index holds only the placed ones
+		i := len(trees)
+		trees = append(trees, ChristmasTree{ID: i})
+		candidate := &trees[i]
+		for placed := false; !placed; {
+			r := spiralPitch * theta / (2 * math.Pi)
+			candidate.X, candidate.Y = r*math.Cos(theta), r*math.Sin(theta)
+			for _, angle := range spiralAngles {
+				candidate.Angle = angle
+				if !HasOvlIndexed(trees, i, index) {
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				// Arc length of a step of dtheta is about r*dtheta away from the center
+				theta += spiralStep / math.Max(r, spiralStep)
+			}
+		}
+
+		minX, minY, maxX, maxY := candidate.GetBoundingBox()
+		index.Insert([2]float64{minX, minY}, [2]float64{maxX, maxY}, i)
+		bounds.Extend(minX, minY, maxX, maxY)
+	}
+	return trees, bounds.Side()
+}
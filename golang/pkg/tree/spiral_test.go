@@ -0,0 +1,22 @@
+package tree
+
+import "testing"
+
+func TestInitializeTreesSpiral(t *testing.T) {
+	a, sideA := InitializeTreesSpiral(12)
+	if err := CheckGroupIntegrity(12, a); err != nil {
+		t.Fatalf("Spiral layout is not a complete group: %v", err)
+	}
+	if HasCollision(a) {
+		t.Fatalf("Spiral layout has overlapping trees")
+	}
+	if sideA != Side(a) {
+		t.Errorf("Returned side %.6f, layout side %.6f", sideA, Side(a))
+	}
+	b, _ := InitializeTreesSpiral(12)
+	for i := range a {
+		if !a[i].Equal(&b[i]) {
+			t.Fatalf("Tree %d differs between runs: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+}
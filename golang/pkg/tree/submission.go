@@ -0,0 +1,358 @@
+package tree
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LoadSubmission reads a submission CSV ("id,x,y,deg" with ids like "005_2" and values
+// prefixed with "s") into groups keyed by n. The header line is optional: the first row is
+// skipped only if its columns are id,x,y,deg, and is an error if it is neither that nor
+// data. Extra columns are ignored, later rows with fewer than four fields or a malformed
+// id are skipped, and unparseable coordinates are an error.
+// BaseAngleOffset is subtracted so loaded angles are in the solver's reference frame.
+func LoadSubmission(path string) (map[int][]ChristmasTree, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1 // Tolerate rows with extra columns
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int][]ChristmasTree)
+	startIdx := 0
+	if len(records) > 0 {
+		header, err := firstRowIsHeader(records[0])
+		if err != nil {
+			return nil, err
+		}
+		if header {
+			startIdx = 1
+		}
+	}
+
+	for k, record := range records[startIdx:] {
+		if len(record) < 4 {
+			continue
+		}
+		n, ok := parseGroupID(record[0])
+		if !ok {
+			continue
+		}
+
+		var vals [3]float64
+		for c := range vals {
+			v, err := parseSubmissionValue(record[c+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid value %q in column %d", startIdx+k+1, record[c+1], c+2)
+			}
+			vals[c] = v
+		}
+
+		t := ChristmasTree{
+			ID:    len(result[n]),
+			X:     vals[0],
+			Y:     vals[1],
+			Angle: vals[2] - BaseAngleOffset,
+		}
+		result[n] = append(result[n], t)
+	}
+	return result, nil
+}
+
+// LoadSubmissionRows reads a submission CSV like LoadSubmission but keeps every data row as
+// written, grouped by n in file order, so groups can be copied to a new file untouched
+func LoadSubmissionRows(path string) (map[int][][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make(map[int][][]string)
+	for k, record := range records {
+		if k == 0 {
+			header, err := firstRowIsHeader(record)
+			if err != nil {
+				return nil, err
+			}
+			if header {
+				continue
+			}
+		}
+		if len(record) < 4 {
+			continue
+		}
+		if n, ok := parseGroupID(record[0]); ok {
+			rows[n] = append(rows[n], record)
+		}
+	}
+	return rows, nil
+}
+
+// submissionHeader is the header row of a submission CSV
+var submissionHeader = []string{"id", "x", "y", "deg"}
+
+// isHeaderRow reports whether a CSV row is the submission header: its first four columns
+// are id,x,y,deg, ignoring case and surrounding spaces (extra columns are allowed)
+func isHeaderRow(record []string) bool {
+	if len(record) < len(submissionHeader) {
+		return false
+	}
+	for c, name := range submissionHeader {
+		if !strings.EqualFold(strings.TrimSpace(record[c]), name) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstRowIsHeader reports whether the first CSV row is the header to skip. A first row
+// that is neither the header nor a data row with an "NNN_k" id is an error rather than
+// a header, so a misnamed header or a corrupt first tree is not dropped silently.
+func firstRowIsHeader(record []string) (bool, error) {
+	if isHeaderRow(record) {
+		return true, nil
+	}
+	if len(record) < 4 {
+		return false, fmt.Errorf("line 1: expected the id,x,y,deg header or a tree, got %d fields", len(record))
+	}
+	if _, ok := parseGroupID(record[0]); !ok {
+		return false, fmt.Errorf("line 1: expected the id,x,y,deg header or a tree, got %q", strings.Join(record, ","))
+	}
+	return false, nil
+}
+
+// parseGroupID parses a row id like "005_2" and returns the group size n (5)
+func parseGroupID(id string) (int, bool) {
+	parts := strings.Split(strings.TrimSpace(id), "_")
+	if len(parts) != 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, false
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// parseSubmissionValue parses a coordinate written as "s1.234567" (the "s" prefix is optional)
+func parseSubmissionValue(s string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimPrefix(strings.TrimSpace(s), "s"), 64)
+}
+
+// LoadTreesFromCSV reads a submission CSV (see LoadSubmission) and returns the trees of
+// group n, e.g. to resume packing from a previous run. It fails if the group is absent
+// or has a different number of trees than n.
+func LoadTreesFromCSV(path string, n int) ([]ChristmasTree, error) {
+	groups, err := LoadSubmission(path)
+	if err != nil {
+		return nil, err
+	}
+	trees, ok := groups[n]
+	if !ok {
+		return nil, fmt.Errorf("%s: no group for n=%d", path, n)
+	}
+	if len(trees) != n {
+		return nil, fmt.Errorf("%s: group n=%d has %d trees", path, n, len(trees))
+	}
+	return trees, nil
+}
+
+// ScoreSubmission returns the competition score: the sum over groups of side^2 / n
+// (see AggregateScore)
+func ScoreSubmission(groups map[int][]ChristmasTree) float64 {
+	return AggregateScore(groups)
+}
+
+// BestMerge picks, for every n, the smallest-side valid group (exactly n trees and no
+// collisions) among the given submissions. Ties keep the earlier submission. It also
+// returns the index of the submission each group came from; n with no valid group are omitted.
+func BestMerge(subs []map[int][]ChristmasTree) (map[int][]ChristmasTree, map[int]int) {
+	merged := make(map[int][]ChristmasTree)
+	source := make(map[int]int)
+	bestSide := make(map[int]float64)
+
+	for k, sub := range subs {
+		for n, trees := range sub {
+			if len(trees) != n || HasCollision(trees) {
+				continue
+			}
+			side := CalculateSideLength(trees)
+			if prev, ok := bestSide[n]; ok && side >= prev-1e-12 {
+				continue
+			}
+			merged[n] = trees
+			source[n] = k
+			bestSide[n] = side
+		}
+	}
+	return merged, source
+}
+
+// FillLeastOverlap completes a BestMerge result: every n present in subs but missing from
+// merged (no valid group anywhere) gets the group with the least total overlap area, ties
+// going to the smaller side and then the earlier submission. It records the source and
+// returns the n filled this way, sorted, so the caller can warn about them.
+func FillLeastOverlap(subs []map[int][]ChristmasTree, merged map[int][]ChristmasTree, source map[int]int) []int {
+	bestOverlap := make(map[int]float64)
+	bestSide := make(map[int]float64)
+	var filled []int
+	for k, sub := range subs {
+		for n, trees := range sub {
+			if _, valid := merged[n]; valid {
+				if _, isFallback := bestOverlap[n]; !isFallback {
+					continue
+				}
+			}
+			overlap := CalculateTotalOverlap(trees)
+			side := CalculateSideLength(trees)
+			if prev, ok := bestOverlap[n]; ok {
+				if overlap > prev+1e-12 || (overlap >= prev-1e-12 && side >= bestSide[n]-1e-12) {
+					continue
+				}
+			} else {
+				filled = append(filled, n)
+			}
+			merged[n] = trees
+			source[n] = k
+			bestOverlap[n] = overlap
+			bestSide[n] = side
+		}
+	}
+	sort.Ints(filled)
+	return filled
+}
+
+// LoadSubmissions loads the submission CSVs at paths, in order, with LoadSubmission
+func LoadSubmissions(paths []string) ([]map[int][]ChristmasTree, error) {
+	subs := make([]map[int][]ChristmasTree, len(paths))
+	for k, p := range paths {
+		groups, err := LoadSubmission(p)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", p, err)
+		}
+		subs[k] = groups
+	}
+	return subs, nil
+}
+
+// MergeBest is BestMerge over the submissions at paths, with FillLeastOverlap giving an n
+// that has no valid group in any file its least overlapping one. Besides the merged groups
+// and the index into paths each came from, it returns the sorted n that fell back to an
+// overlapping group.
+func MergeBest(paths []string) (map[int][]ChristmasTree, map[int]int, []int, error) {
+	subs, err := LoadSubmissions(paths)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	merged, source := BestMerge(subs)
+	fallback := FillLeastOverlap(subs, merged, source)
+	return merged, source, fallback, nil
+}
+
+// Quantize returns a copy of the trees rounded the way a submission written with the
+// given number of decimals would store them (angles are rounded in the output frame,
+// i.e. including BaseAngleOffset)
+func Quantize(trees []ChristmasTree, decimals int) []ChristmasTree {
+	scale := math.Pow(10, float64(decimals))
+	round := func(v float64) float64 {
+		return math.Round(v*scale) / scale
+	}
+
+	out := make([]ChristmasTree, len(trees))
+	for i, t := range trees {
+		out[i] = ChristmasTree{
+			ID:    t.ID,
+			X:     round(t.X),
+			Y:     round(t.Y),
+			Angle: round(t.Angle+BaseAngleOffset) - BaseAngleOffset,
+		}
+	}
+	return out
+}
+
+// ScoreDelta returns how much rounding to the given number of decimals changes the
+// group's score (side^2 / n): score(quantized) - score(raw). A large value means the
+// written file would score differently from the optimized layout.
+func ScoreDelta(trees []ChristmasTree, decimals int) float64 {
+	if len(trees) == 0 {
+		return 0
+	}
+	n := float64(len(trees))
+	raw := CalculateSideLength(trees)
+	quantized := CalculateSideLength(Quantize(trees, decimals))
+	return quantized*quantized/n - raw*raw/n
+}
+
+// Improvement compares one group of a submission against a baseline
+type Improvement struct {
+	N         int
+	Baseline  float64 // Baseline group score (side^2 / n)
+	Current   float64 // Current group score
+	Percent   float64 // Score reduction relative to the baseline; negative means worse
+	Regressed bool    // Current scores worse than the baseline
+}
+
+// CompareToBaseline reports the per-n percentage improvement of current over baseline for
+// every n present in both, sorted by n, and the overall improvement of the summed scores
+// (ScoreSubmission) over those n.
+func CompareToBaseline(current, baseline map[int][]ChristmasTree) ([]Improvement, float64) {
+	var ns []int
+	for n := range current {
+		if _, ok := baseline[n]; ok {
+			ns = append(ns, n)
+		}
+	}
+	sort.Ints(ns)
+
+	common := make(map[int][]ChristmasTree, len(ns))
+	commonBase := make(map[int][]ChristmasTree, len(ns))
+	report := make([]Improvement, 0, len(ns))
+	for _, n := range ns {
+		common[n] = current[n]
+		commonBase[n] = baseline[n]
+
+		cur := ScoreSubmission(map[int][]ChristmasTree{n: current[n]})
+		base := ScoreSubmission(map[int][]ChristmasTree{n: baseline[n]})
+		report = append(report, Improvement{
+			N:         n,
+			Baseline:  base,
+			Current:   cur,
+			Percent:   percentImprovement(base, cur),
+			Regressed: cur > base+1e-12,
+		})
+	}
+
+	overall := percentImprovement(ScoreSubmission(commonBase), ScoreSubmission(common))
+	return report, overall
+}
+
+// percentImprovement returns how much lower cur is than base, in percent of base
+func percentImprovement(base, cur float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return (base - cur) / base * 100
+}
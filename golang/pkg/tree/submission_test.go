@@ -0,0 +1,243 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSubmission writes groups as a submission CSV and returns its path
+func writeSubmission(t *testing.T, name string, groups map[int][]ChristmasTree) string {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("id,x,y,deg\n")
+	for n := 1; n <= 3; n++ {
+		for k, tr := range groups[n] {
+			fmt.Fprintf(&sb, "%03d_%d,s%.6f,s%.6f,s%.6f\n", n, k, tr.X, tr.Y, tr.Angle)
+		}
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// row lays out n upright trees in a row with the given spacing
+func row(n int, spacing float64) []ChristmasTree {
+	trees := make([]ChristmasTree, n)
+	for k := range trees {
+		trees[k] = ChristmasTree{ID: k + 1, X: float64(k) * spacing}
+	}
+	return trees
+}
+
+func TestBestMerge(t *testing.T) {
+	// Each file is tightest for a different n; c's n=2 is tightest but collides
+	a := map[int][]ChristmasTree{1: {{ID: 1, Angle: 45}}, 2: row(2, 1.5), 3: row(3, 1.5)}
+	b := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 0.8), 3: row(3, 1.2)}
+	c := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 0.3), 3: row(3, 0.75)}
+
+	var subs []map[int][]ChristmasTree
+	for k, g := range []map[int][]ChristmasTree{a, b, c} {
+		loaded, err := LoadSubmission(writeSubmission(t, fmt.Sprintf("sub%d.csv", k), g))
+		if err != nil {
+			t.Fatal(err)
+		}
+		subs = append(subs, loaded)
+	}
+
+	merged, source := BestMerge(subs)
+	if ScoreSubmission(merged) >= ScoreSubmission(subs[0]) {
+		t.Errorf("Merged score %.5f does not improve on file 0 (%.5f)", ScoreSubmission(merged), ScoreSubmission(subs[0]))
+	}
+	want := map[int]int{1: 0, 2: 1, 3: 2}
+	for n, k := range want {
+		if source[n] != k {
+			t.Errorf("n=%d: expected group from file %d, got %d", n, k, source[n])
+		}
+		if len(merged[n]) != n || HasCollision(merged[n]) {
+			t.Errorf("n=%d: merged group is invalid", n)
+		}
+	}
+}
+
+func TestMergeBest(t *testing.T) {
+	// n=1 and n=2 have a valid group in one file only; n=3 is invalid everywhere, b's
+	// trees overlap less than a's
+	a := map[int][]ChristmasTree{1: {{ID: 1}}, 2: {{ID: 1}, {ID: 2, X: 0.1}}, 3: {{ID: 1}, {ID: 2, X: 0.1}, {ID: 3, X: 2}}}
+	b := map[int][]ChristmasTree{2: row(2, 1.2), 3: {{ID: 1}, {ID: 2, X: 0.5}, {ID: 3, X: 2}}}
+	merged, source, fallback, err := MergeBest([]string{writeSubmission(t, "a.csv", a), writeSubmission(t, "b.csv", b)})
+	if err != nil {
+		t.Fatalf("MergeBest: %v", err)
+	}
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(merged))
+	}
+	if HasCollision(merged[2]) {
+		t.Errorf("n=2 should come from the valid group in b")
+	}
+	if got := merged[3][1].X; math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("n=3 should fall back to b's less overlapping group, second tree at x=%v", got)
+	}
+	if source[1] != 0 || source[2] != 1 || source[3] != 1 {
+		t.Errorf("Expected sources 0, 1, 1 for n=1..3, got %v", source)
+	}
+	if len(fallback) != 1 || fallback[0] != 3 {
+		t.Errorf("Expected only n=3 to fall back, got %v", fallback)
+	}
+
+	if _, _, _, err := MergeBest([]string{"missing.csv"}); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestScoreDeltaPrecision(t *testing.T) {
+	trees := []ChristmasTree{
+		{ID: 1, X: 0.1234567, Y: 0.7654321, Angle: 12.3456789},
+		{ID: 2, X: 1.2345678, Y: 0.9876543, Angle: 187.654321},
+	}
+
+	prev := math.Inf(1)
+	for _, decimals := range []int{1, 2, 4, 6, 9} {
+		d := math.Abs(ScoreDelta(trees, decimals))
+		if d > prev+1e-15 {
+			t.Errorf("%d decimals: |delta| %.3e larger than with fewer decimals (%.3e)", decimals, d, prev)
+		}
+		prev = d
+	}
+	if prev > 1e-8 {
+		t.Errorf("Expected a negligible delta at 9 decimals, got %.3e", prev)
+	}
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	// Group scores: n=1 is 1.0 in both, n=2 shrinks 2.0 -> 1.5 side, n=3 grows 2.2 -> 2.5 side
+	baseline := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 1.3), 3: row(3, 0.75)}
+	current := map[int][]ChristmasTree{1: {{ID: 1}}, 2: row(2, 0.8), 3: row(3, 0.9), 4: row(4, 1.0)}
+
+	report, overall := CompareToBaseline(current, baseline)
+	if len(report) != 3 {
+		t.Fatalf("Expected 3 common groups, got %d", len(report))
+	}
+
+	wantPercent := map[int]float64{
+		1: 0,
+		2: (2.0*2.0/2 - 1.5*1.5/2) / (2.0 * 2.0 / 2) * 100, // 43.75
+		3: (2.2*2.2/3 - 2.5*2.5/3) / (2.2 * 2.2 / 3) * 100, // -29.13
+	}
+	for _, imp := range report {
+		if math.Abs(imp.Percent-wantPercent[imp.N]) > 1e-9 {
+			t.Errorf("n=%d: expected %.4f%%, got %.4f%%", imp.N, wantPercent[imp.N], imp.Percent)
+		}
+		if imp.Regressed != (imp.N == 3) {
+			t.Errorf("n=%d: unexpected regression flag %v", imp.N, imp.Regressed)
+		}
+	}
+
+	base := 1.0 + 2.0*2.0/2 + 2.2*2.2/3
+	cur := 1.0 + 1.5*1.5/2 + 2.5*2.5/3
+	if want := (base - cur) / base * 100; math.Abs(overall-want) > 1e-9 {
+		t.Errorf("Expected overall %.4f%%, got %.4f%%", want, overall)
+	}
+}
+
+func TestLoadSubmissionHeaderDetection(t *testing.T) {
+	cases := map[string]string{
+		"header":    "id,x,y,deg\n001_0,s0.0,s0.0,s0.0\n002_0,s0.0,s0.0,s0.0\n002_1,s1.0,s0.0,s90.0\n",
+		"no header": "001_0,s0.0,s0.0,s0.0\n002_0,s0.0,s0.0,s0.0\n002_1,s1.0,s0.0,s90.0\n",
+		"extra col": "id,x,y,deg,note\n001_0,s0.0,s0.0,s0.0,a\n002_0,s0.0,s0.0,s0.0,b\n002_1,s1.0,s0.0,s90.0,c\n",
+		"upper":     " ID,X,Y,Deg\n001_0,s0.0,s0.0,s0.0\n002_0,s0.0,s0.0,s0.0\n002_1,s1.0,s0.0,s90.0\n",
+	}
+	for name, content := range cases {
+		path := filepath.Join(t.TempDir(), "sub.csv")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		groups, err := LoadSubmission(path)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+			continue
+		}
+		if len(groups[1]) != 1 || len(groups[2]) != 2 {
+			t.Errorf("%s: expected groups of 1 and 2 trees, got %d and %d", name, len(groups[1]), len(groups[2]))
+			continue
+		}
+		if groups[2][1].X != 1.0 || groups[2][1].Angle != 90.0 || groups[2][1].ID != 1 {
+			t.Errorf("%s: wrong values for 002_1: %+v", name, groups[2][1])
+		}
+	}
+
+	// A malformed coordinate in a data row is reported instead of silently read as 0
+	path := filepath.Join(t.TempDir(), "bad.csv")
+	if err := os.WriteFile(path, []byte("id,x,y,deg\n001_0,s0.0,sabc,s0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSubmission(path); err == nil {
+		t.Errorf("Expected an error for a malformed coordinate")
+	}
+
+	// Only an id,x,y,deg header is skipped; any other unparseable first row is an error
+	for name, content := range map[string]string{
+		"other header": "tree,x,y,angle\n001_0,s0.0,s0.0,s0.0\n",
+		"short row":    "id,x\n001_0,s0.0,s0.0,s0.0\n",
+		"bad tree":     "001_0,s0.0,sabc,s0.0\n002_0,s0.0,s0.0,s0.0\n002_1,s1.0,s0.0,s90.0\n",
+	} {
+		path := filepath.Join(t.TempDir(), "sub.csv")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSubmission(path); err == nil {
+			t.Errorf("%s: expected LoadSubmission to fail", name)
+		}
+		if name != "bad tree" {
+			if _, err := LoadSubmissionRows(path); err == nil {
+				t.Errorf("%s: expected LoadSubmissionRows to fail", name)
+			}
+		}
+	}
+}
+
+func TestLoadTreesFromCSV(t *testing.T) {
+	path := writeSubmission(t, "sub.csv", map[int][]ChristmasTree{
+		2: {{X: 0.5, Y: -1.25, Angle: 30}, {X: 1.5, Y: 2, Angle: 270}},
+		3: row(2, 1),
+	})
+
+	trees, err := LoadTreesFromCSV(path, 2)
+	if err != nil {
+		t.Fatalf("LoadTreesFromCSV: %v", err)
+	}
+	if len(trees) != 2 || trees[0].Y != -1.25 || trees[1].Angle != 270 {
+		t.Errorf("Wrong trees for n=2: %+v", trees)
+	}
+
+	if _, err := LoadTreesFromCSV(path, 1); err == nil {
+		t.Errorf("Expected an error for a missing group")
+	}
+	if _, err := LoadTreesFromCSV(path, 3); err == nil {
+		t.Errorf("Expected an error for a group with the wrong tree count")
+	}
+}
+
+func TestLoadSubmissionRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub.csv")
+	content := "id,x,y,deg\n001_0,s0.10000,s0.0,s45.0\n002_0,s0.0,s0.0,s0.0\n002_1,s1.0,s0.0,s90.0\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := LoadSubmissionRows(path)
+	if err != nil {
+		t.Fatalf("LoadSubmissionRows: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != 1 || len(rows[2]) != 2 {
+		t.Fatalf("Expected groups of 1 and 2 rows, got %v", rows)
+	}
+	// Values are kept exactly as written, not reformatted
+	if rows[1][0][1] != "s0.10000" || rows[2][1][0] != "002_1" {
+		t.Errorf("Rows were not kept as written: %v", rows)
+	}
+}
@@ -0,0 +1,98 @@
+package tree
+
+import (
+	"fmt"
+	"math"
+)
+
+// GroupReport is the validation result for a single group n of a submission
+type GroupReport struct {
+	N     int
+	Count int      // Number of trees found for the group
+	Side  float64  // Side length of the group's bounding square
+	Pairs [][2]int // Colliding tree index pairs (0-based, i < j)
+	Err   error    // CheckGroupIntegrity failure, nil if the group is well formed
+}
+
+// Valid reports whether the group is well formed (exactly n trees) and has no collisions
+func (g *GroupReport) Valid() bool {
+	return g.Count == g.N && g.Err == nil && len(g.Pairs) == 0
+}
+
+// CheckGroupIntegrity reports whether trees is a complete group n: exactly n trees, IDs
+// 0..n-1 each used once, and finite coordinates and angles. The error names the first
+// offending tree. A solver that places fewer trees than asked, or reuses an ID, would
+// otherwise only show up as a rejected submission.
+func CheckGroupIntegrity(n int, trees []ChristmasTree) error {
+	if len(trees) != n {
+		return fmt.Errorf("group %d has %d trees, expected %d", n, len(trees), n)
+	}
+	seen := make([]bool, n)
+	for i := range trees {
+		t := &trees[i]
+		if t.ID < 0 || t.ID >= n {
+			return fmt.Errorf("group %d: tree ID %d out of range 0..%d", n, t.ID, n-1)
+		}
+		if seen[t.ID] {
+			return fmt.Errorf("group %d: duplicate tree ID %d", n, t.ID)
+		}
+		seen[t.ID] = true
+		for _, v := range [3]float64{t.X, t.Y, t.Angle} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("group %d: tree ID %d has non-finite pose (%g, %g, %g)", n, t.ID, t.X, t.Y, t.Angle)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidationReport summarizes a submission file: every group found, the n between 1 and
+// the largest group with no rows at all, and the score over the groups present
+type ValidationReport struct {
+	Groups  []GroupReport // Sorted by n
+	Missing []int
+	Score   float64 // AggregateScore of the groups present
+}
+
+// Valid reports whether no group is missing and every group is valid
+func (r *ValidationReport) Valid() bool {
+	if len(r.Missing) > 0 {
+		return false
+	}
+	for k := range r.Groups {
+		if !r.Groups[k].Valid() {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateSubmission parses a submission CSV and checks every group with
+// CheckGroupIntegrity and for overlaps, listing each colliding pair. Malformed values are reported as an error.
+func ValidateSubmission(path string) (*ValidationReport, error) {
+	groups, err := LoadSubmission(path)
+	if err != nil {
+		return nil, fmt.Errorf("validate %s: %w", path, err)
+	}
+
+	ns := SortedGroups(groups)
+	report := &ValidationReport{}
+	for _, n := range ns {
+		trees := groups[n]
+		g := GroupReport{N: n, Count: len(trees), Side: Side(trees), Err: CheckGroupIntegrity(n, trees)}
+		if AnyOvl(trees) {
+			g.Pairs = OverlappingPairs(trees)
+		}
+		report.Groups = append(report.Groups, g)
+	}
+	report.Score = AggregateScore(groups)
+
+	if len(ns) > 0 {
+		for n := 1; n < ns[len(ns)-1]; n++ {
+			if _, ok := groups[n]; !ok {
+				report.Missing = append(report.Missing, n)
+			}
+		}
+	}
+	return report, nil
+}
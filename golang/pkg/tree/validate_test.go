@@ -0,0 +1,103 @@
+package tree
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateSubmission(t *testing.T) {
+	// n=1 is fine, n=2 has one tree too few, n=3 has its last two trees overlapping
+	groups := map[int][]ChristmasTree{
+		1: {{ID: 1}},
+		2: row(1, 1),
+		3: {{ID: 1}, {ID: 2, X: 1}, {ID: 3, X: 1.2}},
+	}
+	report, err := ValidateSubmission(writeSubmission(t, "sub.csv", groups))
+	if err != nil {
+		t.Fatalf("ValidateSubmission: %v", err)
+	}
+	if len(report.Groups) != 3 || len(report.Missing) != 0 {
+		t.Fatalf("Expected 3 groups and none missing, got %d groups, missing %v", len(report.Groups), report.Missing)
+	}
+	if report.Valid() {
+		t.Errorf("Report with a short group and an overlap must be invalid")
+	}
+
+	if g := report.Groups[0]; !g.Valid() {
+		t.Errorf("n=1 should be valid: %+v", g)
+	}
+	if g := report.Groups[1]; g.Valid() || g.Count != 1 {
+		t.Errorf("n=2 should be invalid with 1 tree: %+v", g)
+	}
+	if g := report.Groups[2]; len(g.Pairs) != 1 || g.Pairs[0] != [2]int{1, 2} {
+		t.Errorf("n=3 should report the pair (1,2), got %v", g.Pairs)
+	}
+
+	want := 0.0
+	for n, trees := range groups {
+		s := Side(trees)
+		want += s * s / float64(n)
+	}
+	if math.Abs(report.Score-want) > 1e-5 {
+		t.Errorf("Score = %.6f, want %.6f", report.Score, want)
+	}
+}
+
+func TestValidateSubmissionMissingAndMalformed(t *testing.T) {
+	report, err := ValidateSubmission(writeSubmission(t, "gap.csv", map[int][]ChristmasTree{1: {{ID: 1}}, 3: row(3, 1)}))
+	if err != nil {
+		t.Fatalf("ValidateSubmission: %v", err)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != 2 || report.Valid() {
+		t.Errorf("Expected n=2 to be reported missing, got %v", report.Missing)
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.csv")
+	if err := os.WriteFile(path, []byte("id,x,y,deg\n001_0,s0.0,s0.0,s0.0\n002_0,s0.0,sabc,s0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateSubmission(path); err == nil || !strings.Contains(err.Error(), "abc") {
+		t.Errorf("Expected an error naming the malformed value, got %v", err)
+	}
+}
+
+func TestCheckGroupIntegrity(t *testing.T) {
+	good := []ChristmasTree{{ID: 2}, {ID: 0, X: 1}, {ID: 1, Y: 1}}
+	if err := CheckGroupIntegrity(3, good); err != nil {
+		t.Errorf("Unexpected error for a complete group: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		trees []ChristmasTree
+		want  string
+	}{
+		{"short", good[:2], "has 2 trees"},
+		{"out of range", []ChristmasTree{{ID: 0}, {ID: 1}, {ID: 3}}, "ID 3 out of range"},
+		{"duplicate", []ChristmasTree{{ID: 0}, {ID: 1}, {ID: 1}}, "duplicate tree ID 1"},
+		{"nan", []ChristmasTree{{ID: 0}, {ID: 1, X: math.NaN()}, {ID: 2}}, "tree ID 1 has non-finite"},
+		{"inf", []ChristmasTree{{ID: 0}, {ID: 1}, {ID: 2, Angle: math.Inf(1)}}, "tree ID 2 has non-finite"},
+	}
+	for _, c := range cases {
+		err := CheckGroupIntegrity(3, c.trees)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.name, c.want, err)
+		}
+	}
+
+	// The validator reports it for a group that parses but is not finite
+	path := filepath.Join(t.TempDir(), "nan.csv")
+	if err := os.WriteFile(path, []byte("id,x,y,deg\n001_0,sNaN,s0.0,s0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ValidateSubmission(path)
+	if err != nil {
+		t.Fatalf("ValidateSubmission: %v", err)
+	}
+	if report.Valid() || report.Groups[0].Err == nil {
+		t.Errorf("Expected the NaN group to be reported invalid")
+	}
+}